@@ -13,19 +13,24 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	commandcenter "github.com/rathix/command-center"
 	"github.com/rathix/command-center/internal/certs"
 	appconfig "github.com/rathix/command-center/internal/config"
+	"github.com/rathix/command-center/internal/federation"
 	"github.com/rathix/command-center/internal/gitops"
 	"github.com/rathix/command-center/internal/health"
 	"github.com/rathix/command-center/internal/history"
 	"github.com/rathix/command-center/internal/k8s"
 	"github.com/rathix/command-center/internal/logtail"
+	"github.com/rathix/command-center/internal/maintenance"
+	"github.com/rathix/command-center/internal/metrics"
 	"github.com/rathix/command-center/internal/notify"
 	"github.com/rathix/command-center/internal/server"
+	"github.com/rathix/command-center/internal/serviceapi"
 	"github.com/rathix/command-center/internal/session"
 	"github.com/rathix/command-center/internal/sse"
 	"github.com/rathix/command-center/internal/state"
@@ -34,11 +39,13 @@ import (
 	appwebsocket "github.com/rathix/command-center/internal/websocket"
 
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 const defaultAddr = ":8443"
 
+// snapshotInterval is how often the service store is saved for warm restarts.
+const snapshotInterval = 30 * time.Second
+
 // Version is injected at build time using ldflags.
 var Version = "(unknown)"
 
@@ -218,6 +225,15 @@ func run(ctx context.Context, cfg config) error {
 
 	store := state.NewStore()
 
+	// Restore the last known state of every service before discovery starts,
+	// so the dashboard and notifications pick up where they left off.
+	snapshotPath := filepath.Join(cfg.DataDir, "snapshot.json")
+	if services, err := history.ReadSnapshot(snapshotPath); err != nil {
+		slog.Warn("failed to read service snapshot", "error", err)
+	} else if len(services) > 0 {
+		slog.Info("Service snapshot restored", "services", store.Restore(services))
+	}
+
 	// Validate kubeconfig file permissions (FR33)
 	// If the default kubeconfig is missing, we allow it to fall back to in-cluster config.
 	if cfg.Kubeconfig != "" {
@@ -253,20 +269,33 @@ func run(ctx context.Context, cfg config) error {
 		lastAppCfg = appCfg
 	}
 
-	// Start Kubernetes Ingress watcher
+	// Start a Kubernetes Ingress watcher per cluster
 	watcherCtx, watcherCancel := context.WithCancel(ctx)
 	defer watcherCancel()
 
-	watcher, err := k8s.NewWatcher(cfg.Kubeconfig, store, logger)
-	if err != nil {
-		slog.Warn("k8s watcher disabled: failed to build kubeconfig")
-	} else {
+	var clusters []clusterWatcher
+	for _, c := range clusterConfigs(cfg.Kubeconfig, lastAppCfg) {
+		if c.Kubeconfig != cfg.Kubeconfig {
+			if err := validateKubeconfigPermissions(c.Kubeconfig, logger); err != nil {
+				slog.Warn("k8s watcher disabled for cluster: kubeconfig validation failed", "cluster", c.Name, "error", err)
+				store.SetClusterConnected(c.Name, false)
+				continue
+			}
+		}
+		watcher, err := k8s.NewWatcher(c.Kubeconfig, c.Context, k8s.ForCluster(c.Name, store), logger,
+			k8s.WithScope(discoveryScope(c, lastAppCfg)))
+		if err != nil {
+			slog.Warn("k8s watcher disabled: failed to build kubeconfig", "cluster", c.Name)
+			store.SetClusterConnected(c.Name, false)
+			continue
+		}
 		go watcher.Run(watcherCtx)
+		clusters = append(clusters, clusterWatcher{ClusterConfig: c, watcher: watcher})
 	}
 
 	// Start Flux GitOps watcher if gitops config is present
-	if lastAppCfg != nil && lastAppCfg.GitOps != nil {
-		restCfg, err := clientcmd.BuildConfigFromFlags("", cfg.Kubeconfig)
+	if lastAppCfg != nil && lastAppCfg.GitOps != nil && len(clusters) > 0 {
+		restCfg, err := k8s.BuildConfig(clusters[0].Kubeconfig, clusters[0].Context)
 		if err != nil {
 			slog.Warn("Flux watcher disabled: failed to build kubeconfig for dynamic client", "error", err)
 		} else {
@@ -286,18 +315,31 @@ func run(ctx context.Context, cfg config) error {
 		slog.Info("Config services registered", "count", len(lastAppCfg.Services))
 
 		// Wait for initial K8s informer sync so overrides can be applied to discovered services.
-		if watcher != nil {
-			waitCtx, cancelWait := context.WithTimeout(watcherCtx, 5*time.Second)
-			if !watcher.WaitForSync(waitCtx) {
-				slog.Warn("timed out waiting for k8s sync before applying config overrides")
+		waitCtx, cancelWait := context.WithTimeout(watcherCtx, 5*time.Second)
+		for _, c := range clusters {
+			if !c.watcher.WaitForSync(waitCtx) {
+				slog.Warn("timed out waiting for k8s sync before applying config overrides", "cluster", c.Name)
 			}
-			cancelWait()
 		}
+		cancelWait()
 
 		appconfig.ApplyOverrides(store, lastAppCfg)
 		slog.Info("Config overrides applied", "count", len(lastAppCfg.Overrides))
 	}
 
+	// Drop restored services their source no longer reports.
+	store.PruneRestored(state.SourceConfig)
+	if len(clusters) == 0 {
+		store.PruneRestored(state.SourceKubernetes)
+	}
+	for _, c := range clusters {
+		go func() {
+			if c.watcher.WaitForSync(watcherCtx) {
+				store.PruneRestoredCluster(c.Name)
+			}
+		}()
+	}
+
 	// Initialize history persistence
 	historyWriter, err := history.NewFileWriter(cfg.HistoryFile, logger)
 	if err != nil {
@@ -319,8 +361,16 @@ func run(ctx context.Context, cfg config) error {
 				if !ok {
 					return
 				}
-				if event.Type == state.EventDiscovered && pendingHistory != nil {
-					pendingHistory.ApplyIfPending(store, event.Service.Namespace, event.Service.Name)
+				if pendingHistory == nil {
+					continue
+				}
+				switch event.Type {
+				case state.EventDiscovered:
+					pendingHistory.ApplyIfPending(store, event.Service.ScopedNamespace(), event.Service.Name)
+				case state.EventResync:
+					for _, svc := range store.All() {
+						pendingHistory.ApplyIfPending(store, svc.ScopedNamespace(), svc.Name)
+					}
 				}
 			}
 		}
@@ -333,6 +383,30 @@ func run(ctx context.Context, cfg config) error {
 	}
 	pendingHistory = history.RestoreHistory(store, records, logger)
 
+	// Create HTTP health checker. Probe timeouts come from the check schedule,
+	// so the client itself has none. Verification is skipped so services with
+	// bad certificates can still be probed; the checker verifies certificates
+	// itself according to the TLS policy.
+	probeClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+	checker := health.NewChecker(store, store, probeClient, cfg.HealthInterval, historyWriter, logger)
+	checker.SetEndpointReader(storeEndpointReadinessReader{store: store})
+	checker.SetSchedule(healthSchedule(cfg.HealthInterval, lastAppCfg))
+	checker.SetDamping(healthDamping(lastAppCfg))
+	checker.SetTLSPolicy(healthTLSPolicy(lastAppCfg, logger))
+
+	// Maintenance windows from config and the API; API windows persist in the data dir
+	maintenanceManager, err := maintenance.NewManager(store, filepath.Join(cfg.DataDir, "maintenance.json"), logger)
+	if err != nil {
+		return fmt.Errorf("failed to load maintenance windows: %w", err)
+	}
+	maintenanceManager.SetConfigWindows(appconfig.MaintenanceWindows(lastAppCfg))
+
 	// Start config file watcher for hot-reload
 	if cfg.ConfigFile != "" {
 		configWatcher := appconfig.NewWatcher(cfg.ConfigFile, func(newCfg *appconfig.Config, errs []error) {
@@ -359,6 +433,10 @@ func run(ctx context.Context, cfg config) error {
 			if added > 0 || removed > 0 || updated > 0 {
 				slog.Info("Config reconciled", "added", added, "removed", removed, "updated", updated)
 			}
+			checker.SetSchedule(healthSchedule(cfg.HealthInterval, newCfg))
+			checker.SetDamping(healthDamping(newCfg))
+			checker.SetTLSPolicy(healthTLSPolicy(newCfg, logger))
+			maintenanceManager.SetConfigWindows(appconfig.MaintenanceWindows(newCfg))
 			lastAppCfg = newCfg
 		}, logger)
 		go func() {
@@ -369,13 +447,15 @@ func run(ctx context.Context, cfg config) error {
 	}
 
 	// Initialize notification engine if configured
+	var notifyDispatcher *notify.RetryDispatcher
 	if lastAppCfg != nil && lastAppCfg.Notifications != nil {
 		adapters, err := notify.BuildAdapters(lastAppCfg.Notifications.Adapters)
 		if err != nil {
 			return fmt.Errorf("failed to build notification adapters: %w", err)
 		}
+		notifyDispatcher = notify.NewRetryDispatcher(notify.WithRetryLogger(logger))
 		var engineOpts []notify.Option
-		engineOpts = append(engineOpts, notify.WithLogger(logger))
+		engineOpts = append(engineOpts, notify.WithLogger(logger), notify.WithRetryDispatcher(notifyDispatcher))
 		if len(lastAppCfg.Notifications.Rules) > 0 {
 			matcher := notify.NewRuleMatcher(lastAppCfg.Notifications.Rules)
 			engineOpts = append(engineOpts, notify.WithRuleMatcher(matcher))
@@ -391,19 +471,12 @@ func run(ctx context.Context, cfg config) error {
 	broker := sse.NewBroker(store, logger, Version, cfg.HealthInterval)
 	go broker.Run(ctx)
 
-	// Create and start HTTP health checker
-	probeClient := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
-	}
-	checker := health.NewChecker(store, store, probeClient, cfg.HealthInterval, historyWriter, logger)
-	checker.SetEndpointReader(storeEndpointReadinessReader{store: store})
+	// Start HTTP health checker
 	go checker.Run(ctx)
 
+	// Keep services' maintenance flags in step with the maintenance windows
+	go maintenanceManager.Run(ctx)
+
 	retentionDays := 30
 	if lastAppCfg != nil && lastAppCfg.History.RetentionDays > 0 {
 		retentionDays = lastAppCfg.History.RetentionDays
@@ -411,10 +484,13 @@ func run(ctx context.Context, cfg config) error {
 	pruner := history.NewPruner(cfg.HistoryFile, retentionDays, historyWriter, logger)
 	go pruner.Run(ctx)
 
-	// Wire log tail handler if K8s is available
+	snapshotter := history.NewSnapshotter(store, snapshotPath, snapshotInterval, logger)
+	go snapshotter.Run(ctx)
+
+	// Wire log tail handler to the first cluster if K8s is available
 	var logHandler *logtail.Handler
-	if watcher != nil {
-		clientset, csErr := k8s.BuildClientset(cfg.Kubeconfig)
+	if len(clusters) > 0 {
+		clientset, csErr := k8s.BuildClientset(clusters[0].Kubeconfig, clusters[0].Context)
 		if csErr != nil {
 			slog.Warn("log tail disabled: failed to build clientset", "error", csErr)
 		} else {
@@ -443,6 +519,29 @@ func run(ctx context.Context, cfg config) error {
 		}
 	}
 
+	// Federate services from remote instances (opt-in: only when config has remotes)
+	var remotes []appconfig.RemoteConfig
+	if lastAppCfg != nil {
+		remotes = lastAppCfg.Remotes
+	}
+	if n := removeUnconfiguredSites(store, remotes); n > 0 {
+		slog.Info("Restored services of removed remotes dropped", "count", n)
+	}
+	for _, r := range remotes {
+		tlsCfg, err := federation.LoadTLSConfig(r.CACert, r.ClientCert, r.ClientKey)
+		if err != nil {
+			slog.Error("federation link disabled: failed to load TLS config", "site", r.Name, "error", err)
+			continue
+		}
+		link, err := federation.NewLink(r.Name, r.URL, tlsCfg, store, logger)
+		if err != nil {
+			slog.Error("federation link disabled", "site", r.Name, "error", err)
+			continue
+		}
+		go link.Run(watcherCtx)
+		slog.Info("Federation link enabled", "site", r.Name, "url", r.URL)
+	}
+
 	// Create WebSocket connection registry for graceful shutdown
 	wsRegistry := appwebsocket.NewRegistry(logger)
 
@@ -451,6 +550,26 @@ func run(ctx context.Context, cfg config) error {
 	// Register SSE endpoint before the catch-all static handler
 	mux.Handle("GET /api/events", broker)
 
+	// Register read-only service endpoints
+	servicesHandler := serviceapi.NewHandler(store, logger)
+	mux.Handle("GET /api/services", servicesHandler)
+	mux.Handle("GET /api/services/{namespace}/{name}", servicesHandler)
+	mux.Handle("GET /api/services/{cluster}/{namespace}/{name}", servicesHandler)
+	mux.Handle("GET /api/services/{site}/{cluster}/{namespace}/{name}", servicesHandler)
+
+	// Register on-demand health check endpoints
+	checkHandler := health.NewCheckHandler(checker)
+	mux.Handle("POST /api/services/check", checkHandler)
+	mux.Handle("POST /api/services/{namespace}/{name}/check", checkHandler)
+	mux.Handle("POST /api/services/{cluster}/{namespace}/{name}/check", checkHandler)
+	mux.Handle("POST /api/groups/{group}/check", checkHandler)
+
+	// Register maintenance window endpoints
+	maintenanceHandler := maintenance.NewHandler(maintenanceManager, logger)
+	mux.Handle("GET /api/maintenance", maintenanceHandler)
+	mux.Handle("POST /api/maintenance", maintenanceHandler)
+	mux.Handle("DELETE /api/maintenance/{id}", maintenanceHandler)
+
 	// Register log tail endpoint (WebSocket)
 	if logHandler != nil {
 		mux.Handle("GET /api/logs/{namespace}/{pod}", logHandler)
@@ -488,6 +607,50 @@ func run(ctx context.Context, cfg config) error {
 		)
 	}
 
+	// Register Prometheus metrics endpoint if enabled. Without auth it sits
+	// behind mTLS like the rest of the API; with auth it bypasses the session
+	// middleware (see below) so scrapers need no client certificate.
+	var metricsBypass http.Handler
+	var metricsSrv *http.Server
+	if lastAppCfg != nil && lastAppCfg.Metrics != nil && lastAppCfg.Metrics.Enabled {
+		collectorOpts := []metrics.Option{
+			metrics.WithStore(store),
+			metrics.WithChecker(checker),
+			metrics.WithSSE(broker),
+		}
+		if notifyDispatcher != nil {
+			collectorOpts = append(collectorOpts, metrics.WithNotifications(notifyDispatcher))
+		}
+		if termManager != nil {
+			collectorOpts = append(collectorOpts, metrics.WithTerminal(termManager))
+		}
+		collector := metrics.NewCollector(store, collectorOpts...)
+		checker.SetMetrics(collector)
+		auth := metricsAuth(lastAppCfg.Metrics.Auth)
+		metricsHandler := metrics.NewHandler(collector, auth, logger)
+		switch {
+		case lastAppCfg.Metrics.ListenAddr != "":
+			metricsMux := http.NewServeMux()
+			metricsMux.Handle("GET /metrics", metricsHandler)
+			metricsSrv = &http.Server{Addr: lastAppCfg.Metrics.ListenAddr, Handler: metricsMux}
+			go func() {
+				if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					slog.Error("metrics server stopped", "error", err)
+				}
+			}()
+			if auth == nil {
+				slog.Warn("Metrics endpoint has no authentication", "addr", lastAppCfg.Metrics.ListenAddr)
+			}
+			slog.Info("Metrics endpoint enabled", "addr", lastAppCfg.Metrics.ListenAddr)
+		case auth != nil:
+			metricsBypass = metricsHandler
+			slog.Info("Metrics endpoint enabled", "path", "/metrics")
+		default:
+			mux.Handle("GET /metrics", metricsHandler)
+			slog.Info("Metrics endpoint enabled", "path", "/metrics", "auth", "mtls")
+		}
+	}
+
 	// Register GitOps REST endpoints
 	var gitopsCfg *appconfig.GitOpsConfig
 	var ghClient *gitops.GitHubClient
@@ -590,6 +753,13 @@ func run(ctx context.Context, cfg config) error {
 		slog.Info("Session authentication enabled", "duration", cfg.SessionDuration)
 	}
 
+	if metricsBypass != nil {
+		outer := http.NewServeMux()
+		outer.Handle("GET /metrics", metricsBypass)
+		outer.Handle("/", handler)
+		handler = outer
+	}
+
 	srv := &http.Server{
 		Addr:      cfg.ListenAddr,
 		Handler:   handler,
@@ -627,10 +797,16 @@ func run(ctx context.Context, cfg config) error {
 		}
 		// Close all WebSocket connections before draining HTTP
 		wsRegistry.CloseAll(shutdownCtx)
+		if metricsSrv != nil {
+			metricsSrv.Shutdown(shutdownCtx)
+		}
 		if err := srv.Shutdown(shutdownCtx); err != nil {
 			return fmt.Errorf("server forced to shutdown: %w", err)
 		}
 		slog.Info("Connections drained")
+		if err := snapshotter.Save(); err != nil {
+			slog.Warn("failed to save service snapshot", "error", err)
+		}
 		slog.Info("Server stopped")
 	case err := <-serverError:
 		return fmt.Errorf("server error: %w", err)
@@ -648,6 +824,159 @@ func storeConfigErrors(store *state.Store, errs []error) {
 	store.SetConfigErrors(strs)
 }
 
+// metricsAuth converts the metrics auth config. It returns nil when no
+// credentials are configured.
+func metricsAuth(a *appconfig.AuthConfig) *metrics.Auth {
+	if a == nil {
+		return nil
+	}
+	secretRef := func(s appconfig.SecretConfig) *state.SecretRef {
+		return &state.SecretRef{Value: s.Value, Env: s.Env, File: s.File}
+	}
+	switch {
+	case a.Bearer != nil:
+		return &metrics.Auth{BearerToken: secretRef(*a.Bearer)}
+	case a.Basic != nil:
+		return &metrics.Auth{Username: a.Basic.Username, Password: secretRef(a.Basic.Password)}
+	}
+	return nil
+}
+
+// clusterWatcher is a cluster whose Ingress watcher is running.
+type clusterWatcher struct {
+	appconfig.ClusterConfig
+	watcher *k8s.Watcher
+}
+
+// clusterConfigs returns the clusters to discover services in: those of the
+// YAML clusters block, with the --kubeconfig flag as their default kubeconfig,
+// or else the single unnamed cluster of the flag.
+func clusterConfigs(kubeconfig string, appCfg *appconfig.Config) []appconfig.ClusterConfig {
+	if appCfg == nil || len(appCfg.Clusters) == 0 {
+		return []appconfig.ClusterConfig{{Kubeconfig: kubeconfig}}
+	}
+	clusters := make([]appconfig.ClusterConfig, len(appCfg.Clusters))
+	for i, c := range appCfg.Clusters {
+		if c.Kubeconfig == "" {
+			c.Kubeconfig = kubeconfig
+		}
+		clusters[i] = c
+	}
+	return clusters
+}
+
+// discoveryScope returns the discovery scope of a cluster: its own, or else
+// the top-level one.
+func discoveryScope(c appconfig.ClusterConfig, appCfg *appconfig.Config) k8s.Scope {
+	d := c.Discovery
+	if d == nil && appCfg != nil {
+		d = appCfg.Discovery
+	}
+	if d == nil {
+		return k8s.Scope{}
+	}
+	scope := k8s.Scope{
+		Namespaces:        d.Namespaces,
+		ExcludeNamespaces: d.ExcludeNamespaces,
+		LabelSelector:     d.LabelSelector,
+		FieldSelector:     d.FieldSelector,
+		ExpandRules:       d.ExpandRules,
+	}
+	if s := d.Services; s != nil && s.Enabled {
+		scope.Services = &k8s.ServiceDiscovery{All: s.All, NodeAddress: s.NodeAddress}
+	}
+	return scope
+}
+
+// removeUnconfiguredSites removes the federated services, restored from the
+// snapshot, of sites that are no longer configured as remotes. It returns
+// the number of services removed.
+func removeUnconfiguredSites(store *state.Store, remotes []appconfig.RemoteConfig) int {
+	configured := make(map[string]bool, len(remotes))
+	for _, r := range remotes {
+		configured[r.Name] = true
+	}
+	removed := 0
+	for _, svc := range store.All() {
+		site, _, _ := strings.Cut(svc.Site, "/")
+		if site != "" && !configured[site] {
+			removed += store.ReplaceSite(site, nil)
+		}
+	}
+	return removed
+}
+
+// healthSchedule builds the health check schedule from the --health-interval
+// flag and the YAML config. health.interval in YAML takes precedence over the
+// flag; groups may override both interval and timeout. Concurrency limits come
+// from the health block.
+func healthSchedule(flagInterval time.Duration, appCfg *appconfig.Config) health.Schedule {
+	schedule := health.Schedule{
+		Default: health.Timing{Interval: flagInterval, Timeout: 10 * time.Second},
+	}
+	if appCfg == nil {
+		return schedule
+	}
+	schedule.MaxConcurrency = appCfg.Health.MaxConcurrency
+	schedule.MaxPerHost = appCfg.Health.MaxPerHost
+	if d := appconfig.ParseDurationOrZero(appCfg.Health.Interval); d > 0 {
+		schedule.Default.Interval = d
+	}
+	if d := appconfig.ParseDurationOrZero(appCfg.Health.Timeout); d > 0 {
+		schedule.Default.Timeout = d
+	}
+	for name, group := range appCfg.Groups {
+		timing := health.Timing{
+			Interval: appconfig.ParseDurationOrZero(group.Interval),
+			Timeout:  appconfig.ParseDurationOrZero(group.Timeout),
+		}
+		if timing == (health.Timing{}) {
+			continue
+		}
+		if schedule.Groups == nil {
+			schedule.Groups = make(map[string]health.Timing)
+		}
+		schedule.Groups[name] = timing
+	}
+	return schedule
+}
+
+// healthDamping builds the transition thresholds and flap detection settings
+// from the YAML health block.
+func healthDamping(appCfg *appconfig.Config) health.Damping {
+	if appCfg == nil {
+		return health.Damping{}
+	}
+	return health.Damping{
+		FailureThreshold: appCfg.Health.FailureThreshold,
+		SuccessThreshold: appCfg.Health.SuccessThreshold,
+		FlapThreshold:    appCfg.Health.FlapThreshold,
+		FlapWindow:       appconfig.ParseDurationOrZero(appCfg.Health.FlapWindow),
+	}
+}
+
+// healthTLSPolicy builds the certificate policy from the YAML health.tls
+// block. An unreadable CA file falls back to the system roots.
+func healthTLSPolicy(appCfg *appconfig.Config, logger *slog.Logger) health.TLSPolicy {
+	if appCfg == nil {
+		return health.TLSPolicy{}
+	}
+	tlsCfg := appCfg.Health.TLS
+	policy := health.TLSPolicy{
+		WarningWindow:     appconfig.ParseDurationOrZero(tlsCfg.WarningWindow),
+		RequireValidChain: tlsCfg.RequireValidChain,
+	}
+	if tlsCfg.CAFile != "" {
+		roots, err := health.LoadCertPool(tlsCfg.CAFile)
+		if err != nil {
+			logger.Warn("health TLS CA not loaded, using system roots", "error", err)
+		} else {
+			policy.Roots = roots
+		}
+	}
+	return policy
+}
+
 type storeEndpointReadinessReader struct {
 	store *state.Store
 }
@@ -9,10 +9,15 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	appconfig "github.com/rathix/command-center/internal/config"
+	"github.com/rathix/command-center/internal/health"
+	"github.com/rathix/command-center/internal/k8s"
 	"github.com/rathix/command-center/internal/state"
 )
 
@@ -817,3 +822,135 @@ func TestValidateKubeconfigPermissionsNoPathInError(t *testing.T) {
 		t.Errorf("error must not contain file path, got: %v", err)
 	}
 }
+
+func TestHealthSchedule(t *testing.T) {
+	schedule := healthSchedule(30*time.Second, nil)
+	if schedule.Default.Interval != 30*time.Second || schedule.Default.Timeout != 10*time.Second {
+		t.Errorf("default schedule = %+v, want 30s interval and 10s timeout", schedule.Default)
+	}
+
+	schedule = healthSchedule(30*time.Second, &appconfig.Config{
+		Health: appconfig.HealthConfig{Interval: "1m", Timeout: "5s", MaxConcurrency: 8, MaxPerHost: 2},
+		Groups: map[string]appconfig.GroupConfig{
+			"media": {DisplayName: "Media", Interval: "5m"},
+			"apps":  {DisplayName: "Apps"},
+		},
+	})
+	if schedule.Default.Interval != time.Minute || schedule.Default.Timeout != 5*time.Second {
+		t.Errorf("YAML health settings not applied: %+v", schedule.Default)
+	}
+	if got := schedule.Groups["media"].Interval; got != 5*time.Minute {
+		t.Errorf("media group interval = %v, want 5m", got)
+	}
+	if _, ok := schedule.Groups["apps"]; ok {
+		t.Error("expected group without timing to be omitted")
+	}
+	if schedule.MaxConcurrency != 8 || schedule.MaxPerHost != 2 {
+		t.Errorf("limits = %d/%d, want 8/2", schedule.MaxConcurrency, schedule.MaxPerHost)
+	}
+}
+
+func TestHealthDamping(t *testing.T) {
+	if d := healthDamping(nil); d != (health.Damping{}) {
+		t.Errorf("healthDamping(nil) = %+v, want zero value", d)
+	}
+
+	d := healthDamping(&appconfig.Config{Health: appconfig.HealthConfig{
+		FailureThreshold: 3,
+		SuccessThreshold: 2,
+		FlapThreshold:    4,
+		FlapWindow:       "15m",
+	}})
+	want := health.Damping{FailureThreshold: 3, SuccessThreshold: 2, FlapThreshold: 4, FlapWindow: 15 * time.Minute}
+	if d != want {
+		t.Errorf("healthDamping = %+v, want %+v", d, want)
+	}
+}
+
+func TestClusterConfigs(t *testing.T) {
+	got := clusterConfigs("/kube/config", nil)
+	if !slices.Equal(got, []appconfig.ClusterConfig{{Kubeconfig: "/kube/config"}}) {
+		t.Errorf("clusterConfigs(nil) = %+v, want the unnamed cluster of the flag", got)
+	}
+
+	got = clusterConfigs("/kube/config", &appconfig.Config{Clusters: []appconfig.ClusterConfig{
+		{Name: "prod", Context: "prod-admin"},
+		{Name: "lab", Kubeconfig: "/kube/lab"},
+	}})
+	want := []appconfig.ClusterConfig{
+		{Name: "prod", Kubeconfig: "/kube/config", Context: "prod-admin"},
+		{Name: "lab", Kubeconfig: "/kube/lab"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("clusterConfigs = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiscoveryScope(t *testing.T) {
+	if got := discoveryScope(appconfig.ClusterConfig{}, nil); !reflect.DeepEqual(got, k8s.Scope{}) {
+		t.Errorf("discoveryScope without config = %+v, want everything", got)
+	}
+
+	appCfg := &appconfig.Config{Discovery: &appconfig.DiscoveryConfig{ExcludeNamespaces: []string{"kube-system"}}}
+	got := discoveryScope(appconfig.ClusterConfig{Name: "prod"}, appCfg)
+	if want := (k8s.Scope{ExcludeNamespaces: []string{"kube-system"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("discoveryScope = %+v, want the top-level %+v", got, want)
+	}
+
+	lab := appconfig.ClusterConfig{Name: "lab", Discovery: &appconfig.DiscoveryConfig{Namespaces: []string{"media"}, LabelSelector: "expose=true", ExpandRules: true}}
+	got = discoveryScope(lab, appCfg)
+	if want := (k8s.Scope{Namespaces: []string{"media"}, LabelSelector: "expose=true", ExpandRules: true}); !reflect.DeepEqual(got, want) {
+		t.Errorf("discoveryScope = %+v, want the cluster's own %+v", got, want)
+	}
+
+	lab.Discovery.Services = &appconfig.ServiceDiscoveryConfig{Enabled: true, NodeAddress: "192.168.1.10"}
+	got = discoveryScope(lab, appCfg)
+	if want := (&k8s.ServiceDiscovery{NodeAddress: "192.168.1.10"}); !reflect.DeepEqual(got.Services, want) {
+		t.Errorf("discoveryScope services = %+v, want %+v", got.Services, want)
+	}
+	lab.Discovery.Services.Enabled = false
+	if got = discoveryScope(lab, appCfg); got.Services != nil {
+		t.Errorf("discoveryScope services = %+v, want none when disabled", got.Services)
+	}
+}
+
+func TestRemoveUnconfiguredSites(t *testing.T) {
+	store := state.NewStore()
+	store.Restore([]state.Service{
+		{Name: "api", Namespace: "default"},
+		{Name: "api", Namespace: "default", Site: "east"},
+		{Name: "api", Namespace: "default", Site: "west"},
+		{Name: "db", Namespace: "default", Site: "west/lab"},
+	})
+
+	if n := removeUnconfiguredSites(store, []appconfig.RemoteConfig{{Name: "east"}}); n != 2 {
+		t.Errorf("removeUnconfiguredSites() = %d, want 2", n)
+	}
+	var keys []string
+	for _, svc := range store.All() {
+		keys = append(keys, svc.Key())
+	}
+	slices.Sort(keys)
+	if want := []string{"default/api", "east/default/api"}; !slices.Equal(keys, want) {
+		t.Errorf("services = %v, want %v", keys, want)
+	}
+}
+
+func TestHealthTLSPolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if p := healthTLSPolicy(nil, logger); p.WarningWindow != 0 || p.Roots != nil || p.RequireValidChain {
+		t.Errorf("healthTLSPolicy(nil) = %+v, want zero value", p)
+	}
+
+	p := healthTLSPolicy(&appconfig.Config{Health: appconfig.HealthConfig{TLS: appconfig.TLSCheckConfig{
+		WarningWindow:     "720h",
+		CAFile:            filepath.Join(t.TempDir(), "missing.pem"),
+		RequireValidChain: true,
+	}}}, logger)
+	if p.WarningWindow != 720*time.Hour || !p.RequireValidChain {
+		t.Errorf("policy = %+v, want 720h window and chain required", p)
+	}
+	if p.Roots != nil {
+		t.Error("expected system roots when CA file cannot be loaded")
+	}
+}
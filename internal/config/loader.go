@@ -3,12 +3,19 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 func parseTerminalDuration(s string) (time.Duration, error) {
@@ -22,6 +29,363 @@ func parseTerminalDuration(s string) (time.Duration, error) {
 	return d, nil
 }
 
+// minCheckInterval is the shortest accepted health check interval.
+const minCheckInterval = time.Second
+
+// validateTiming checks optional interval and timeout durations, clearing
+// any that are invalid. prefix names the owning entry.
+func validateTiming(prefix string, interval, timeout *string) []error {
+	var errs []error
+	if *interval != "" {
+		d, err := time.ParseDuration(*interval)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%s.interval: invalid duration %q: %w", prefix, *interval, err))
+			*interval = ""
+		case d < minCheckInterval:
+			errs = append(errs, fmt.Errorf("%s.interval: must be at least 1s, got %q", prefix, *interval))
+			*interval = ""
+		}
+	}
+	if *timeout != "" {
+		if _, err := parseTerminalDuration(*timeout); err != nil {
+			errs = append(errs, fmt.Errorf("%s.timeout: %w", prefix, err))
+			*timeout = ""
+		}
+	}
+	return errs
+}
+
+// validateThresholds rejects negative threshold values, resetting them to
+// zero (inherit). prefix names the owning entry.
+func validateThresholds(prefix string, failure, success *int) []error {
+	var errs []error
+	if *failure < 0 {
+		errs = append(errs, fmt.Errorf("%s.failureThreshold: must not be negative, got %d", prefix, *failure))
+		*failure = 0
+	}
+	if *success < 0 {
+		errs = append(errs, fmt.Errorf("%s.successThreshold: must not be negative, got %d", prefix, *success))
+		*success = 0
+	}
+	return errs
+}
+
+// validateLatency checks a latency block. Like validateProbe, errors are
+// prefixed with the offending field.
+func validateLatency(l *LatencyConfig) error {
+	switch {
+	case l.DegradedAboveMs < 0:
+		return fmt.Errorf(".degradedAboveMs: must not be negative, got %d", l.DegradedAboveMs)
+	case l.UnhealthyAboveMs < 0:
+		return fmt.Errorf(".unhealthyAboveMs: must not be negative, got %d", l.UnhealthyAboveMs)
+	case l.DegradedAboveMs == 0 && l.UnhealthyAboveMs == 0:
+		return fmt.Errorf(": set degradedAboveMs or unhealthyAboveMs")
+	case l.UnhealthyAboveMs > 0 && l.DegradedAboveMs >= l.UnhealthyAboveMs:
+		return fmt.Errorf(".degradedAboveMs: must be below unhealthyAboveMs (%d), got %d", l.UnhealthyAboveMs, l.DegradedAboveMs)
+	case l.Window < 0:
+		return fmt.Errorf(".window: must not be negative, got %d", l.Window)
+	}
+	return nil
+}
+
+// validateDependsOn returns the well-formed entries of a dependsOn list, each
+// a service key ("namespace/name" or "cluster/namespace/name") or a bare
+// name in the owner's namespace, plus one error per dropped entry. self is
+// the owner's own key.
+func validateDependsOn(prefix, self string, deps []string) ([]string, []error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	selfNS, selfName := splitKey(self)
+	var (
+		valid []string
+		errs  []error
+	)
+	for i, dep := range deps {
+		dep = strings.TrimSpace(dep)
+		ns, name := selfNS, dep
+		if strings.Contains(dep, "/") {
+			ns, name = splitKey(dep)
+		}
+		switch {
+		case dep == "" || !validKey(ns+"/"+name):
+			errs = append(errs, fmt.Errorf("%s.dependsOn[%d]: must be a service name, namespace/name, or cluster/namespace/name, got %q", prefix, i, deps[i]))
+		case ns == selfNS && name == selfName:
+			errs = append(errs, fmt.Errorf("%s.dependsOn[%d]: service cannot depend on itself", prefix, i))
+		default:
+			valid = append(valid, dep)
+		}
+	}
+	return valid, errs
+}
+
+// validKey reports whether key is a service key: "namespace/name", or
+// "cluster/namespace/name" for a service in a named cluster, optionally
+// prefixed with "site/" for a service federated from a remote instance.
+func validKey(key string) bool {
+	parts := strings.Split(key, "/")
+	if len(parts) < 2 || len(parts) > 4 {
+		return false
+	}
+	return !slices.Contains(parts, "")
+}
+
+// splitKey splits a service key into its scoped namespace and name.
+func splitKey(key string) (namespace, name string) {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return "", key
+	}
+	return key[:i], key[i+1:]
+}
+
+// validateLabels returns labels without entries whose key or value is not a
+// valid Kubernetes label key or value, with an error for each one dropped.
+func validateLabels(prefix string, labels map[string]string) (map[string]string, []error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs []error
+	valid := make(map[string]string, len(labels))
+	for _, k := range keys {
+		if msgs := validation.IsQualifiedName(k); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%s.labels: invalid key %q: %s", prefix, k, strings.Join(msgs, "; ")))
+			continue
+		}
+		if msgs := validation.IsValidLabelValue(labels[k]); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%s.labels.%s: invalid value %q: %s", prefix, k, labels[k], strings.Join(msgs, "; ")))
+			continue
+		}
+		valid[k] = labels[k]
+	}
+	return valid, errs
+}
+
+// dnsRecordTypes lists the record types supported by dns probes.
+var dnsRecordTypes = map[string]struct{}{
+	"A": {}, "AAAA": {}, "CNAME": {}, "MX": {}, "NS": {}, "TXT": {},
+}
+
+// validateProbe checks a probe block and normalizes its type and record type.
+// Returned errors are prefixed with the offending field (e.g. ".type: ...") so
+// callers can join them onto the owning entry's path.
+// validateDiscovery checks a discovery scope in place, dropping invalid
+// namespaces and selectors.
+func validateDiscovery(prefix string, d *DiscoveryConfig) []error {
+	if d == nil {
+		return nil
+	}
+	var errs []error
+	validNamespaces := func(field string, list []string) []string {
+		out := make([]string, 0, len(list))
+		for i, ns := range list {
+			ns = strings.TrimSpace(ns)
+			if msgs := validation.IsDNS1123Label(ns); len(msgs) > 0 {
+				errs = append(errs, fmt.Errorf("%s.%s[%d]: invalid namespace %q: %s", prefix, field, i, ns, strings.Join(msgs, "; ")))
+				continue
+			}
+			out = append(out, ns)
+		}
+		return out
+	}
+	d.Namespaces = validNamespaces("namespaces", d.Namespaces)
+	d.ExcludeNamespaces = validNamespaces("excludeNamespaces", d.ExcludeNamespaces)
+	if _, err := labels.Parse(d.LabelSelector); err != nil {
+		errs = append(errs, fmt.Errorf("%s.labelSelector: %w", prefix, err))
+		d.LabelSelector = ""
+	}
+	if _, err := fields.ParseSelector(d.FieldSelector); err != nil {
+		errs = append(errs, fmt.Errorf("%s.fieldSelector: %w", prefix, err))
+		d.FieldSelector = ""
+	}
+	if s := d.Services; s != nil {
+		s.NodeAddress = strings.TrimSpace(s.NodeAddress)
+		if s.NodeAddress != "" && net.ParseIP(s.NodeAddress) == nil && len(validation.IsDNS1123Subdomain(s.NodeAddress)) > 0 {
+			errs = append(errs, fmt.Errorf("%s.services.nodeAddress: invalid host %q", prefix, s.NodeAddress))
+			s.NodeAddress = ""
+		}
+	}
+	return errs
+}
+
+func validateProbe(p *ProbeConfig) error {
+	p.Type = strings.ToLower(strings.TrimSpace(p.Type))
+	switch p.Type {
+	case "", "http":
+		p.Type = "http"
+	case "tcp", "tls", "grpc":
+	case "dns":
+		if strings.TrimSpace(p.Query) == "" {
+			return fmt.Errorf(".query: required for dns probes")
+		}
+		p.RecordType = strings.ToUpper(strings.TrimSpace(p.RecordType))
+		if p.RecordType == "" {
+			p.RecordType = "A"
+		}
+		if _, ok := dnsRecordTypes[p.RecordType]; !ok {
+			return fmt.Errorf(".recordType: unsupported record type %q", p.RecordType)
+		}
+	default:
+		return fmt.Errorf(".type: unsupported probe type %q (want http, tcp, tls, dns, or grpc)", p.Type)
+	}
+	return validateHTTPProbe(p)
+}
+
+// httpProbeMethods lists the request methods accepted for HTTP probes.
+var httpProbeMethods = map[string]struct{}{
+	"GET": {}, "HEAD": {}, "POST": {}, "PUT": {}, "PATCH": {}, "DELETE": {}, "OPTIONS": {},
+}
+
+// validateHTTPProbe checks the HTTP request settings of a probe. Secrets and
+// files must be readable now so that typos surface at load time rather than
+// as probe failures.
+func validateHTTPProbe(p *ProbeConfig) error {
+	p.Method = strings.ToUpper(strings.TrimSpace(p.Method))
+	if p.Method != "" {
+		if _, ok := httpProbeMethods[p.Method]; !ok {
+			return fmt.Errorf(".method: unsupported method %q", p.Method)
+		}
+	}
+	names := make([]string, 0, len(p.Headers))
+	for name := range p.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validateSecret(p.Headers[name]); err != nil {
+			return fmt.Errorf(".headers.%s: %w", name, err)
+		}
+	}
+	if p.Auth != nil {
+		if err := validateAuth(p.Auth); err != nil {
+			return fmt.Errorf(".auth%w", err)
+		}
+	}
+	if t := p.TLS; t != nil {
+		if (t.CertFile == "") != (t.KeyFile == "") {
+			return fmt.Errorf(".tls: certFile and keyFile must be set together")
+		}
+		for _, f := range []struct{ field, path string }{
+			{"caFile", t.CAFile}, {"certFile", t.CertFile}, {"keyFile", t.KeyFile},
+		} {
+			if f.path == "" {
+				continue
+			}
+			if _, err := os.Stat(f.path); err != nil {
+				return fmt.Errorf(".tls.%s: %w", f.field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSecret requires exactly one source and checks that it is readable.
+func validateSecret(s SecretConfig) error {
+	sources := 0
+	for _, v := range []string{s.Value, s.Env, s.File} {
+		if v != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("set exactly one of value, env, or file")
+	}
+	if s.Env != "" {
+		if _, ok := os.LookupEnv(s.Env); !ok {
+			return fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+	}
+	if s.File != "" {
+		if _, err := os.ReadFile(s.File); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAuth checks that at most one credential type is set and that its
+// secrets resolve. Errors are prefixed with the offending field.
+func validateAuth(a *AuthConfig) error {
+	if a.Bearer != nil && a.Basic != nil {
+		return fmt.Errorf(": set either bearer or basic, not both")
+	}
+	if a.Bearer != nil {
+		if err := validateSecret(*a.Bearer); err != nil {
+			return fmt.Errorf(".bearer: %w", err)
+		}
+	}
+	if a.Basic != nil {
+		if a.Basic.Username == "" {
+			return fmt.Errorf(".basic.username: required")
+		}
+		if err := validateSecret(a.Basic.Password); err != nil {
+			return fmt.Errorf(".basic.password: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateAssertions returns the valid body assertions from list, normalizing
+// onFailure, plus one error per stripped entry. prefix names the owning entry.
+func validateAssertions(prefix string, list []AssertionConfig) ([]AssertionConfig, []error) {
+	if len(list) == 0 {
+		return list, nil
+	}
+	var errs []error
+	valid := make([]AssertionConfig, 0, len(list))
+	for j, a := range list {
+		field := fmt.Sprintf("%s.assertions[%d]", prefix, j)
+
+		kinds := 0
+		if a.Contains != "" {
+			kinds++
+		}
+		if a.Regex != "" {
+			kinds++
+			if _, err := regexp.Compile(a.Regex); err != nil {
+				errs = append(errs, fmt.Errorf("%s.regex: invalid pattern: %w", field, err))
+				continue
+			}
+		}
+		if a.JSONPath != "" {
+			kinds++
+		}
+		if a.MaxBodyBytes != 0 {
+			kinds++
+			if a.MaxBodyBytes < 0 {
+				errs = append(errs, fmt.Errorf("%s.maxBodyBytes: must be positive, got %d", field, a.MaxBodyBytes))
+				continue
+			}
+		}
+		if kinds != 1 {
+			errs = append(errs, fmt.Errorf("%s: exactly one of contains, regex, jsonPath, or maxBodyBytes is required", field))
+			continue
+		}
+		if a.Equals != nil && a.JSONPath == "" {
+			errs = append(errs, fmt.Errorf("%s.equals: requires jsonPath", field))
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(a.OnFailure)) {
+		case "", "unhealthy":
+			a.OnFailure = "unhealthy"
+		case "degraded":
+			a.OnFailure = "degraded"
+		default:
+			errs = append(errs, fmt.Errorf("%s.onFailure: must be \"degraded\" or \"unhealthy\", got %q", field, a.OnFailure))
+			continue
+		}
+		valid = append(valid, a)
+	}
+	return valid, errs
+}
+
 // Load reads and parses a YAML configuration file at path.
 // If path does not exist or is empty, it returns an empty Config with no errors.
 // If the YAML is malformed, it returns nil config with a parse error.
@@ -88,6 +452,29 @@ func Load(path string) (*Config, []error) {
 					svc.HealthURL = ""
 				}
 			}
+			if svc.Probe != nil {
+				if err := validateProbe(svc.Probe); err != nil {
+					validationErrors = append(validationErrors, fmt.Errorf("services[%d].probe%w", i, err))
+					svc.Probe = nil
+				}
+			}
+			var assertionErrs []error
+			svc.Assertions, assertionErrs = validateAssertions(fmt.Sprintf("services[%d]", i), svc.Assertions)
+			validationErrors = append(validationErrors, assertionErrs...)
+			validationErrors = append(validationErrors, validateTiming(fmt.Sprintf("services[%d]", i), &svc.Interval, &svc.Timeout)...)
+			validationErrors = append(validationErrors, validateThresholds(fmt.Sprintf("services[%d]", i), &svc.FailureThreshold, &svc.SuccessThreshold)...)
+			if svc.Latency != nil {
+				if err := validateLatency(svc.Latency); err != nil {
+					validationErrors = append(validationErrors, fmt.Errorf("services[%d].latency%w", i, err))
+					svc.Latency = nil
+				}
+			}
+			var depErrs []error
+			svc.DependsOn, depErrs = validateDependsOn(fmt.Sprintf("services[%d]", i), "custom/"+name, svc.DependsOn)
+			validationErrors = append(validationErrors, depErrs...)
+			var labelErrs []error
+			svc.Labels, labelErrs = validateLabels(fmt.Sprintf("services[%d]", i), svc.Labels)
+			validationErrors = append(validationErrors, labelErrs...)
 			seenServiceNames[name] = struct{}{}
 			validServices = append(validServices, svc)
 		}
@@ -101,9 +488,8 @@ func Load(path string) (*Config, []error) {
 			validationErrors = append(validationErrors, fmt.Errorf("overrides[%d].match: required field missing", i))
 			continue
 		}
-		parts := strings.SplitN(match, "/", 2)
-		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-			validationErrors = append(validationErrors, fmt.Errorf("overrides[%d].match: must be in namespace/name format, got %q", i, ovr.Match))
+		if !validKey(match) {
+			validationErrors = append(validationErrors, fmt.Errorf("overrides[%d].match: must be in namespace/name or cluster/namespace/name format, got %q", i, ovr.Match))
 			continue
 		}
 		// Validate optional healthUrl if provided
@@ -114,10 +500,201 @@ func Load(path string) (*Config, []error) {
 				ovr.HealthURL = ""
 			}
 		}
+		if ovr.Probe != nil {
+			if err := validateProbe(ovr.Probe); err != nil {
+				validationErrors = append(validationErrors, fmt.Errorf("overrides[%d].probe%w", i, err))
+				ovr.Probe = nil
+			}
+		}
+		var assertionErrs []error
+		ovr.Assertions, assertionErrs = validateAssertions(fmt.Sprintf("overrides[%d]", i), ovr.Assertions)
+		validationErrors = append(validationErrors, assertionErrs...)
+		validationErrors = append(validationErrors, validateTiming(fmt.Sprintf("overrides[%d]", i), &ovr.Interval, &ovr.Timeout)...)
+		validationErrors = append(validationErrors, validateThresholds(fmt.Sprintf("overrides[%d]", i), &ovr.FailureThreshold, &ovr.SuccessThreshold)...)
+		if ovr.Latency != nil {
+			if err := validateLatency(ovr.Latency); err != nil {
+				validationErrors = append(validationErrors, fmt.Errorf("overrides[%d].latency%w", i, err))
+				ovr.Latency = nil
+			}
+		}
+		var depErrs []error
+		ovr.DependsOn, depErrs = validateDependsOn(fmt.Sprintf("overrides[%d]", i), match, ovr.DependsOn)
+		validationErrors = append(validationErrors, depErrs...)
+		var labelErrs []error
+		ovr.Labels, labelErrs = validateLabels(fmt.Sprintf("overrides[%d]", i), ovr.Labels)
+		validationErrors = append(validationErrors, labelErrs...)
 		validOverrides = append(validOverrides, ovr)
 	}
 	cfg.Overrides = validOverrides
 
+	// Validate health check timing: global, then per group
+	validationErrors = append(validationErrors, validateTiming("health", &cfg.Health.Interval, &cfg.Health.Timeout)...)
+	validationErrors = append(validationErrors, validateThresholds("health", &cfg.Health.FailureThreshold, &cfg.Health.SuccessThreshold)...)
+	for _, limit := range []struct {
+		field string
+		value *int
+	}{
+		{"flapThreshold", &cfg.Health.FlapThreshold},
+		{"maxConcurrency", &cfg.Health.MaxConcurrency},
+		{"maxPerHost", &cfg.Health.MaxPerHost},
+	} {
+		if *limit.value < 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("health.%s: must not be negative, got %d", limit.field, *limit.value))
+			*limit.value = 0
+		}
+	}
+	if cfg.Health.TLS.WarningWindow != "" {
+		if _, err := parseTerminalDuration(cfg.Health.TLS.WarningWindow); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("health.tls.warningWindow: %w", err))
+			cfg.Health.TLS.WarningWindow = ""
+		}
+	}
+	if cfg.Health.TLS.CAFile != "" {
+		if _, err := os.Stat(cfg.Health.TLS.CAFile); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("health.tls.caFile: %w", err))
+			cfg.Health.TLS.CAFile = ""
+		}
+	}
+	if cfg.Health.FlapWindow != "" {
+		if _, err := parseTerminalDuration(cfg.Health.FlapWindow); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("health.flapWindow: %w", err))
+			cfg.Health.FlapWindow = ""
+		}
+	}
+	groupNames := make([]string, 0, len(cfg.Groups))
+	for name := range cfg.Groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+	for _, name := range groupNames {
+		group := cfg.Groups[name]
+		validationErrors = append(validationErrors, validateTiming("groups."+name, &group.Interval, &group.Timeout)...)
+		cfg.Groups[name] = group
+	}
+
+	// Validate maintenance windows: names are required and unique
+	validMaintenance := make([]MaintenanceConfig, 0, len(cfg.Maintenance))
+	maintenanceNames := make(map[string]struct{}, len(cfg.Maintenance))
+	for i, m := range cfg.Maintenance {
+		m.Name = strings.TrimSpace(m.Name)
+		if m.Name == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("maintenance[%d].name: required field missing", i))
+			continue
+		}
+		if _, dup := maintenanceNames[m.Name]; dup {
+			validationErrors = append(validationErrors, fmt.Errorf("maintenance[%d].name: duplicate name %q", i, m.Name))
+			continue
+		}
+		if _, err := maintenanceWindowFromConfig(m); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("maintenance[%d].%w", i, err))
+			continue
+		}
+		maintenanceNames[m.Name] = struct{}{}
+		validMaintenance = append(validMaintenance, m)
+	}
+	cfg.Maintenance = validMaintenance
+
+	// Validate clusters: names are required, unique DNS labels
+	validClusters := make([]ClusterConfig, 0, len(cfg.Clusters))
+	clusterNames := make(map[string]struct{}, len(cfg.Clusters))
+	for i, c := range cfg.Clusters {
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("clusters[%d].name: required field missing", i))
+			continue
+		}
+		if msgs := validation.IsDNS1123Label(c.Name); len(msgs) > 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("clusters[%d].name: invalid name %q: %s", i, c.Name, strings.Join(msgs, "; ")))
+			continue
+		}
+		if _, dup := clusterNames[c.Name]; dup {
+			validationErrors = append(validationErrors, fmt.Errorf("clusters[%d].name: duplicate name %q", i, c.Name))
+			continue
+		}
+		validationErrors = append(validationErrors, validateDiscovery(fmt.Sprintf("clusters[%d].discovery", i), c.Discovery)...)
+		clusterNames[c.Name] = struct{}{}
+		validClusters = append(validClusters, c)
+	}
+	cfg.Clusters = validClusters
+	validationErrors = append(validationErrors, validateDiscovery("discovery", cfg.Discovery)...)
+
+	// Validate remotes: names are unique DNS labels that are not also cluster
+	// names, since both qualify service keys the same way
+	validRemotes := make([]RemoteConfig, 0, len(cfg.Remotes))
+	remoteNames := make(map[string]struct{}, len(cfg.Remotes))
+	for i, r := range cfg.Remotes {
+		r.Name = strings.TrimSpace(r.Name)
+		r.URL = strings.TrimSpace(r.URL)
+		if r.Name == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("remotes[%d].name: required field missing", i))
+			continue
+		}
+		if msgs := validation.IsDNS1123Label(r.Name); len(msgs) > 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("remotes[%d].name: invalid name %q: %s", i, r.Name, strings.Join(msgs, "; ")))
+			continue
+		}
+		if _, dup := remoteNames[r.Name]; dup {
+			validationErrors = append(validationErrors, fmt.Errorf("remotes[%d].name: duplicate name %q", i, r.Name))
+			continue
+		}
+		if _, dup := clusterNames[r.Name]; dup {
+			validationErrors = append(validationErrors, fmt.Errorf("remotes[%d].name: %q is also a cluster name", i, r.Name))
+			continue
+		}
+		if r.URL == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("remotes[%d].url: required field missing", i))
+			continue
+		}
+		if parsed, err := url.Parse(r.URL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			validationErrors = append(validationErrors, fmt.Errorf("remotes[%d].url: invalid URL %q", i, r.URL))
+			continue
+		}
+		if (r.ClientCert == "") != (r.ClientKey == "") {
+			validationErrors = append(validationErrors, fmt.Errorf("remotes[%d]: clientCert and clientKey must be set together", i))
+			continue
+		}
+		remoteNames[r.Name] = struct{}{}
+		validRemotes = append(validRemotes, r)
+	}
+	cfg.Remotes = validRemotes
+
+	// Validate notification rules: a rule with an invalid selector is dropped
+	if n := cfg.Notifications; n != nil {
+		validRules := make([]NotificationRule, 0, len(n.Rules))
+		for i, rule := range n.Rules {
+			if rule.Selector != "" {
+				if _, err := labels.Parse(rule.Selector); err != nil {
+					validationErrors = append(validationErrors, fmt.Errorf("notifications.rules[%d].selector: %w", i, err))
+					continue
+				}
+			}
+			for _, c := range rule.Clusters {
+				if _, ok := clusterNames[c]; !ok {
+					validationErrors = append(validationErrors, fmt.Errorf("notifications.rules[%d].clusters: unknown cluster %q", i, c))
+				}
+			}
+			validRules = append(validRules, rule)
+		}
+		n.Rules = validRules
+	}
+
+	// Validate metrics endpoint
+	if m := cfg.Metrics; m != nil {
+		if m.ListenAddr != "" {
+			if _, _, err := net.SplitHostPort(m.ListenAddr); err != nil {
+				validationErrors = append(validationErrors, fmt.Errorf("metrics.listenAddr: invalid address %q", m.ListenAddr))
+				m.ListenAddr = ""
+			}
+		}
+		if m.Auth != nil {
+			if err := validateAuth(m.Auth); err != nil {
+				// Never fall back to serving metrics without the intended auth.
+				validationErrors = append(validationErrors, fmt.Errorf("metrics.auth%w", err))
+				m.Enabled = false
+			}
+		}
+	}
+
 	// Validate terminal config
 	if cfg.Terminal.Enabled && len(cfg.Terminal.AllowedCommands) == 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("terminal.allowedCommands: required when terminal is enabled"))
@@ -3,8 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func writeTempConfig(t *testing.T, content string) string {
@@ -302,6 +304,9 @@ func TestLoad_OverrideMatchFormat(t *testing.T) {
 		{"only slash", "/", false},
 		{"missing name", "default/", false},
 		{"missing namespace", "/radarr", false},
+		{"valid cluster/namespace/name", "lab/default/radarr", true},
+		{"missing cluster", "/default/radarr", false},
+		{"too many segments", "a/b/c/d/e", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -778,3 +783,800 @@ services:
 	}
 }
 	
+
+func TestLoad_ProbeValidation(t *testing.T) {
+	yaml := `
+services:
+  - name: "postgres"
+    url: "tcp://db.local:5432"
+    group: "data"
+    probe:
+      type: "TCP"
+  - name: "resolver"
+    url: "dns://192.168.1.1"
+    group: "network"
+    probe:
+      type: "dns"
+      query: "nas.home"
+      expect: ["192.168.1.10"]
+  - name: "broken-dns"
+    url: "dns://192.168.1.1"
+    group: "network"
+    probe:
+      type: "dns"
+  - name: "smtp"
+    url: "tcp://smtp.local:25"
+    group: "mail"
+    probe:
+      type: "icmp"
+
+overrides:
+  - match: "apps/api"
+    probe:
+      type: "grpc"
+      grpcService: "api.v1"
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 validation errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "services[2].probe.query") {
+		t.Errorf("expected dns query error, got %v", errs[0])
+	}
+	if !strings.Contains(errs[1].Error(), "services[3].probe.type") {
+		t.Errorf("expected probe type error, got %v", errs[1])
+	}
+
+	// Services with invalid probes are kept with the probe cleared.
+	if len(cfg.Services) != 4 {
+		t.Fatalf("expected 4 services, got %d", len(cfg.Services))
+	}
+	if p := cfg.Services[0].Probe; p == nil || p.Type != "tcp" {
+		t.Errorf("expected normalized tcp probe, got %+v", p)
+	}
+	if p := cfg.Services[1].Probe; p == nil || p.RecordType != "A" {
+		t.Errorf("expected dns record type to default to A, got %+v", p)
+	}
+	if cfg.Services[2].Probe != nil || cfg.Services[3].Probe != nil {
+		t.Error("expected invalid probes to be cleared")
+	}
+	if p := cfg.Overrides[0].Probe; p == nil || p.Type != "grpc" || p.GRPCService != "api.v1" {
+		t.Errorf("expected grpc override probe, got %+v", p)
+	}
+}
+
+func TestLoad_AssertionValidation(t *testing.T) {
+	yaml := `
+services:
+  - name: "nextcloud"
+    url: "https://cloud.local"
+    group: "apps"
+    assertions:
+      - jsonPath: "status"
+        equals: "ok"
+        onFailure: "Degraded"
+      - contains: "installed"
+      - regex: "("
+      - contains: "a"
+        regex: "b"
+      - maxBodyBytes: -1
+      - contains: "x"
+        onFailure: "broken"
+      - contains: "y"
+        equals: "z"
+      - jsonPath: "error"
+        equals: ""
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	if len(errs) != 5 {
+		t.Fatalf("expected 5 validation errors, got %d: %v", len(errs), errs)
+	}
+	for i, want := range []string{
+		"services[0].assertions[2].regex",
+		"services[0].assertions[3]: exactly one",
+		"services[0].assertions[4].maxBodyBytes",
+		"services[0].assertions[5].onFailure",
+		"services[0].assertions[6].equals",
+	} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want it to mention %q", i, errs[i], want)
+		}
+	}
+
+	got := cfg.Services[0].Assertions
+	if len(got) != 3 {
+		t.Fatalf("expected 3 valid assertions, got %d", len(got))
+	}
+	if got[2].Equals == nil || *got[2].Equals != "" {
+		t.Errorf("expected an empty equals to be kept, got %v", got[2].Equals)
+	}
+	if got[0].OnFailure != "degraded" {
+		t.Errorf("expected normalized onFailure %q, got %q", "degraded", got[0].OnFailure)
+	}
+	if got[1].OnFailure != "unhealthy" {
+		t.Errorf("expected default onFailure %q, got %q", "unhealthy", got[1].OnFailure)
+	}
+}
+
+func TestLoad_TimingValidation(t *testing.T) {
+	yaml := `
+health:
+  interval: "500ms"
+  timeout: "5s"
+
+groups:
+  media:
+    displayName: "Media"
+    interval: "5m"
+    timeout: "forever"
+
+services:
+  - name: "nas"
+    url: "https://nas.local"
+    group: "storage"
+    interval: "15s"
+    timeout: "3s"
+  - name: "printer"
+    url: "https://printer.local"
+    group: "office"
+    timeout: "-1s"
+
+overrides:
+  - match: "media/jellyfin"
+    interval: "2m"
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 validation errors, got %v", errs)
+	}
+	for i, want := range []string{"services[1].timeout", "health.interval", "groups.media.timeout"} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want mention of %s", i, errs[i], want)
+		}
+	}
+
+	if cfg.Health.Interval != "" || cfg.Health.Timeout != "5s" {
+		t.Errorf("health = %+v, want invalid interval cleared and timeout kept", cfg.Health)
+	}
+	if g := cfg.Groups["media"]; g.Interval != "5m" || g.Timeout != "" {
+		t.Errorf("group media = %+v, want interval kept and timeout cleared", g)
+	}
+	if s := cfg.Services[0]; s.Interval != "15s" || s.Timeout != "3s" {
+		t.Errorf("services[0] interval/timeout = %q/%q", s.Interval, s.Timeout)
+	}
+	if cfg.Services[1].Timeout != "" {
+		t.Errorf("expected invalid timeout cleared, got %q", cfg.Services[1].Timeout)
+	}
+	if cfg.Overrides[0].Interval != "2m" {
+		t.Errorf("override interval = %q, want 2m", cfg.Overrides[0].Interval)
+	}
+}
+
+func TestLoad_ThresholdValidation(t *testing.T) {
+	yaml := `
+health:
+  failureThreshold: 3
+  successThreshold: -1
+  flapThreshold: 4
+  flapWindow: "soon"
+  maxConcurrency: -1
+
+services:
+  - name: "wifi-ap"
+    url: "https://ap.local"
+    group: "network"
+    failureThreshold: 5
+  - name: "nas"
+    url: "https://nas.local"
+    group: "storage"
+    failureThreshold: -2
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 validation errors, got %v", errs)
+	}
+	for i, want := range []string{"services[1].failureThreshold", "health.successThreshold", "health.maxConcurrency", "health.flapWindow"} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want mention of %s", i, errs[i], want)
+		}
+	}
+
+	h := cfg.Health
+	if h.FailureThreshold != 3 || h.SuccessThreshold != 0 || h.FlapThreshold != 4 || h.FlapWindow != "" || h.MaxConcurrency != 0 {
+		t.Errorf("health = %+v, want invalid values reset", h)
+	}
+	if cfg.Services[0].FailureThreshold != 5 || cfg.Services[1].FailureThreshold != 0 {
+		t.Errorf("service thresholds = %d, %d; want 5, 0", cfg.Services[0].FailureThreshold, cfg.Services[1].FailureThreshold)
+	}
+}
+
+func TestLoad_HealthTLSValidation(t *testing.T) {
+	yaml := `
+health:
+  tls:
+    warningWindow: "720h"
+    caFile: "/nonexistent/ca.pem"
+    requireValidChain: true
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "health.tls.caFile") {
+		t.Fatalf("expected caFile error, got %v", errs)
+	}
+	tlsCfg := cfg.Health.TLS
+	if tlsCfg.WarningWindow != "720h" || tlsCfg.CAFile != "" || !tlsCfg.RequireValidChain {
+		t.Errorf("health.tls = %+v, want window kept, caFile cleared, chain required", tlsCfg)
+	}
+}
+
+func TestLoad_HTTPProbeValidation(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CC_TEST_API_KEY", "key")
+
+	yaml := `
+services:
+  - name: "api"
+    url: "https://api.local"
+    group: "apps"
+    probe:
+      method: "post"
+      host: "api.internal"
+      body: '{"ping":true}'
+      headers:
+        X-Api-Key:
+          env: "CC_TEST_API_KEY"
+        X-Static: "inline"
+      auth:
+        bearer:
+          file: "` + tokenFile + `"
+      followRedirects: false
+      redirectsHealthy: true
+  - name: "bad-method"
+    url: "https://a.local"
+    group: "apps"
+    probe:
+      method: "TRACE"
+  - name: "unset-env"
+    url: "https://b.local"
+    group: "apps"
+    probe:
+      auth:
+        basic:
+          username: "admin"
+          password:
+            env: "CC_TEST_UNSET"
+  - name: "both-auth"
+    url: "https://c.local"
+    group: "apps"
+    probe:
+      auth:
+        bearer: "tok"
+        basic:
+          username: "admin"
+          password: "pw"
+  - name: "half-cert"
+    url: "https://d.local"
+    group: "apps"
+    probe:
+      tls:
+        certFile: "` + tokenFile + `"
+  - name: "two-sources"
+    url: "https://e.local"
+    group: "apps"
+    probe:
+      headers:
+        X-Key:
+          value: "a"
+          env: "CC_TEST_API_KEY"
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		"services[1].probe.method",
+		"services[2].probe.auth.basic.password",
+		"services[3].probe.auth: set either bearer or basic",
+		"services[4].probe.tls: certFile and keyFile",
+		"services[5].probe.headers.X-Key",
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+
+	p := cfg.Services[0].Probe
+	if p == nil {
+		t.Fatal("expected valid probe to be kept")
+	}
+	if p.Method != "POST" || p.Host != "api.internal" || p.Body != `{"ping":true}` {
+		t.Errorf("unexpected request settings: %+v", p)
+	}
+	if p.Headers["X-Api-Key"].Env != "CC_TEST_API_KEY" || p.Headers["X-Static"].Value != "inline" {
+		t.Errorf("unexpected headers: %+v", p.Headers)
+	}
+	if p.Auth == nil || p.Auth.Bearer == nil || p.Auth.Bearer.File != tokenFile {
+		t.Errorf("unexpected auth: %+v", p.Auth)
+	}
+	if p.FollowRedirects == nil || *p.FollowRedirects || !p.RedirectsHealthy {
+		t.Errorf("unexpected redirect settings: %+v", p)
+	}
+	for i := 1; i < len(cfg.Services); i++ {
+		if cfg.Services[i].Probe != nil {
+			t.Errorf("services[%d]: expected invalid probe to be cleared", i)
+		}
+	}
+}
+
+func TestLoad_LatencyValidation(t *testing.T) {
+	yaml := `
+services:
+  - name: "nextcloud"
+    url: "https://cloud.local"
+    group: "apps"
+    latency:
+      degradedAboveMs: 2000
+      unhealthyAboveMs: 8000
+      p95: true
+  - name: "inverted"
+    url: "https://a.local"
+    group: "apps"
+    latency:
+      degradedAboveMs: 5000
+      unhealthyAboveMs: 1000
+  - name: "empty"
+    url: "https://b.local"
+    group: "apps"
+    latency:
+      p95: true
+
+overrides:
+  - match: "apps/api"
+    latency:
+      degradedAboveMs: -1
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		"services[1].latency.degradedAboveMs: must be below unhealthyAboveMs",
+		"services[2].latency: set degradedAboveMs or unhealthyAboveMs",
+		"overrides[0].latency.degradedAboveMs: must not be negative",
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+	if l := cfg.Services[0].Latency; l == nil || l.DegradedAboveMs != 2000 || l.UnhealthyAboveMs != 8000 || !l.P95 {
+		t.Errorf("unexpected latency: %+v", l)
+	}
+	if cfg.Services[1].Latency != nil || cfg.Services[2].Latency != nil || cfg.Overrides[0].Latency != nil {
+		t.Error("expected invalid latency blocks to be cleared")
+	}
+}
+
+func TestLoad_DependsOnValidation(t *testing.T) {
+	yaml := `
+services:
+  - name: "jellyfin"
+    url: "https://jellyfin.local"
+    group: "media"
+    dependsOn: ["truenas", " kube-system/traefik ", "a/b/c/d/e", "jellyfin", "lab/db/redis"]
+
+overrides:
+  - match: "media/sonarr"
+    dependsOn: ["media/sonarr", "custom/truenas"]
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		"services[0].dependsOn[2]: must be a service name, namespace/name, or cluster/namespace/name",
+		"services[0].dependsOn[3]: service cannot depend on itself",
+		"overrides[0].dependsOn[0]: service cannot depend on itself",
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+	if got := strings.Join(cfg.Services[0].DependsOn, ","); got != "truenas,kube-system/traefik,lab/db/redis" {
+		t.Errorf("services[0].dependsOn = %q", got)
+	}
+	if got := strings.Join(cfg.Overrides[0].DependsOn, ","); got != "custom/truenas" {
+		t.Errorf("overrides[0].dependsOn = %q", got)
+	}
+}
+
+func TestLoad_LabelValidation(t *testing.T) {
+	yaml := `
+services:
+  - name: "truenas"
+    url: "https://nas.local"
+    group: "storage"
+    labels:
+      tier: critical
+      "bad key!": x
+      owner: "not a valid value"
+
+overrides:
+  - match: "media/jellyfin"
+    labels:
+      example.com/exposure: public
+
+maintenance:
+  - name: "critical-freeze"
+    selector: "tier in (critical"
+    start: 2026-11-01T22:00:00Z
+    end: 2026-11-02T02:00:00Z
+
+notifications:
+  rules:
+    - selector: "tier=critical,exposure!=internal"
+      channels: ["pager"]
+    - services: ["*"]
+      selector: "tier in (critical"
+      channels: ["pager"]
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		`services[0].labels: invalid key "bad key!"`,
+		`services[0].labels.owner: invalid value "not a valid value"`,
+		"maintenance[0].selector: ",
+		"notifications.rules[1].selector: ",
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+	if got := cfg.Services[0].Labels; len(got) != 1 || got["tier"] != "critical" {
+		t.Errorf("services[0].labels = %v, want only tier", got)
+	}
+	if got := cfg.Overrides[0].Labels["example.com/exposure"]; got != "public" {
+		t.Errorf("overrides[0].labels = %v", cfg.Overrides[0].Labels)
+	}
+	if len(cfg.Maintenance) != 0 {
+		t.Errorf("expected window with invalid selector dropped, got %+v", cfg.Maintenance)
+	}
+	if len(cfg.Notifications.Rules) != 1 || cfg.Notifications.Rules[0].Selector != "tier=critical,exposure!=internal" {
+		t.Errorf("unexpected rules: %+v", cfg.Notifications.Rules)
+	}
+}
+
+func TestLoad_ClusterValidation(t *testing.T) {
+	yaml := `
+clusters:
+  - name: prod
+    kubeconfig: /etc/command-center/prod.kubeconfig
+  - name: lab
+    context: lab-admin
+  - kubeconfig: /etc/command-center/other.kubeconfig
+  - name: Lab_Cluster
+  - name: prod
+
+notifications:
+  rules:
+    - services: ["*"]
+      clusters: ["prod", "staging"]
+      channels: ["hook"]
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		"clusters[2].name: required field missing",
+		`clusters[3].name: invalid name "Lab_Cluster"`,
+		`clusters[4].name: duplicate name "prod"`,
+		`notifications.rules[0].clusters: unknown cluster "staging"`,
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+	if len(cfg.Clusters) != 2 || cfg.Clusters[0].Kubeconfig != "/etc/command-center/prod.kubeconfig" || cfg.Clusters[1].Context != "lab-admin" {
+		t.Errorf("clusters = %+v", cfg.Clusters)
+	}
+}
+
+func TestLoad_DiscoveryValidation(t *testing.T) {
+	yaml := `
+discovery:
+  excludeNamespaces: [kube-system, Not_A_Namespace]
+  labelSelector: "expose in (true"
+  fieldSelector: metadata.name!=internal
+  services:
+    enabled: true
+    nodeAddress: "not a host"
+
+clusters:
+  - name: lab
+    discovery:
+      namespaces: [media, apps]
+      labelSelector: expose=true
+      expandRules: true
+      services:
+        enabled: true
+        all: true
+        nodeAddress: 192.168.1.10
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		`discovery.excludeNamespaces[1]: invalid namespace "Not_A_Namespace"`,
+		"discovery.labelSelector:",
+		`discovery.services.nodeAddress: invalid host "not a host"`,
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+	d := cfg.Discovery
+	if !slices.Equal(d.ExcludeNamespaces, []string{"kube-system"}) || d.LabelSelector != "" || d.FieldSelector != "metadata.name!=internal" {
+		t.Errorf("discovery = %+v", d)
+	}
+	if s := d.Services; s == nil || !s.Enabled || s.NodeAddress != "" {
+		t.Errorf("discovery.services = %+v", s)
+	}
+	if c := cfg.Clusters[0].Discovery; c == nil || !slices.Equal(c.Namespaces, []string{"media", "apps"}) || c.LabelSelector != "expose=true" || !c.ExpandRules {
+		t.Errorf("cluster discovery = %+v", c)
+	}
+	if s := cfg.Clusters[0].Discovery.Services; s == nil || !s.All || s.NodeAddress != "192.168.1.10" {
+		t.Errorf("cluster discovery.services = %+v", s)
+	}
+}
+
+func TestLoad_RemoteValidation(t *testing.T) {
+	yaml := `
+clusters:
+  - name: lab
+
+remotes:
+  - name: east
+    url: https://cc.east.example.com:8443
+    caCert: /etc/command-center/east/ca.crt
+    clientCert: /etc/command-center/east/client.crt
+    clientKey: /etc/command-center/east/client.key
+  - url: https://cc.west.example.com
+  - name: east
+    url: https://cc.other.example.com
+  - name: lab
+    url: https://cc.lab.example.com
+  - name: west
+  - name: west
+    url: ftp://cc.west.example.com
+  - name: west
+    url: https://cc.west.example.com
+    clientCert: /etc/command-center/west/client.crt
+  - name: dev
+    url: http://localhost:8443
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		"remotes[1].name: required field missing",
+		`remotes[2].name: duplicate name "east"`,
+		`remotes[3].name: "lab" is also a cluster name`,
+		"remotes[4].url: required field missing",
+		`remotes[5].url: invalid URL "ftp://cc.west.example.com"`,
+		"remotes[6]: clientCert and clientKey must be set together",
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+	if len(cfg.Remotes) != 2 || cfg.Remotes[0].Name != "east" || cfg.Remotes[0].ClientKey == "" || cfg.Remotes[1].Name != "dev" {
+		t.Errorf("remotes = %+v", cfg.Remotes)
+	}
+}
+
+func TestLoad_MaintenanceValidation(t *testing.T) {
+	yaml := `
+maintenance:
+  - name: "nas-disk-swap"
+    reason: "Replacing a disk"
+    services: ["custom/truenas", "media/*"]
+    start: 2026-11-01T22:00:00Z
+    end: 2026-11-02T02:00:00Z
+  - name: "weekly-backup"
+    groups: ["storage"]
+    schedule: "0 3 * * 0"
+    duration: "2h"
+    timezone: "UTC"
+  - services: ["media/*"]
+    schedule: "0 3 * * *"
+    duration: "1h"
+  - name: "weekly-backup"
+    groups: ["storage"]
+    schedule: "0 4 * * 0"
+    duration: "1h"
+  - name: "bad-start"
+    groups: ["storage"]
+    start: "tomorrow"
+    end: 2026-11-02T02:00:00Z
+  - name: "bad-schedule"
+    groups: ["storage"]
+    schedule: "0 3 * *"
+    duration: "1h"
+`
+	path := writeTempConfig(t, yaml)
+	cfg, errs := Load(path)
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	wantErrs := []string{
+		"maintenance[2].name: required field missing",
+		`maintenance[3].name: duplicate name "weekly-backup"`,
+		"maintenance[4].start: invalid RFC 3339 timestamp",
+		"maintenance[5].schedule: expected 5 fields",
+	}
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("expected %d errors, got %v", len(wantErrs), errs)
+	}
+	for i, want := range wantErrs {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("errs[%d] = %v, want %q", i, errs[i], want)
+		}
+	}
+
+	windows := MaintenanceWindows(cfg)
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 valid windows, got %+v", windows)
+	}
+	oneOff := windows[0]
+	if oneOff.ID != "nas-disk-swap" || oneOff.Reason != "Replacing a disk" || oneOff.Start == nil ||
+		!oneOff.Start.Equal(time.Date(2026, 11, 1, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected one-off window: %+v", oneOff)
+	}
+	if !oneOff.ActiveAt(time.Date(2026, 11, 2, 1, 0, 0, 0, time.UTC)) {
+		t.Error("expected one-off window active inside its range")
+	}
+	if w := windows[1]; w.ID != "weekly-backup" || w.Schedule != "0 3 * * 0" || w.Source != "config" {
+		t.Errorf("unexpected recurring window: %+v", w)
+	}
+}
+
+func TestLoad_MetricsValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		wantErr     string
+		wantEnabled bool
+		wantAddr    string
+	}{
+		{
+			name: "bearer on a separate listener",
+			yaml: `
+metrics:
+  enabled: true
+  listenAddr: ":9090"
+  auth:
+    bearer: s3cret
+`,
+			wantEnabled: true,
+			wantAddr:    ":9090",
+		},
+		{
+			name: "invalid listen address",
+			yaml: `
+metrics:
+  enabled: true
+  listenAddr: "9090"
+`,
+			wantErr:     `metrics.listenAddr: invalid address "9090"`,
+			wantEnabled: true,
+		},
+		{
+			name: "basic auth without a password disables metrics",
+			yaml: `
+metrics:
+  enabled: true
+  auth:
+    basic:
+      username: prom
+`,
+			wantErr: "metrics.auth.basic.password:",
+		},
+		{
+			name: "bearer and basic together",
+			yaml: `
+metrics:
+  enabled: true
+  auth:
+    bearer:
+      value: token
+    basic:
+      username: prom
+      password:
+        value: pw
+`,
+			wantErr: "metrics.auth: set either",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, errs := Load(writeTempConfig(t, tt.yaml))
+			if cfg == nil || cfg.Metrics == nil {
+				t.Fatal("expected metrics config")
+			}
+			if tt.wantErr == "" && len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if tt.wantErr != "" && (len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.wantErr)) {
+				t.Fatalf("errors = %v, want one containing %q", errs, tt.wantErr)
+			}
+			if cfg.Metrics.Enabled != tt.wantEnabled {
+				t.Errorf("Enabled = %v, want %v", cfg.Metrics.Enabled, tt.wantEnabled)
+			}
+			if cfg.Metrics.ListenAddr != tt.wantAddr {
+				t.Errorf("ListenAddr = %q, want %q", cfg.Metrics.ListenAddr, tt.wantAddr)
+			}
+		})
+	}
+}
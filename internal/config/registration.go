@@ -1,8 +1,15 @@
 package config
 
 import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/rathix/command-center/internal/maintenance"
 	"github.com/rathix/command-center/internal/state"
 )
 
@@ -39,14 +46,19 @@ func ApplyOverrides(store StateUpdater, cfg *Config) {
 
         // 2. Process all services in the store
         for _, svc := range store.All() {
-                if svc.Source != state.SourceKubernetes {
+                // Federated services carry the overrides of their own site.
+                if svc.Source != state.SourceKubernetes || svc.Site != "" {
                         continue
                 }
 
-                match := svc.Namespace + "/" + svc.Name
-                ovr, hasOverride := overrides[match]
+                // A cluster-qualified match wins over "namespace/name", which
+                // applies in every cluster.
+                ovr, hasOverride := overrides[svc.Key()]
+                if !hasOverride {
+                        ovr, hasOverride = overrides[svc.Namespace+"/"+svc.Name]
+                }
 
-                store.Update(svc.Namespace, svc.Name, func(s *state.Service) {
+                store.Update(svc.ScopedNamespace(), svc.Name, func(s *state.Service) {
                         if hasOverride {
                                 applyOverride(s, ovr)
                         } else {
@@ -61,9 +73,18 @@ func restoreOriginals(svc *state.Service) {
                 return
         }
         svc.DisplayName = svc.OriginalDisplayName
-        svc.Icon = ""
-        svc.HealthURL = ""
-        svc.ExpectedStatusCodes = nil
+        svc.Icon = svc.OriginalIcon
+        svc.HealthURL = svc.OriginalHealthURL
+        svc.ExpectedStatusCodes = slices.Clone(svc.OriginalExpectedStatusCodes)
+        svc.Probe = svc.OriginalProbe.Clone()
+        svc.Assertions = nil
+        svc.CheckInterval = 0
+        svc.CheckTimeout = 0
+        svc.FailureThreshold = 0
+        svc.SuccessThreshold = 0
+        svc.Latency = nil
+        svc.DependsOn = slices.Clone(svc.OriginalDependsOn)
+        svc.Labels = maps.Clone(svc.OriginalLabels)
 }
 
 // ReconcileOnReload diffs old vs new config and applies additions, removals, and updates.
@@ -118,6 +139,15 @@ func ReconcileOnReload(store StateUpdater, oldCfg, newCfg *Config) (added, remov
                                 svc.HealthURL = newCS.HealthURL
                                 svc.ExpectedStatusCodes = newCS.ExpectedStatusCodes
                                 svc.Icon = newCS.Icon
+                                svc.Probe = probeSpecFromConfig(newCS.Probe)
+                                svc.Assertions = assertionsFromConfig(newCS.Assertions)
+                                svc.CheckInterval = ParseDurationOrZero(newCS.Interval)
+                                svc.CheckTimeout = ParseDurationOrZero(newCS.Timeout)
+                                svc.FailureThreshold = newCS.FailureThreshold
+                                svc.SuccessThreshold = newCS.SuccessThreshold
+                                svc.Latency = latencySLOFromConfig(newCS.Latency)
+                                svc.DependsOn = slices.Clone(newCS.DependsOn)
+                                svc.Labels = maps.Clone(newCS.Labels)
                         })
                         updated++
                 }
@@ -140,12 +170,21 @@ func customServiceToState(cs CustomService) state.Service {
                 OriginalDisplayName: displayName,
                 Namespace:           "custom",
                 Group:               cs.Group,
+                Labels:              maps.Clone(cs.Labels),
                 URL:                 cs.URL,
                 Source:              state.SourceConfig,
                 Status:              state.StatusUnknown,
                 HealthURL:      cs.HealthURL,
                 ExpectedStatusCodes: cs.ExpectedStatusCodes,
                 Icon:                cs.Icon,
+                Probe:               probeSpecFromConfig(cs.Probe),
+                Assertions:          assertionsFromConfig(cs.Assertions),
+                CheckInterval:       ParseDurationOrZero(cs.Interval),
+                CheckTimeout:        ParseDurationOrZero(cs.Timeout),
+                FailureThreshold:    cs.FailureThreshold,
+                SuccessThreshold:    cs.SuccessThreshold,
+                Latency:             latencySLOFromConfig(cs.Latency),
+                DependsOn:           slices.Clone(cs.DependsOn),
         }
 }
 
@@ -155,10 +194,186 @@ func applyOverride(svc *state.Service, ovr ServiceOverride) {
         } else {
                 svc.DisplayName = svc.OriginalDisplayName
         }
-        svc.HealthURL = ovr.HealthURL
-        svc.ExpectedStatusCodes = ovr.ExpectedStatusCodes
-        svc.Icon = ovr.Icon
+        // An override's fields take precedence over the discovered ones,
+        // which include those set by annotations.
+        svc.HealthURL = cmp.Or(ovr.HealthURL, svc.OriginalHealthURL)
+        if len(ovr.ExpectedStatusCodes) > 0 {
+                svc.ExpectedStatusCodes = ovr.ExpectedStatusCodes
+        } else {
+                svc.ExpectedStatusCodes = slices.Clone(svc.OriginalExpectedStatusCodes)
+        }
+        svc.Icon = cmp.Or(ovr.Icon, svc.OriginalIcon)
+        if ovr.Probe != nil {
+                svc.Probe = probeSpecFromConfig(ovr.Probe)
+        } else {
+                svc.Probe = svc.OriginalProbe.Clone()
+        }
+        svc.Assertions = assertionsFromConfig(ovr.Assertions)
+        svc.CheckInterval = ParseDurationOrZero(ovr.Interval)
+        svc.CheckTimeout = ParseDurationOrZero(ovr.Timeout)
+        svc.FailureThreshold = ovr.FailureThreshold
+        svc.SuccessThreshold = ovr.SuccessThreshold
+        svc.Latency = latencySLOFromConfig(ovr.Latency)
+        if len(ovr.DependsOn) > 0 {
+                svc.DependsOn = slices.Clone(ovr.DependsOn)
+        } else {
+                svc.DependsOn = slices.Clone(svc.OriginalDependsOn)
+        }
+        svc.Labels = maps.Clone(svc.OriginalLabels)
+        if len(ovr.Labels) > 0 {
+                if svc.Labels == nil {
+                        svc.Labels = make(map[string]string, len(ovr.Labels))
+                }
+                maps.Copy(svc.Labels, ovr.Labels)
+        }
+}
+
+// ParseDurationOrZero parses a duration that Load has already validated.
+// Empty or invalid input yields zero, meaning "inherit".
+func ParseDurationOrZero(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// probeSpecFromConfig converts a validated probe block into its state form.
+// A nil block yields nil so the service keeps the plain HTTP GET behavior.
+func probeSpecFromConfig(p *ProbeConfig) *state.ProbeSpec {
+	if p == nil {
+		return nil
+	}
+	spec := &state.ProbeSpec{
+		Type:        p.Type,
+		Address:     p.Address,
+		Query:       p.Query,
+		RecordType:  p.RecordType,
+		GRPCService: p.GRPCService,
+	}
+	if p.Expect != nil {
+		spec.Expect = make([]string, len(p.Expect))
+		copy(spec.Expect, p.Expect)
+	}
+	spec.Method = p.Method
+	spec.Host = p.Host
+	spec.Body = p.Body
+	spec.DisableRedirects = p.FollowRedirects != nil && !*p.FollowRedirects
+	spec.RedirectsHealthy = p.RedirectsHealthy
+	if len(p.Headers) > 0 {
+		spec.Headers = make(map[string]state.SecretRef, len(p.Headers))
+		for name, v := range p.Headers {
+			spec.Headers[name] = secretRefFromConfig(v)
+		}
+	}
+	if p.Auth != nil {
+		if p.Auth.Bearer != nil {
+			ref := secretRefFromConfig(*p.Auth.Bearer)
+			spec.BearerToken = &ref
+		}
+		if p.Auth.Basic != nil {
+			spec.BasicAuth = &state.BasicAuth{
+				Username: p.Auth.Basic.Username,
+				Password: secretRefFromConfig(p.Auth.Basic.Password),
+			}
+		}
+	}
+	if p.TLS != nil {
+		spec.CAFile = p.TLS.CAFile
+		spec.CertFile = p.TLS.CertFile
+		spec.KeyFile = p.TLS.KeyFile
+		spec.ServerName = p.TLS.ServerName
+	}
+	return spec
 }
+
+// latencySLOFromConfig converts a validated latency block into its state form.
+func latencySLOFromConfig(l *LatencyConfig) *state.LatencySLO {
+	if l == nil {
+		return nil
+	}
+	return &state.LatencySLO{
+		DegradedAboveMs:  l.DegradedAboveMs,
+		UnhealthyAboveMs: l.UnhealthyAboveMs,
+		P95:              l.P95,
+		Window:           l.Window,
+	}
+}
+
+// MaintenanceWindows converts the validated maintenance windows of cfg.
+func MaintenanceWindows(cfg *Config) []maintenance.Window {
+	if cfg == nil {
+		return nil
+	}
+	windows := make([]maintenance.Window, 0, len(cfg.Maintenance))
+	for _, m := range cfg.Maintenance {
+		if w, err := maintenanceWindowFromConfig(m); err == nil {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// maintenanceWindowFromConfig converts and validates a maintenance block.
+// Errors name the offending field, e.g. "start: ...".
+func maintenanceWindowFromConfig(m MaintenanceConfig) (maintenance.Window, error) {
+	w := maintenance.Window{
+		ID:       m.Name,
+		Reason:   m.Reason,
+		Services: m.Services,
+		Groups:   m.Groups,
+		Selector: m.Selector,
+		Schedule: m.Schedule,
+		Duration: m.Duration,
+		Timezone: m.Timezone,
+		Source:   maintenance.SourceConfig,
+	}
+	for _, ts := range []struct {
+		field string
+		value string
+		dst   **time.Time
+	}{
+		{"start", m.Start, &w.Start},
+		{"end", m.End, &w.End},
+	} {
+		if ts.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, ts.value)
+		if err != nil {
+			return maintenance.Window{}, fmt.Errorf("%s: invalid RFC 3339 timestamp %q", ts.field, ts.value)
+		}
+		*ts.dst = &t
+	}
+	if err := w.Validate(); err != nil {
+		return maintenance.Window{}, err
+	}
+	return w, nil
+}
+
+func secretRefFromConfig(s SecretConfig) state.SecretRef {
+	return state.SecretRef{Value: s.Value, Env: s.Env, File: s.File}
+}
+
+// assertionsFromConfig converts validated body assertions into their state form.
+func assertionsFromConfig(list []AssertionConfig) []state.BodyAssertion {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make([]state.BodyAssertion, 0, len(list))
+	for _, a := range list {
+		out = append(out, state.BodyAssertion{
+			Contains:     a.Contains,
+			Regex:        a.Regex,
+			JSONPath:     a.JSONPath,
+			Equals:       a.Equals,
+			MaxBodyBytes: a.MaxBodyBytes,
+			OnFailure:    state.HealthStatus(a.OnFailure),
+		})
+	}
+	return out
+}
+
 func parseMatch(match string) (namespace, name string, ok bool) {
 	parts := strings.SplitN(match, "/", 2)
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
@@ -173,17 +388,44 @@ func customServiceEqual(a, b CustomService) bool {
 		a.DisplayName == b.DisplayName &&
 		a.HealthURL == b.HealthURL &&
 		a.Icon == b.Icon &&
-		intSliceEqual(a.ExpectedStatusCodes, b.ExpectedStatusCodes)
+		slices.Equal(a.ExpectedStatusCodes, b.ExpectedStatusCodes) &&
+		probeConfigEqual(a.Probe, b.Probe) &&
+		slices.EqualFunc(a.Assertions, b.Assertions, assertionConfigEqual) &&
+		a.Interval == b.Interval &&
+		a.Timeout == b.Timeout &&
+		a.FailureThreshold == b.FailureThreshold &&
+		a.SuccessThreshold == b.SuccessThreshold &&
+		reflect.DeepEqual(a.Latency, b.Latency) &&
+		slices.Equal(a.DependsOn, b.DependsOn) &&
+		maps.Equal(a.Labels, b.Labels)
 }
 
-func intSliceEqual(a, b []int) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
+func assertionConfigEqual(a, b AssertionConfig) bool {
+	return a.Contains == b.Contains &&
+		a.Regex == b.Regex &&
+		a.JSONPath == b.JSONPath &&
+		(a.Equals == nil) == (b.Equals == nil) &&
+		(a.Equals == nil || *a.Equals == *b.Equals) &&
+		a.MaxBodyBytes == b.MaxBodyBytes &&
+		a.OnFailure == b.OnFailure
+}
+
+func probeConfigEqual(a, b *ProbeConfig) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
-	return true
+	return a.Type == b.Type &&
+		a.Address == b.Address &&
+		a.Query == b.Query &&
+		a.RecordType == b.RecordType &&
+		a.GRPCService == b.GRPCService &&
+		slices.Equal(a.Expect, b.Expect) &&
+		a.Method == b.Method &&
+		a.Host == b.Host &&
+		a.Body == b.Body &&
+		a.RedirectsHealthy == b.RedirectsHealthy &&
+		reflect.DeepEqual(a.FollowRedirects, b.FollowRedirects) &&
+		maps.Equal(a.Headers, b.Headers) &&
+		reflect.DeepEqual(a.Auth, b.Auth) &&
+		reflect.DeepEqual(a.TLS, b.TLS)
 }
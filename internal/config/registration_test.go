@@ -1,8 +1,10 @@
 package config
 
 import (
+	"maps"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/rathix/command-center/internal/state"
 )
@@ -21,7 +23,7 @@ func newFakeStore() *fakeStore {
 func (f *fakeStore) AddOrUpdate(svc state.Service) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	f.services[svc.Namespace+"/"+svc.Name] = svc
+	f.services[svc.Key()] = svc
 }
 
 func (f *fakeStore) Remove(namespace, name string) {
@@ -155,6 +157,34 @@ func TestApplyOverrides_DisplayNameUpdated(t *testing.T) {
 	}
 }
 
+func TestApplyOverrides_ClusterMatchTakesPrecedence(t *testing.T) {
+	store := newFakeStore()
+	for _, cluster := range []string{"", "prod", "lab"} {
+		store.AddOrUpdate(state.Service{Name: "pihole", Namespace: "default", Cluster: cluster, Source: state.SourceKubernetes})
+	}
+	store.AddOrUpdate(state.Service{Name: "pihole", DisplayName: "East Pi-hole", Namespace: "default", Site: "east", Source: state.SourceKubernetes})
+
+	cfg := &Config{
+		Overrides: []ServiceOverride{
+			{Match: "default/pihole", DisplayName: "Pi-hole"},
+			{Match: "lab/default/pihole", DisplayName: "Lab Pi-hole"},
+		},
+	}
+
+	ApplyOverrides(store, cfg)
+
+	for _, tt := range []struct{ namespace, want string }{
+		{"default", "Pi-hole"},
+		{"prod/default", "Pi-hole"},
+		{"lab/default", "Lab Pi-hole"},
+		{"east/default", "East Pi-hole"}, // federated services keep their site's overrides
+	} {
+		if svc, _ := store.Get(tt.namespace, "pihole"); svc.DisplayName != tt.want {
+			t.Errorf("%s/pihole displayName = %q, want %q", tt.namespace, svc.DisplayName, tt.want)
+		}
+	}
+}
+
 func TestReconcileOnReload_AddedServiceTriggersAddOrUpdate(t *testing.T) {
 	store := newFakeStore()
 	oldCfg := &Config{}
@@ -325,3 +355,275 @@ func TestApplyOverrides_HealthURLAndExpectedStatusCodes(t *testing.T) {
 		t.Errorf("expected icon %q, got %q", "radarr", svc.Icon)
 	}
 }
+
+func TestApplyOverrides_TakePrecedenceOverAnnotations(t *testing.T) {
+	store := newFakeStore()
+	store.AddOrUpdate(state.Service{
+		Name: "svc", Namespace: "default", Source: state.SourceKubernetes,
+		DisplayName: "Annotated", OriginalDisplayName: "Annotated",
+		Icon: "annotated", OriginalIcon: "annotated",
+		HealthURL: "https://svc.local/ready", OriginalHealthURL: "https://svc.local/ready",
+		ExpectedStatusCodes: []int{204}, OriginalExpectedStatusCodes: []int{204},
+	})
+
+	// Fields the override sets win; the others keep the annotated values.
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "default/svc", Icon: "override", ExpectedStatusCodes: []int{200}},
+	}})
+	svc, _ := store.Get("default", "svc")
+	if svc.Icon != "override" || len(svc.ExpectedStatusCodes) != 1 || svc.ExpectedStatusCodes[0] != 200 {
+		t.Errorf("icon %q, expectedStatusCodes %v, want the override's", svc.Icon, svc.ExpectedStatusCodes)
+	}
+	if svc.DisplayName != "Annotated" || svc.HealthURL != "https://svc.local/ready" {
+		t.Errorf("displayName %q, healthUrl %q, want the annotated values", svc.DisplayName, svc.HealthURL)
+	}
+
+	// Removing the override restores the annotated values.
+	ApplyOverrides(store, &Config{})
+	svc, _ = store.Get("default", "svc")
+	if svc.Icon != "annotated" || len(svc.ExpectedStatusCodes) != 1 || svc.ExpectedStatusCodes[0] != 204 {
+		t.Errorf("icon %q, expectedStatusCodes %v, want the annotated values restored", svc.Icon, svc.ExpectedStatusCodes)
+	}
+}
+
+func TestReconcileOnReload_ProbeChangeUpdatesService(t *testing.T) {
+	store := newFakeStore()
+	oldCfg := &Config{Services: []CustomService{
+		{Name: "postgres", URL: "tcp://db.local:5432", Group: "data"},
+	}}
+	RegisterServices(store, oldCfg)
+
+	newCfg := &Config{Services: []CustomService{
+		{Name: "postgres", URL: "tcp://db.local:5432", Group: "data", Probe: &ProbeConfig{Type: "tcp"}},
+	}}
+	_, _, updated := ReconcileOnReload(store, oldCfg, newCfg)
+	if updated != 1 {
+		t.Fatalf("expected 1 updated service, got %d", updated)
+	}
+
+	svc, _ := store.Get("custom", "postgres")
+	if svc.Probe == nil || svc.Probe.Type != state.ProbeTCP {
+		t.Errorf("expected tcp probe on service, got %+v", svc.Probe)
+	}
+}
+
+func TestApplyOverrides_ProbeAppliedAndRestored(t *testing.T) {
+	store := newFakeStore()
+	store.AddOrUpdate(state.Service{Name: "api", Namespace: "apps", Source: state.SourceKubernetes})
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "apps/api", Probe: &ProbeConfig{Type: "grpc", GRPCService: "api.v1"}},
+	}})
+	svc, _ := store.Get("apps", "api")
+	if svc.Probe == nil || svc.Probe.Type != state.ProbeGRPC || svc.Probe.GRPCService != "api.v1" {
+		t.Fatalf("expected grpc probe from override, got %+v", svc.Probe)
+	}
+
+	ApplyOverrides(store, &Config{})
+	svc, _ = store.Get("apps", "api")
+	if svc.Probe != nil {
+		t.Errorf("expected probe cleared after override removal, got %+v", svc.Probe)
+	}
+}
+
+func TestApplyOverrides_ProbeRestoresDiscovered(t *testing.T) {
+	store := newFakeStore()
+	store.AddOrUpdate(state.Service{
+		Name: "plex", Namespace: "media", Source: state.SourceKubernetes,
+		Probe: &state.ProbeSpec{Type: state.ProbeTCP}, OriginalProbe: &state.ProbeSpec{Type: state.ProbeTCP},
+	})
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "media/plex", DisplayName: "Plex"},
+	}})
+	svc, _ := store.Get("media", "plex")
+	if svc.Probe == nil || svc.Probe.Type != state.ProbeTCP {
+		t.Fatalf("expected annotation probe kept without override probe, got %+v", svc.Probe)
+	}
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "media/plex", Probe: &ProbeConfig{Type: "tls"}},
+	}})
+	svc, _ = store.Get("media", "plex")
+	if svc.Probe == nil || svc.Probe.Type != state.ProbeTLS {
+		t.Fatalf("expected override probe, got %+v", svc.Probe)
+	}
+
+	ApplyOverrides(store, &Config{})
+	svc, _ = store.Get("media", "plex")
+	if svc.Probe == nil || svc.Probe.Type != state.ProbeTCP {
+		t.Errorf("expected discovered probe restored, got %+v", svc.Probe)
+	}
+}
+
+func TestReconcileOnReload_IntervalChangeUpdatesService(t *testing.T) {
+	store := newFakeStore()
+	oldCfg := &Config{Services: []CustomService{
+		{Name: "nas", URL: "https://nas.local", Group: "storage"},
+	}}
+	RegisterServices(store, oldCfg)
+
+	newCfg := &Config{Services: []CustomService{
+		{Name: "nas", URL: "https://nas.local", Group: "storage", Interval: "15s", Timeout: "3s"},
+	}}
+	_, _, updated := ReconcileOnReload(store, oldCfg, newCfg)
+	if updated != 1 {
+		t.Fatalf("expected 1 updated service, got %d", updated)
+	}
+
+	svc, _ := store.Get("custom", "nas")
+	if svc.CheckInterval != 15*time.Second || svc.CheckTimeout != 3*time.Second {
+		t.Errorf("interval/timeout = %v/%v, want 15s/3s", svc.CheckInterval, svc.CheckTimeout)
+	}
+}
+
+func TestApplyOverrides_IntervalAppliedAndRestored(t *testing.T) {
+	store := newFakeStore()
+	store.AddOrUpdate(state.Service{Name: "jellyfin", Namespace: "media", Source: state.SourceKubernetes})
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "media/jellyfin", Interval: "2m"},
+	}})
+	svc, _ := store.Get("media", "jellyfin")
+	if svc.CheckInterval != 2*time.Minute {
+		t.Fatalf("expected 2m interval from override, got %v", svc.CheckInterval)
+	}
+
+	ApplyOverrides(store, &Config{})
+	svc, _ = store.Get("media", "jellyfin")
+	if svc.CheckInterval != 0 {
+		t.Errorf("expected interval cleared after override removal, got %v", svc.CheckInterval)
+	}
+}
+
+func TestReconcileOnReload_ProbeHeaderChangeUpdatesService(t *testing.T) {
+	store := newFakeStore()
+	follow := false
+	oldCfg := &Config{Services: []CustomService{{
+		Name: "api", URL: "https://api.local", Group: "apps",
+		Probe: &ProbeConfig{Headers: map[string]SecretConfig{"X-Api-Key": {Env: "OLD_KEY"}}},
+	}}}
+	RegisterServices(store, oldCfg)
+
+	newCfg := &Config{Services: []CustomService{{
+		Name: "api", URL: "https://api.local", Group: "apps",
+		Probe: &ProbeConfig{
+			Headers:         map[string]SecretConfig{"X-Api-Key": {Env: "NEW_KEY"}},
+			Auth:            &AuthConfig{Basic: &BasicAuthConfig{Username: "admin", Password: SecretConfig{File: "/run/secrets/pw"}}},
+			TLS:             &ProbeTLSConfig{ServerName: "api.internal"},
+			FollowRedirects: &follow,
+		},
+	}}}
+	_, _, updated := ReconcileOnReload(store, oldCfg, newCfg)
+	if updated != 1 {
+		t.Fatalf("expected 1 updated service, got %d", updated)
+	}
+
+	svc, _ := store.Get("custom", "api")
+	p := svc.Probe
+	if p == nil || p.Headers["X-Api-Key"].Env != "NEW_KEY" {
+		t.Fatalf("expected updated header secret, got %+v", p)
+	}
+	if p.BasicAuth == nil || p.BasicAuth.Username != "admin" || p.BasicAuth.Password.File != "/run/secrets/pw" {
+		t.Errorf("unexpected basic auth: %+v", p.BasicAuth)
+	}
+	if p.ServerName != "api.internal" || !p.DisableRedirects {
+		t.Errorf("unexpected TLS/redirect settings: %+v", p)
+	}
+}
+
+func TestApplyOverrides_LatencyAppliedAndRestored(t *testing.T) {
+	store := newFakeStore()
+	store.AddOrUpdate(state.Service{Name: "cloud", Namespace: "apps", Source: state.SourceKubernetes})
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "apps/cloud", Latency: &LatencyConfig{DegradedAboveMs: 2000, P95: true, Window: 30}},
+	}})
+	svc, _ := store.Get("apps", "cloud")
+	want := state.LatencySLO{DegradedAboveMs: 2000, P95: true, Window: 30}
+	if svc.Latency == nil || *svc.Latency != want {
+		t.Fatalf("expected latency SLO %+v from override, got %+v", want, svc.Latency)
+	}
+
+	ApplyOverrides(store, &Config{})
+	svc, _ = store.Get("apps", "cloud")
+	if svc.Latency != nil {
+		t.Errorf("expected latency SLO cleared after override removal, got %+v", svc.Latency)
+	}
+}
+
+func TestApplyOverrides_DependsOnRestoresDiscovered(t *testing.T) {
+	store := newFakeStore()
+	store.AddOrUpdate(state.Service{
+		Name: "jellyfin", Namespace: "media", Source: state.SourceKubernetes,
+		DependsOn: []string{"custom/truenas"}, OriginalDependsOn: []string{"custom/truenas"},
+	})
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "media/jellyfin", DisplayName: "Jellyfin"},
+	}})
+	svc, _ := store.Get("media", "jellyfin")
+	if len(svc.DependsOn) != 1 || svc.DependsOn[0] != "custom/truenas" {
+		t.Fatalf("expected annotation dependsOn kept without override value, got %v", svc.DependsOn)
+	}
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "media/jellyfin", DependsOn: []string{"kube-system/traefik"}},
+	}})
+	svc, _ = store.Get("media", "jellyfin")
+	if len(svc.DependsOn) != 1 || svc.DependsOn[0] != "kube-system/traefik" {
+		t.Fatalf("expected override dependsOn, got %v", svc.DependsOn)
+	}
+
+	ApplyOverrides(store, &Config{})
+	svc, _ = store.Get("media", "jellyfin")
+	if len(svc.DependsOn) != 1 || svc.DependsOn[0] != "custom/truenas" {
+		t.Errorf("expected discovered dependsOn restored, got %v", svc.DependsOn)
+	}
+}
+
+func TestApplyOverrides_LabelsMergedAndRestored(t *testing.T) {
+	store := newFakeStore()
+	discovered := map[string]string{"tier": "standard", "owner": "media-team"}
+	store.AddOrUpdate(state.Service{
+		Name: "jellyfin", Namespace: "media", Source: state.SourceKubernetes,
+		Labels: maps.Clone(discovered), OriginalLabels: maps.Clone(discovered),
+	})
+
+	ApplyOverrides(store, &Config{Overrides: []ServiceOverride{
+		{Match: "media/jellyfin", Labels: map[string]string{"tier": "critical", "exposure": "public"}},
+	}})
+	svc, _ := store.Get("media", "jellyfin")
+	want := map[string]string{"tier": "critical", "owner": "media-team", "exposure": "public"}
+	if !maps.Equal(svc.Labels, want) {
+		t.Fatalf("Labels = %v, want %v", svc.Labels, want)
+	}
+	if !maps.Equal(svc.OriginalLabels, discovered) {
+		t.Errorf("OriginalLabels = %v, want discovered labels unchanged", svc.OriginalLabels)
+	}
+
+	ApplyOverrides(store, &Config{})
+	svc, _ = store.Get("media", "jellyfin")
+	if !maps.Equal(svc.Labels, discovered) {
+		t.Errorf("expected discovered labels restored, got %v", svc.Labels)
+	}
+}
+
+func TestReconcileOnReload_LabelChangeUpdatesService(t *testing.T) {
+	store := newFakeStore()
+	oldCfg := &Config{Services: []CustomService{
+		{Name: "truenas", URL: "https://nas.local", Group: "storage", Labels: map[string]string{"tier": "standard"}},
+	}}
+	RegisterServices(store, oldCfg)
+	newCfg := &Config{Services: []CustomService{
+		{Name: "truenas", URL: "https://nas.local", Group: "storage", Labels: map[string]string{"tier": "critical"}},
+	}}
+
+	if _, _, updated := ReconcileOnReload(store, oldCfg, newCfg); updated != 1 {
+		t.Fatalf("expected 1 update, got %d", updated)
+	}
+	svc, _ := store.Get("custom", "truenas")
+	if svc.Labels["tier"] != "critical" {
+		t.Errorf("Labels = %v, want tier=critical", svc.Labels)
+	}
+}
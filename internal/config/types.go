@@ -1,5 +1,7 @@
 package config
 
+import "gopkg.in/yaml.v3"
+
 // Config is the top-level configuration parsed from the YAML config file.
 type Config struct {
 	Services      []CustomService        `yaml:"services"      json:"services"`
@@ -7,11 +9,95 @@ type Config struct {
 	Groups        map[string]GroupConfig `yaml:"groups"        json:"groups"`
 	Health        HealthConfig           `yaml:"health"        json:"health"`
 	History       HistoryConfig          `yaml:"history"       json:"history"`
+	Maintenance   []MaintenanceConfig    `yaml:"maintenance"   json:"maintenance,omitempty"`
+	Metrics       *MetricsConfig         `yaml:"metrics"       json:"metrics,omitempty"`
 	Notifications *NotificationsConfig   `yaml:"notifications" json:"notifications,omitempty"`
 	Talos         *TalosConfig           `yaml:"talos"         json:"talos,omitempty"`
 	Keyboard      *KeyboardConfig        `yaml:"keyboard"      json:"keyboard,omitempty"`
 	Terminal      TerminalConfig         `yaml:"terminal"      json:"terminal"`
 	GitOps        *GitOpsConfig          `yaml:"gitops"        json:"gitops,omitempty"`
+	Clusters      []ClusterConfig        `yaml:"clusters"      json:"clusters,omitempty"`
+	Remotes       []RemoteConfig         `yaml:"remotes"       json:"remotes,omitempty"`
+	Discovery     *DiscoveryConfig       `yaml:"discovery"     json:"discovery,omitempty"`
+}
+
+// DiscoveryConfig limits which Kubernetes objects services are discovered
+// from. Namespaces, if set, are watched alone; otherwise the whole cluster
+// is, less ExcludeNamespaces. LabelSelector and FieldSelector filter the
+// Ingresses, routes and Services. Services, if enabled, also discovers
+// LoadBalancer and NodePort Services. ExpandRules expands every Ingress into
+// one service per host and path, unless annotated otherwise.
+type DiscoveryConfig struct {
+	Namespaces        []string                `yaml:"namespaces"        json:"namespaces,omitempty"`
+	ExcludeNamespaces []string                `yaml:"excludeNamespaces" json:"excludeNamespaces,omitempty"`
+	LabelSelector     string                  `yaml:"labelSelector"     json:"labelSelector,omitempty"`
+	FieldSelector     string                  `yaml:"fieldSelector"     json:"fieldSelector,omitempty"`
+	Services          *ServiceDiscoveryConfig `yaml:"services"          json:"services,omitempty"`
+	ExpandRules       bool                    `yaml:"expandRules"       json:"expandRules,omitempty"`
+}
+
+// ServiceDiscoveryConfig opts into discovering LoadBalancer and NodePort
+// Services: those annotated command-center.io/discover: "true", or all of
+// them with All. NodeAddress is the host of NodePort Services' URLs; without
+// it they are skipped.
+type ServiceDiscoveryConfig struct {
+	Enabled     bool   `yaml:"enabled"     json:"enabled"`
+	All         bool   `yaml:"all"         json:"all,omitempty"`
+	NodeAddress string `yaml:"nodeAddress" json:"nodeAddress,omitempty"`
+}
+
+// ClusterConfig is a Kubernetes cluster to discover services in. Its
+// services' keys are qualified by Name ("name/namespace/service"). Kubeconfig
+// is the path of its kubeconfig file (default: the --kubeconfig flag) and
+// Context the context to use from it (default: its current context).
+// Discovery, if set, replaces the top-level discovery scope for it.
+type ClusterConfig struct {
+	Name       string           `yaml:"name"       json:"name"`
+	Kubeconfig string           `yaml:"kubeconfig" json:"kubeconfig,omitempty"`
+	Context    string           `yaml:"context"    json:"context,omitempty"`
+	Discovery  *DiscoveryConfig `yaml:"discovery"  json:"discovery,omitempty"`
+}
+
+// RemoteConfig is a remote Command Center instance whose services are
+// federated into this one as site Name, with keys qualified by it
+// ("name/namespace/service"). URL is its base URL. ClientCert and ClientKey
+// are the client certificate presented to it, and CACert the CA that signed
+// its server certificate (default: the system roots).
+type RemoteConfig struct {
+	Name       string `yaml:"name"       json:"name"`
+	URL        string `yaml:"url"        json:"url"`
+	CACert     string `yaml:"caCert"     json:"caCert,omitempty"`
+	ClientCert string `yaml:"clientCert" json:"clientCert,omitempty"`
+	ClientKey  string `yaml:"clientKey"  json:"clientKey,omitempty"`
+}
+
+// MaintenanceConfig schedules a maintenance window, either one-off from
+// Start to End (RFC 3339 timestamps) or recurring on a five-field cron
+// Schedule for Duration, read in Timezone (default: the server's local
+// time). Services lists service keys ("namespace/name") or glob patterns;
+// Groups lists group names; Selector is a label selector.
+type MaintenanceConfig struct {
+	Name     string   `yaml:"name"     json:"name"`
+	Reason   string   `yaml:"reason"   json:"reason,omitempty"`
+	Services []string `yaml:"services" json:"services,omitempty"`
+	Groups   []string `yaml:"groups"   json:"groups,omitempty"`
+	Selector string   `yaml:"selector" json:"selector,omitempty"`
+	Start    string   `yaml:"start"    json:"start,omitempty"`
+	End      string   `yaml:"end"      json:"end,omitempty"`
+	Schedule string   `yaml:"schedule" json:"schedule,omitempty"`
+	Duration string   `yaml:"duration" json:"duration,omitempty"`
+	Timezone string   `yaml:"timezone" json:"timezone,omitempty"`
+}
+
+// MetricsConfig enables the Prometheus /metrics endpoint. By default it is
+// served on the main listener behind mTLS like the rest of the API. With Auth
+// set it bypasses mTLS and requires those credentials instead, so a scraper
+// needs no client certificate. ListenAddr serves it on a separate plain HTTP
+// listener instead, where it is open unless Auth is set.
+type MetricsConfig struct {
+	Enabled    bool        `yaml:"enabled"    json:"enabled"`
+	ListenAddr string      `yaml:"listenAddr" json:"listenAddr,omitempty"`
+	Auth       *AuthConfig `yaml:"auth"       json:"auth,omitempty"`
 }
 
 // TalosConfig configures the Talos gRPC API connection for node management.
@@ -38,35 +124,174 @@ type GitOpsConfig struct {
 
 // CustomService defines a non-Kubernetes service to monitor.
 type CustomService struct {
-	Name                string `yaml:"name"                json:"name"`
-	URL                 string `yaml:"url"                 json:"url"`
-	Group               string `yaml:"group"               json:"group"`
-	DisplayName         string `yaml:"displayName"         json:"displayName"`
-	HealthURL           string `yaml:"healthUrl"           json:"healthUrl"`
-	ExpectedStatusCodes []int  `yaml:"expectedStatusCodes" json:"expectedStatusCodes"`
-	Icon                string `yaml:"icon"                json:"icon"`
+	Name                string            `yaml:"name"                json:"name"`
+	URL                 string            `yaml:"url"                 json:"url"`
+	Group               string            `yaml:"group"               json:"group"`
+	DisplayName         string            `yaml:"displayName"         json:"displayName"`
+	HealthURL           string            `yaml:"healthUrl"           json:"healthUrl"`
+	ExpectedStatusCodes []int             `yaml:"expectedStatusCodes" json:"expectedStatusCodes"`
+	Icon                string            `yaml:"icon"                json:"icon"`
+	Labels              map[string]string `yaml:"labels"              json:"labels,omitempty"`
+	Probe               *ProbeConfig      `yaml:"probe"               json:"probe,omitempty"`
+	Assertions          []AssertionConfig `yaml:"assertions"          json:"assertions,omitempty"`
+	Interval            string            `yaml:"interval"            json:"interval,omitempty"`
+	Timeout             string            `yaml:"timeout"             json:"timeout,omitempty"`
+	FailureThreshold    int               `yaml:"failureThreshold"    json:"failureThreshold,omitempty"`
+	SuccessThreshold    int               `yaml:"successThreshold"    json:"successThreshold,omitempty"`
+	Latency             *LatencyConfig    `yaml:"latency"             json:"latency,omitempty"`
+	DependsOn           []string          `yaml:"dependsOn"           json:"dependsOn,omitempty"`
 }
 
 // ServiceOverride overrides properties of a Kubernetes-discovered service.
+// Labels are added to the discovered labels, replacing any with the same key.
 type ServiceOverride struct {
-	Match               string `yaml:"match"               json:"match"`
-	DisplayName         string `yaml:"displayName"         json:"displayName"`
-	HealthURL           string `yaml:"healthUrl"           json:"healthUrl"`
-	ExpectedStatusCodes []int  `yaml:"expectedStatusCodes" json:"expectedStatusCodes"`
-	Icon                string `yaml:"icon"                json:"icon"`
+	Match               string            `yaml:"match"               json:"match"`
+	DisplayName         string            `yaml:"displayName"         json:"displayName"`
+	HealthURL           string            `yaml:"healthUrl"           json:"healthUrl"`
+	ExpectedStatusCodes []int             `yaml:"expectedStatusCodes" json:"expectedStatusCodes"`
+	Icon                string            `yaml:"icon"                json:"icon"`
+	Labels              map[string]string `yaml:"labels"              json:"labels,omitempty"`
+	Probe               *ProbeConfig      `yaml:"probe"               json:"probe,omitempty"`
+	Assertions          []AssertionConfig `yaml:"assertions"          json:"assertions,omitempty"`
+	Interval            string            `yaml:"interval"            json:"interval,omitempty"`
+	Timeout             string            `yaml:"timeout"             json:"timeout,omitempty"`
+	FailureThreshold    int               `yaml:"failureThreshold"    json:"failureThreshold,omitempty"`
+	SuccessThreshold    int               `yaml:"successThreshold"    json:"successThreshold,omitempty"`
+	Latency             *LatencyConfig    `yaml:"latency"             json:"latency,omitempty"`
+	DependsOn           []string          `yaml:"dependsOn"           json:"dependsOn,omitempty"`
+}
+
+// ProbeConfig selects the health probe type for a service. When omitted, the
+// service is probed with an HTTP GET against healthUrl (or url).
+//
+// Address overrides the host:port derived from the service URL for tcp, tls,
+// and grpc probes; for dns probes it names the resolver to query.
+//
+// The remaining fields customize HTTP probes. Host overrides the Host header
+// (and the TLS server name unless tls.serverName is set). FollowRedirects
+// defaults to true; RedirectsHealthy counts a 3xx response as healthy.
+type ProbeConfig struct {
+	Type             string                  `yaml:"type"             json:"type"`
+	Address          string                  `yaml:"address"          json:"address"`
+	Query            string                  `yaml:"query"            json:"query"`
+	RecordType       string                  `yaml:"recordType"       json:"recordType"`
+	Expect           []string                `yaml:"expect"           json:"expect"`
+	GRPCService      string                  `yaml:"grpcService"      json:"grpcService"`
+	Method           string                  `yaml:"method"           json:"method,omitempty"`
+	Headers          map[string]SecretConfig `yaml:"headers"          json:"headers,omitempty"`
+	Host             string                  `yaml:"host"             json:"host,omitempty"`
+	Body             string                  `yaml:"body"             json:"-"`
+	Auth             *AuthConfig             `yaml:"auth"             json:"auth,omitempty"`
+	TLS              *ProbeTLSConfig         `yaml:"tls"              json:"tls,omitempty"`
+	FollowRedirects  *bool                   `yaml:"followRedirects"  json:"followRedirects,omitempty"`
+	RedirectsHealthy bool                    `yaml:"redirectsHealthy" json:"redirectsHealthy,omitempty"`
+}
+
+// AuthConfig holds HTTP credentials, a bearer token or basic auth; at most
+// one is set.
+type AuthConfig struct {
+	Bearer *SecretConfig    `yaml:"bearer" json:"bearer,omitempty"`
+	Basic  *BasicAuthConfig `yaml:"basic"  json:"basic,omitempty"`
+}
+
+// BasicAuthConfig holds HTTP basic auth credentials.
+type BasicAuthConfig struct {
+	Username string       `yaml:"username" json:"username"`
+	Password SecretConfig `yaml:"password" json:"password"`
+}
+
+// ProbeTLSConfig configures the TLS client of an HTTP probe. CAFile is a PEM
+// CA trusted in addition to the system roots when verifying the service's
+// certificate; CertFile and KeyFile present a client certificate.
+type ProbeTLSConfig struct {
+	CAFile     string `yaml:"caFile"     json:"caFile,omitempty"`
+	CertFile   string `yaml:"certFile"   json:"certFile,omitempty"`
+	KeyFile    string `yaml:"keyFile"    json:"keyFile,omitempty"`
+	ServerName string `yaml:"serverName" json:"serverName,omitempty"`
+}
+
+// SecretConfig is a secret given inline, by environment variable, or by file.
+// A plain YAML string is shorthand for an inline value. Secrets are read at
+// probe time, so rotated files and variables take effect without a reload.
+type SecretConfig struct {
+	Value string `yaml:"value" json:"-"`
+	Env   string `yaml:"env"   json:"env,omitempty"`
+	File  string `yaml:"file"  json:"file,omitempty"`
+}
+
+// UnmarshalYAML accepts either a plain string or a value/env/file mapping.
+func (s *SecretConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.Value = node.Value
+		return nil
+	}
+	type plain SecretConfig
+	return node.Decode((*plain)(s))
+}
+
+// LatencyConfig turns slow responses into a degraded or unhealthy status.
+// Each threshold is in milliseconds and optional (zero disables it). With P95
+// the thresholds are judged on the 95th percentile of the last Window checks
+// (default 20) instead of the latest response time.
+type LatencyConfig struct {
+	DegradedAboveMs  int64 `yaml:"degradedAboveMs"  json:"degradedAboveMs,omitempty"`
+	UnhealthyAboveMs int64 `yaml:"unhealthyAboveMs" json:"unhealthyAboveMs,omitempty"`
+	P95              bool  `yaml:"p95"              json:"p95,omitempty"`
+	Window           int   `yaml:"window"           json:"window,omitempty"`
+}
+
+// AssertionConfig checks the body of an HTTP probe response whose status code
+// already passed. Exactly one of contains, regex, jsonPath, or maxBodyBytes is
+// set; equals, when present, is the expected value at jsonPath, which may be
+// the empty string. onFailure selects the status
+// applied when the assertion fails: "degraded" or "unhealthy" (default).
+type AssertionConfig struct {
+	Contains     string  `yaml:"contains"     json:"contains,omitempty"`
+	Regex        string  `yaml:"regex"        json:"regex,omitempty"`
+	JSONPath     string  `yaml:"jsonPath"     json:"jsonPath,omitempty"`
+	Equals       *string `yaml:"equals"       json:"equals,omitempty"`
+	MaxBodyBytes int64   `yaml:"maxBodyBytes" json:"maxBodyBytes,omitempty"`
+	OnFailure    string  `yaml:"onFailure"    json:"onFailure,omitempty"`
 }
 
-// GroupConfig provides metadata for a service group.
+// GroupConfig provides metadata for a service group. Interval and Timeout
+// apply to every service in the group that does not set its own.
 type GroupConfig struct {
 	DisplayName string `yaml:"displayName" json:"displayName"`
 	Icon        string `yaml:"icon"        json:"icon"`
 	SortOrder   int    `yaml:"sortOrder"   json:"sortOrder"`
+	Interval    string `yaml:"interval"    json:"interval,omitempty"`
+	Timeout     string `yaml:"timeout"     json:"timeout,omitempty"`
 }
 
-// HealthConfig controls health check behavior.
+// HealthConfig controls health check behavior. Interval overrides the
+// --health-interval flag; Timeout defaults to 10s. FailureThreshold and
+// SuccessThreshold set how many consecutive results change a service's
+// status (default 1). FlapThreshold status changes within FlapWindow
+// (default 10m) mark a service as flapping; zero disables flap detection.
+// MaxConcurrency and MaxPerHost cap simultaneous probes overall and per
+// target host (defaults 32 and 4).
 type HealthConfig struct {
-	Interval string `yaml:"interval" json:"interval"`
-	Timeout  string `yaml:"timeout"  json:"timeout"`
+	Interval         string         `yaml:"interval"         json:"interval"`
+	Timeout          string         `yaml:"timeout"          json:"timeout"`
+	FailureThreshold int            `yaml:"failureThreshold" json:"failureThreshold,omitempty"`
+	SuccessThreshold int            `yaml:"successThreshold" json:"successThreshold,omitempty"`
+	FlapThreshold    int            `yaml:"flapThreshold"    json:"flapThreshold,omitempty"`
+	FlapWindow       string         `yaml:"flapWindow"       json:"flapWindow,omitempty"`
+	MaxConcurrency   int            `yaml:"maxConcurrency"   json:"maxConcurrency,omitempty"`
+	MaxPerHost       int            `yaml:"maxPerHost"       json:"maxPerHost,omitempty"`
+	TLS              TLSCheckConfig `yaml:"tls"              json:"tls"`
+}
+
+// TLSCheckConfig controls certificate checks on probed HTTPS, TLS, and gRPC
+// services. Certificates inside WarningWindow (default 336h, 14 days) of
+// expiry make a service degraded. CAFile adds a trusted CA to the system
+// roots for chain verification; RequireValidChain makes a chain that fails
+// verification unhealthy instead of only recording it.
+type TLSCheckConfig struct {
+	WarningWindow     string `yaml:"warningWindow"     json:"warningWindow,omitempty"`
+	CAFile            string `yaml:"caFile"            json:"caFile,omitempty"`
+	RequireValidChain bool   `yaml:"requireValidChain" json:"requireValidChain,omitempty"`
 }
 
 // HistoryConfig controls health history retention.
@@ -89,9 +314,15 @@ type AdapterConfig struct {
 	AppToken string `yaml:"appToken" json:"appToken"`
 }
 
-// NotificationRule defines per-service routing for notifications.
+// NotificationRule defines per-service routing for notifications. A rule
+// applies to services matching one of the Services glob patterns or, if set,
+// the label Selector; with both set a service must match both. Patterns
+// match "namespace/name", or "cluster/namespace/name" to target one cluster.
+// Clusters, if set, further limits the rule to services from those clusters.
 type NotificationRule struct {
 	Services            []string `yaml:"services"            json:"services"`
+	Selector            string   `yaml:"selector"            json:"selector,omitempty"`
+	Clusters            []string `yaml:"clusters"            json:"clusters,omitempty"`
 	Transitions         []string `yaml:"transitions"         json:"transitions"`
 	Channels            []string `yaml:"channels"            json:"channels"`
 	SuppressionInterval string   `yaml:"suppressionInterval" json:"suppressionInterval"`
@@ -104,4 +335,3 @@ type KeyboardConfig struct {
 	Mod      string            `yaml:"mod"      json:"mod"`
 	Bindings map[string]string `yaml:"bindings" json:"bindings"`
 }
-
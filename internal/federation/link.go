@@ -0,0 +1,312 @@
+package federation
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+const (
+	defaultMinBackoff  = time.Second
+	defaultMaxBackoff  = time.Minute
+	defaultIdleTimeout = 45 * time.Second // three missed keepalives of the remote
+
+	// maxEventSize bounds one SSE line; a state snapshot of a large remote
+	// arrives as a single data line.
+	maxEventSize = 16 << 20
+)
+
+// SiteStore is the state store a link merges its site's services into.
+// Satisfied by *state.Store.
+type SiteStore interface {
+	Get(namespace, name string) (state.Service, bool)
+	ReplaceSite(site string, services []state.Service) int
+	AddOrUpdate(svc state.Service)
+	Remove(namespace, name string)
+	MarkSiteStale(site string) int
+}
+
+// Option configures a Link.
+type Option func(*Link)
+
+// WithBackoff sets the delay before the first reconnect and the limit it
+// doubles up to.
+func WithBackoff(min, max time.Duration) Option {
+	return func(l *Link) {
+		l.minBackoff = min
+		l.maxBackoff = max
+	}
+}
+
+// WithIdleTimeout sets how long a stream may go without any data, including
+// keepalives, before it is treated as dropped.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(l *Link) {
+		l.idleTimeout = d
+	}
+}
+
+// Link subscribes to the event stream of a remote Command Center instance
+// and mirrors its services into the store as one site.
+type Link struct {
+	site        string
+	url         string
+	client      *http.Client
+	store       SiteStore
+	logger      *slog.Logger
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	idleTimeout time.Duration
+}
+
+// NewLink creates a link for the remote at baseURL whose services are
+// merged into store as site. tlsConfig holds the client certificate for the
+// remote's mTLS; nil uses the default transport.
+func NewLink(site, baseURL string, tlsConfig *tls.Config, store SiteStore, logger *slog.Logger, opts ...Option) (*Link, error) {
+	eventsURL, err := url.JoinPath(baseURL, "api", "events")
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote URL: %w", err)
+	}
+	client := http.DefaultClient
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client = &http.Client{Transport: transport}
+	}
+	l := &Link{
+		site:        site,
+		url:         eventsURL,
+		client:      client,
+		store:       store,
+		logger:      logger,
+		minBackoff:  defaultMinBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		idleTimeout: defaultIdleTimeout,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l, nil
+}
+
+// LoadTLSConfig builds the client TLS configuration for a remote: the key
+// pair in certFile and keyFile, if set, is presented as the client
+// certificate, and the CA in caFile, if set, verifies the remote's server
+// certificate in place of the system roots.
+func LoadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS13}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA cert")
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// Run streams the remote's events until ctx is cancelled. Each connection
+// starts from the remote's state snapshot, which replaces the site's
+// services. When the stream drops, the site's services are marked stale and
+// the link reconnects after a backoff that doubles from the minimum up to
+// the maximum, starting over once a stream has delivered its snapshot.
+func (l *Link) Run(ctx context.Context) {
+	backoff := l.minBackoff
+	for {
+		synced, err := l.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if synced {
+			backoff = l.minBackoff
+		}
+		stale := l.store.MarkSiteStale(l.site)
+		l.logger.Warn("federation link down",
+			"site", l.site,
+			"error", err,
+			"staleServices", stale,
+			"retryIn", backoff,
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, l.maxBackoff)
+	}
+}
+
+// stream reads one connection's events. It reports whether the state
+// snapshot arrived, and why the stream ended.
+func (l *Link) stream(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	// A connection that silently died is noticed by the remote's keepalives
+	// no longer arriving.
+	var idled atomic.Bool
+	idle := time.AfterFunc(l.idleTimeout, func() {
+		idled.Store(true)
+		cancel()
+	})
+	defer idle.Stop()
+
+	var (
+		synced bool
+		event  string
+		data   []string
+	)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		idle.Reset(l.idleTimeout)
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event != "" {
+				synced = l.handle(event, strings.Join(data, "\n"), synced)
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comment, e.g. a keepalive.
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+	}
+	if idled.Load() {
+		return synced, errors.New("no data within idle timeout")
+	}
+	if err := scanner.Err(); err != nil {
+		return synced, err
+	}
+	return synced, errors.New("stream closed by remote")
+}
+
+// removedPayload identifies a service in a "removed" event.
+type removedPayload struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	Site      string `json:"site"`
+}
+
+// handle applies one event and returns whether the link is synced. Service
+// events before the state snapshot are ignored; the snapshot covers them.
+func (l *Link) handle(event, data string, synced bool) bool {
+	switch event {
+	case "state":
+		var payload struct {
+			Services []state.Service `json:"services"`
+		}
+		if err := json.Unmarshal([]byte(data), &payload); err != nil {
+			l.logger.Warn("federation: invalid state event", "site", l.site, "error", err)
+			return synced
+		}
+		for i := range payload.Services {
+			l.localize(&payload.Services[i])
+		}
+		removed := l.store.ReplaceSite(l.site, payload.Services)
+		l.logger.Info("federation link synced",
+			"site", l.site,
+			"services", len(payload.Services),
+			"removed", removed,
+		)
+		return true
+	case "discovered", "update":
+		if !synced {
+			return false
+		}
+		var svc state.Service
+		if err := json.Unmarshal([]byte(data), &svc); err != nil {
+			l.logger.Warn("federation: invalid service event", "site", l.site, "event", event, "error", err)
+			return synced
+		}
+		l.localize(&svc)
+		if existing, ok := l.store.Get(svc.ScopedNamespace(), svc.Name); ok {
+			keepCheckConfig(&svc, existing)
+		}
+		l.store.AddOrUpdate(svc)
+	case "removed":
+		if !synced {
+			return false
+		}
+		var p removedPayload
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			l.logger.Warn("federation: invalid removed event", "site", l.site, "error", err)
+			return synced
+		}
+		svc := state.Service{Name: p.Name, Namespace: p.Namespace, Cluster: p.Cluster, Site: p.Site}
+		l.localize(&svc)
+		l.store.Remove(svc.ScopedNamespace(), svc.Name)
+	}
+	return synced
+}
+
+// keepCheckConfig copies onto svc, from a service event, the fields of the
+// existing service that events do not carry: the check configuration the
+// state snapshot gave.
+func keepCheckConfig(svc *state.Service, existing state.Service) {
+	svc.OriginalDisplayName = existing.OriginalDisplayName
+	svc.HealthURL = existing.HealthURL
+	svc.ExpectedStatusCodes = existing.ExpectedStatusCodes
+	svc.Probe = existing.Probe
+	svc.Assertions = existing.Assertions
+}
+
+// localize places a service reported by the remote in this link's site. A
+// service the remote itself federates keeps its site beneath this one.
+func (l *Link) localize(svc *state.Service) {
+	if svc.Site == "" {
+		svc.Site = l.site
+	} else {
+		svc.Site = l.site + "/" + svc.Site
+	}
+}
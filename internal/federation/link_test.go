@@ -0,0 +1,256 @@
+package federation
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/certs"
+	"github.com/rathix/command-center/internal/state"
+)
+
+// hold keeps a stream open after its events until the client goes away.
+const hold = ":hold"
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// remote serves an event stream per connection: conns[i] for the i-th
+// connection, after which the stream is closed unless it ends with hold.
+func remote(t *testing.T, conns ...[]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var n atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/events" {
+			http.NotFound(w, r)
+			return
+		}
+		i := int(n.Add(1)) - 1
+		if i >= len(conns) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, evt := range conns[i] {
+			if evt == hold {
+				<-r.Context().Done()
+				return
+			}
+			fmt.Fprint(w, evt)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	return srv, &n
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLink_MirrorsRemoteAndResyncs(t *testing.T) {
+	srv, conns := remote(t,
+		[]string{
+			`event: state` + "\n" + `data: {"services":[{"name":"api","namespace":"default","status":"healthy","compositeStatus":"healthy"},{"name":"old","namespace":"default","status":"healthy"}]}` + "\n\n",
+			":keepalive\n\n",
+			`event: update` + "\n" + `data: {"name":"api","namespace":"default","status":"unhealthy","compositeStatus":"unhealthy"}` + "\n\n",
+			`event: discovered` + "\n" + `data: {"name":"db","namespace":"custom","cluster":"lab","status":"unknown"}` + "\n\n",
+			`event: removed` + "\n" + `data: {"name":"old","namespace":"default"}` + "\n\n",
+		},
+		[]string{
+			`event: state` + "\n" + `data: {"services":[{"name":"api","namespace":"default","status":"healthy"}]}` + "\n\n",
+			hold,
+		},
+	)
+	srv.Start()
+	defer srv.Close()
+
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "api", Namespace: "default", Status: state.StatusHealthy})
+	events := store.Subscribe()
+	link, err := NewLink("east", srv.URL, nil, store, testLogger(), WithBackoff(10*time.Millisecond, 20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go link.Run(ctx)
+
+	// The first stream ends after its events: the site goes stale until the
+	// second connection resyncs it from its snapshot.
+	var sawStale bool
+	waitFor(t, func() bool {
+		for {
+			select {
+			case evt := <-events:
+				if evt.Type == state.EventUpdated && evt.Service.Site == "east" && evt.Service.Stale {
+					sawStale = true
+				}
+			default:
+				return conns.Load() >= 2 && sawStale
+			}
+		}
+	})
+	waitFor(t, func() bool {
+		svc, ok := store.Get("east/default", "api")
+		return ok && svc.Status == state.StatusHealthy && !svc.Stale
+	})
+
+	if _, ok := store.Get("east/default", "old"); ok {
+		t.Error("removed service should be gone")
+	}
+	if _, ok := store.Get("east/lab/custom", "db"); ok {
+		t.Error("service missing from the resync snapshot should be gone")
+	}
+	if svc, ok := store.Get("default", "api"); !ok || svc.Site != "" {
+		t.Errorf("local service should be untouched, got %+v", svc)
+	}
+}
+
+func TestLink_UpdateKeepsSnapshotCheckConfig(t *testing.T) {
+	store := state.NewStore()
+	link, err := NewLink("east", "https://east.example.com", nil, store, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link.handle("state", `{"services":[{"name":"api","namespace":"default","status":"healthy",`+
+		`"description":"Public API","healthUrl":"https://api.example.com/healthz","expectedStatusCodes":[204],`+
+		`"probe":{"type":"tcp"},"assertions":[{"jsonPath":"status","equals":"ok"}]}]}`, false)
+	link.handle("update", `{"name":"api","namespace":"default","description":"Public API","status":"unhealthy","compositeStatus":"unhealthy"}`, true)
+
+	svc, ok := store.Get("east/default", "api")
+	if !ok {
+		t.Fatal("expected the federated service")
+	}
+	if svc.Status != state.StatusUnhealthy {
+		t.Errorf("Status = %q, want the update applied", svc.Status)
+	}
+	if svc.Description != "Public API" || svc.HealthURL != "https://api.example.com/healthz" ||
+		len(svc.ExpectedStatusCodes) != 1 || svc.Probe == nil || svc.Probe.Type != state.ProbeTCP || len(svc.Assertions) != 1 {
+		t.Errorf("service = %+v, want the snapshot's check configuration kept", svc)
+	}
+}
+
+func TestLink_MarksSiteStaleWhenUnreachable(t *testing.T) {
+	srv, _ := remote(t, []string{
+		`event: state` + "\n" + `data: {"services":[{"name":"api","namespace":"default","status":"healthy"}]}` + "\n\n",
+	})
+	srv.Start()
+
+	store := state.NewStore()
+	link, err := NewLink("east", srv.URL, nil, store, testLogger(),
+		WithBackoff(time.Hour, time.Hour), WithIdleTimeout(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go link.Run(ctx)
+
+	waitFor(t, func() bool {
+		svc, ok := store.Get("east/default", "api")
+		return ok && svc.Stale
+	})
+	srv.Close()
+	if svc, _ := store.Get("east/default", "api"); svc.Status != state.StatusHealthy {
+		t.Errorf("stale service should keep its last status, got %q", svc.Status)
+	}
+}
+
+func TestLink_IdleTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: state\ndata: {\"services\":[{\"name\":\"api\",\"namespace\":\"default\"}]}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	store := state.NewStore()
+	link, err := NewLink("east", srv.URL, nil, store, testLogger(), WithIdleTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	synced, err := link.stream(context.Background())
+	if !synced || err == nil || err.Error() != "no data within idle timeout" {
+		t.Errorf("stream() = %v, %v; want synced and an idle timeout", synced, err)
+	}
+}
+
+func TestLink_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := certs.GenerateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := certs.GenerateServerCert(dir, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := certs.GenerateClientCert(dir, ca)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTLS, err := certs.NewTLSConfig(ca.CACertPath, server.ServerCertPath, server.ServerKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverTLS.ClientAuth = tls.RequireAndVerifyClientCert
+
+	srv, _ := remote(t, []string{
+		`event: state` + "\n" + `data: {"services":[{"name":"api","namespace":"default"}]}` + "\n\n",
+	})
+	srv.TLS = serverTLS
+	srv.StartTLS()
+	defer srv.Close()
+
+	if _, err := LoadTLSConfig(ca.CACertPath, client.ClientCertPath, ""); err == nil {
+		t.Error("expected an error for a client certificate without its key")
+	}
+	clientTLS, err := LoadTLSConfig(ca.CACertPath, client.ClientCertPath, client.ClientKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := state.NewStore()
+	link, err := NewLink("east", srv.URL, clientTLS, store, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced, err := link.stream(context.Background()); !synced {
+		t.Fatalf("stream() did not sync: %v", err)
+	}
+	if _, ok := store.Get("east/default", "api"); !ok {
+		t.Error("expected the remote's service over mTLS")
+	}
+
+	noCert, err := LoadTLSConfig(ca.CACertPath, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, _ = NewLink("east", srv.URL, noCert, state.NewStore(), testLogger())
+	if synced, err := link.stream(context.Background()); synced || err == nil {
+		t.Error("expected the remote to refuse a client without a certificate")
+	}
+}
@@ -204,8 +204,8 @@ func (w *FluxWatcher) handleObject(obj *unstructured.Unstructured, sourceType st
 
 	// Also try to match services in other namespaces by iterating all services.
 	for _, svc := range w.updater.All() {
-		if svc.Name == serviceName && svc.Namespace != result.Namespace {
-			w.updater.Update(svc.Namespace, svc.Name, func(s *state.Service) {
+		if svc.Name == serviceName && svc.ScopedNamespace() != result.Namespace {
+			w.updater.Update(svc.ScopedNamespace(), svc.Name, func(s *state.Service) {
 				s.GitOpsStatus = &state.GitOpsStatus{
 					ReconciliationState: result.Result.State,
 					LastTransitionTime:  result.TransitionTime,
@@ -0,0 +1,190 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// defaultAssertionBodyLimit caps how much of a response body is read for
+// contains, regex, and jsonPath assertions.
+const defaultAssertionBodyLimit = 1 << 20
+
+// regexCache holds compiled assertion patterns keyed by source.
+var regexCache sync.Map
+
+// assertionBodyLimit returns how many body bytes must be captured to evaluate
+// assertions, or 0 when there are none. A maxBodyBytes assertion needs one
+// byte beyond its limit to detect an oversized body.
+func assertionBodyLimit(assertions []state.BodyAssertion) int64 {
+	if len(assertions) == 0 {
+		return 0
+	}
+	limit := int64(defaultAssertionBodyLimit)
+	for _, a := range assertions {
+		if a.MaxBodyBytes > 0 && a.MaxBodyBytes+1 > limit {
+			limit = a.MaxBodyBytes + 1
+		}
+	}
+	return limit
+}
+
+// evaluateAssertions runs every assertion against body. When any fail, it
+// returns the most severe configured failure status and the message of the
+// first assertion that failed with that status.
+func evaluateAssertions(assertions []state.BodyAssertion, body []byte) (state.HealthStatus, string, bool) {
+	var (
+		status  state.HealthStatus
+		snippet string
+		failed  bool
+	)
+	// A body that filled the read limit may have been cut off.
+	truncated := int64(len(body)) >= assertionBodyLimit(assertions)
+	for _, a := range assertions {
+		msg, ok := checkAssertion(a, body, truncated)
+		if ok {
+			continue
+		}
+		onFailure := a.OnFailure
+		if onFailure != state.StatusDegraded {
+			onFailure = state.StatusUnhealthy
+		}
+		if !failed || (status == state.StatusDegraded && onFailure == state.StatusUnhealthy) {
+			status, snippet, failed = onFailure, truncateSnippet("assertion failed: "+msg), true
+		}
+	}
+	return status, snippet, failed
+}
+
+// checkAssertion evaluates a single assertion, returning a failure message
+// and false when it does not hold. truncated reports that body is the start
+// of a longer response.
+func checkAssertion(a state.BodyAssertion, body []byte, truncated bool) (string, bool) {
+	switch {
+	case a.MaxBodyBytes > 0:
+		if int64(len(body)) > a.MaxBodyBytes {
+			return fmt.Sprintf("body exceeds %d bytes", a.MaxBodyBytes), false
+		}
+	case a.Contains != "":
+		if !bytes.Contains(body, []byte(a.Contains)) {
+			return fmt.Sprintf("body does not contain %q", a.Contains), false
+		}
+	case a.Regex != "":
+		re, err := compileAssertionRegex(a.Regex)
+		if err != nil {
+			return fmt.Sprintf("invalid regex %q", a.Regex), false
+		}
+		if !re.Match(body) {
+			return fmt.Sprintf("body does not match /%s/", a.Regex), false
+		}
+	case a.JSONPath != "":
+		got, err := lookupJSONPath(body, a.JSONPath, truncated)
+		if err != nil {
+			return fmt.Sprintf("%s: %v", a.JSONPath, err), false
+		}
+		if a.Equals != nil && got != *a.Equals {
+			return fmt.Sprintf("%s = %q, want %q", a.JSONPath, got, *a.Equals), false
+		}
+	}
+	return "", true
+}
+
+func compileAssertionRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// lookupJSONPath resolves a dotted path such as "$.checks.db.status" or
+// "items[0].name" in a JSON document and returns the value as a string.
+// Strings are returned unquoted; other scalars use their JSON text and
+// objects or arrays their compact JSON encoding. A truncated body that does
+// not parse is reported as cut off rather than as invalid JSON.
+func lookupJSONPath(body []byte, path string, truncated bool) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		if truncated {
+			return "", fmt.Errorf("body truncated at %d bytes", len(body))
+		}
+		return "", fmt.Errorf("body is not valid JSON")
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	cur := doc
+	for _, seg := range splitJSONPath(path) {
+		if idx, err := strconv.Atoi(seg); err == nil {
+			if arr, ok := cur.([]any); ok {
+				if idx < 0 || idx >= len(arr) {
+					return "", fmt.Errorf("index %d out of range", idx)
+				}
+				cur = arr[idx]
+				continue
+			}
+		}
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("no field %q", seg)
+		}
+		v, ok := obj[seg]
+		if !ok {
+			return "", fmt.Errorf("no field %q", seg)
+		}
+		cur = v
+	}
+
+	switch v := cur.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "null", nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// splitJSONPath splits "a.b[0].c" into ["a", "b", "0", "c"].
+func splitJSONPath(path string) []string {
+	var segs []string
+	for _, part := range strings.Split(path, ".") {
+		for part != "" {
+			open := strings.IndexByte(part, '[')
+			if open < 0 {
+				segs = append(segs, part)
+				break
+			}
+			if open > 0 {
+				segs = append(segs, part[:open])
+			}
+			end := strings.IndexByte(part[open:], ']')
+			if end < 0 {
+				segs = append(segs, part[open+1:])
+				break
+			}
+			segs = append(segs, part[open+1:open+end])
+			part = part[open+end+1:]
+		}
+	}
+	return segs
+}
@@ -0,0 +1,219 @@
+package health
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+func TestLookupJSONPath(t *testing.T) {
+	body := []byte(`{"status":"ok","checks":{"db":{"up":true,"latency":12.5}},"items":[{"name":"a"},{"name":"b"}],"none":null}`)
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"status", "ok", false},
+		{"$.status", "ok", false},
+		{"checks.db.up", "true", false},
+		{"checks.db.latency", "12.5", false},
+		{"items[1].name", "b", false},
+		{"none", "null", false},
+		{"checks.db", `{"latency":12.5,"up":true}`, false},
+		{"missing", "", true},
+		{"items[5].name", "", true},
+		{"status.inner", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := lookupJSONPath(body, tt.path, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("lookupJSONPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("lookupJSONPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := lookupJSONPath([]byte("<html>"), "status", false); err == nil || err.Error() != "body is not valid JSON" {
+		t.Errorf("error = %v, want invalid JSON for non-JSON body", err)
+	}
+	if _, err := lookupJSONPath([]byte(`{"status":"o`), "status", true); err == nil || err.Error() != "body truncated at 12 bytes" {
+		t.Errorf("error = %v, want truncation reported for a cut-off body", err)
+	}
+}
+
+func TestEvaluateAssertions(t *testing.T) {
+	ok, empty := "ok", ""
+	tests := []struct {
+		name       string
+		assertions []state.BodyAssertion
+		body       string
+		wantFailed bool
+		wantStatus state.HealthStatus
+		wantSnip   string
+	}{
+		{
+			name:       "contains passes",
+			assertions: []state.BodyAssertion{{Contains: "ready", OnFailure: state.StatusUnhealthy}},
+			body:       "system ready",
+		},
+		{
+			name:       "contains fails",
+			assertions: []state.BodyAssertion{{Contains: "ready", OnFailure: state.StatusUnhealthy}},
+			body:       "<html>Bad Gateway</html>",
+			wantFailed: true,
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   `assertion failed: body does not contain "ready"`,
+		},
+		{
+			name:       "regex fails as degraded",
+			assertions: []state.BodyAssertion{{Regex: `version":\s*"2\.`, OnFailure: state.StatusDegraded}},
+			body:       `{"version": "1.9"}`,
+			wantFailed: true,
+			wantStatus: state.StatusDegraded,
+			wantSnip:   `assertion failed: body does not match /version":\s*"2\./`,
+		},
+		{
+			name:       "json path equality fails",
+			assertions: []state.BodyAssertion{{JSONPath: "status", Equals: &ok, OnFailure: state.StatusDegraded}},
+			body:       `{"status":"degraded"}`,
+			wantFailed: true,
+			wantStatus: state.StatusDegraded,
+			wantSnip:   `assertion failed: status = "degraded", want "ok"`,
+		},
+		{
+			name:       "json path equals empty string",
+			assertions: []state.BodyAssertion{{JSONPath: "error", Equals: &empty, OnFailure: state.StatusUnhealthy}},
+			body:       `{"error":"disk full"}`,
+			wantFailed: true,
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   `assertion failed: error = "disk full", want ""`,
+		},
+		{
+			name:       "json path equals empty string passes",
+			assertions: []state.BodyAssertion{{JSONPath: "error", Equals: &empty, OnFailure: state.StatusUnhealthy}},
+			body:       `{"error":""}`,
+		},
+		{
+			name:       "json path on truncated body",
+			assertions: []state.BodyAssertion{{JSONPath: "status", OnFailure: state.StatusUnhealthy}},
+			body:       (`{"status":"ok","padding":"` + strings.Repeat("x", defaultAssertionBodyLimit))[:defaultAssertionBodyLimit],
+			wantFailed: true,
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   "assertion failed: status: body truncated at 1048576 bytes",
+		},
+		{
+			name:       "max body size exceeded",
+			assertions: []state.BodyAssertion{{MaxBodyBytes: 4, OnFailure: state.StatusUnhealthy}},
+			body:       "too large",
+			wantFailed: true,
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   "assertion failed: body exceeds 4 bytes",
+		},
+		{
+			name: "unhealthy outranks earlier degraded failure",
+			assertions: []state.BodyAssertion{
+				{JSONPath: "status", Equals: &ok, OnFailure: state.StatusDegraded},
+				{Contains: "db", OnFailure: state.StatusUnhealthy},
+			},
+			body:       `{"status":"warn"}`,
+			wantFailed: true,
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   `assertion failed: body does not contain "db"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, snippet, failed := evaluateAssertions(tt.assertions, []byte(tt.body))
+			if failed != tt.wantFailed {
+				t.Fatalf("failed = %v, want %v (snippet %q)", failed, tt.wantFailed, snippet)
+			}
+			if !failed {
+				return
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			if snippet != tt.wantSnip {
+				t.Errorf("snippet = %q, want %q", snippet, tt.wantSnip)
+			}
+		})
+	}
+}
+
+func TestAssertionBodyLimit(t *testing.T) {
+	if got := assertionBodyLimit(nil); got != 0 {
+		t.Errorf("expected 0 without assertions, got %d", got)
+	}
+	if got := assertionBodyLimit([]state.BodyAssertion{{Contains: "x"}}); got != defaultAssertionBodyLimit {
+		t.Errorf("expected default limit, got %d", got)
+	}
+	big := int64(4 << 20)
+	if got := assertionBodyLimit([]state.BodyAssertion{{MaxBodyBytes: big}}); got != big+1 {
+		t.Errorf("expected %d, got %d", big+1, got)
+	}
+}
+
+func TestCheckService_AssertionFailureDegradesHealthyResponse(t *testing.T) {
+	ok := "ok"
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "nextcloud", Namespace: "apps", URL: "https://cloud.example.com",
+		Status:     state.StatusUnknown,
+		Assertions: []state.BodyAssertion{{JSONPath: "status", Equals: &ok, OnFailure: state.StatusDegraded}},
+	})
+
+	client := &mockHTTPProber{
+		responses: map[string]mockResponse{
+			"https://cloud.example.com": {statusCode: 200, body: `{"status":"degraded"}`},
+		},
+	}
+
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	checkEach(checker)
+
+	svc, _ := store.Get("apps", "nextcloud")
+	if svc.Status != state.StatusDegraded {
+		t.Errorf("expected status %q, got %q", state.StatusDegraded, svc.Status)
+	}
+	if svc.CompositeStatus != state.StatusDegraded {
+		t.Errorf("expected composite status %q, got %q", state.StatusDegraded, svc.CompositeStatus)
+	}
+	if svc.HTTPCode == nil || *svc.HTTPCode != 200 {
+		t.Errorf("expected HTTPCode 200, got %v", svc.HTTPCode)
+	}
+	if svc.ErrorSnippet == nil || !strings.Contains(*svc.ErrorSnippet, `want "ok"`) {
+		t.Errorf("expected assertion snippet, got %v", svc.ErrorSnippet)
+	}
+}
+
+func TestCheckService_AssertionsSkippedWhenStatusCodeFails(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "app", Namespace: "apps", URL: "https://app.example.com",
+		Status:     state.StatusUnknown,
+		Assertions: []state.BodyAssertion{{Contains: "ok", OnFailure: state.StatusDegraded}},
+	})
+
+	client := &mockHTTPProber{
+		responses: map[string]mockResponse{
+			"https://app.example.com": {statusCode: 503, body: "Service Unavailable"},
+		},
+	}
+
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	checkEach(checker)
+
+	svc, _ := store.Get("apps", "app")
+	if svc.Status != state.StatusUnhealthy {
+		t.Errorf("expected status %q, got %q", state.StatusUnhealthy, svc.Status)
+	}
+	if svc.ErrorSnippet == nil || *svc.ErrorSnippet != "Service Unavailable" {
+		t.Errorf("expected body snippet, got %v", svc.ErrorSnippet)
+	}
+}
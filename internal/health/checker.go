@@ -1,9 +1,12 @@
 package health
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -35,18 +38,53 @@ type EndpointReader interface {
 	GetEndpointReadiness(namespace, name string) *EndpointReadiness
 }
 
-// Checker performs periodic HTTP health checks against discovered services.
+// Metrics receives check measurements for export, e.g. to Prometheus.
+// ObserveCheck is called with each probe's response time, keyed by the
+// service's scoped namespace and name; ObserveCycle with
+// the time a scheduler pass took to finish every check it queued.
+type Metrics interface {
+	ObserveCheck(namespace, name string, responseTime time.Duration)
+	ObserveCycle(d time.Duration)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveCheck(string, string, time.Duration) {}
+func (noopMetrics) ObserveCycle(time.Duration)                 {}
+
+// Checker performs periodic health checks against discovered services.
 type Checker struct {
 	reader         StateReader
 	writer         StateWriter
 	client         HTTPProber
-	interval       time.Duration
 	historyWriter  history.HistoryWriter
 	logger         *slog.Logger
 	endpointReader EndpointReader
+	dialer         Dialer
+	resolverFor    func(server string) DNSResolver
+	grpcClient     HTTPProber
+
+	mu             sync.Mutex
+	schedule       Schedule
+	damping        Damping
+	tlsPolicy      TLSPolicy
+	metrics        Metrics
+	clients        map[string]cachedClient // dedicated probe clients by configuration
+	certPools      map[string]cachedPool   // per-service CA pools by file
+	latency        map[string][]int64      // recent response times per service, for p95 SLOs
+	limiter        *limiter
+	lastStart      map[string]time.Time
+	inflight       map[string]chan struct{} // closed when the running check finishes
+	overrun        map[string]struct{}      // in-flight services already reported as overrunning
+	overruns       uint64
+	lastOverrunLog time.Time
+	flaps          map[string][]time.Time // recent status changes per service
+	wake           chan struct{}
 }
 
-// NewChecker creates a new health checker. If logger is nil, a no-op logger is used.
+// NewChecker creates a new health checker that checks every service at the
+// given interval until SetSchedule says otherwise. If logger is nil, a no-op
+// logger is used.
 func NewChecker(reader StateReader, writer StateWriter, client HTTPProber, interval time.Duration, historyWriter history.HistoryWriter, logger *slog.Logger) *Checker {
 	if logger == nil {
 		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -58,9 +96,24 @@ func NewChecker(reader StateReader, writer StateWriter, client HTTPProber, inter
 		reader:        reader,
 		writer:        writer,
 		client:        client,
-		interval:      interval,
 		historyWriter: historyWriter,
 		logger:        logger,
+		dialer:        &net.Dialer{},
+		resolverFor:   newResolver,
+		grpcClient:    newGRPCClient(),
+		metrics:       noopMetrics{},
+		schedule: Schedule{
+			Default: Timing{Interval: interval, Timeout: defaultProbeTimeout},
+		},
+		limiter:   newLimiter(0, 0),
+		lastStart: make(map[string]time.Time),
+		inflight:  make(map[string]chan struct{}),
+		overrun:   make(map[string]struct{}),
+		flaps:     make(map[string][]time.Time),
+		clients:   make(map[string]cachedClient),
+		certPools: make(map[string]cachedPool),
+		latency:   make(map[string][]int64),
+		wake:      make(chan struct{}, 1),
 	}
 }
 
@@ -69,83 +122,110 @@ func (c *Checker) SetEndpointReader(er EndpointReader) {
 	c.endpointReader = er
 }
 
-// Run starts the health check loop. It performs an immediate check on start,
-// then checks at the configured interval. It returns when ctx is cancelled.
+// SetMetrics sets the receiver of check measurements.
+func (c *Checker) SetMetrics(m Metrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = m
+}
+
+func (c *Checker) currentMetrics() Metrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metrics
+}
+
+// Run starts the health check loop. Every service is checked immediately on
+// start (or on discovery), then again whenever its scheduled interval has
+// elapsed. It returns when ctx is cancelled and in-flight checks have finished.
 func (c *Checker) Run(ctx context.Context) {
-	c.checkAll(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
 
-	ticker := time.NewTicker(c.interval)
+	c.runDue(ctx, &wg)
+
+	ticker := time.NewTicker(scheduleResolution)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-c.wake:
+			c.runDue(ctx, &wg)
 		case <-ticker.C:
-			c.checkAll(ctx)
+			c.runDue(ctx, &wg)
 		}
 	}
 }
 
-// checkAll performs a health check cycle across all discovered services.
-func (c *Checker) checkAll(ctx context.Context) {
-	services := c.reader.All()
-	if len(services) == 0 {
-		return
+// checkService probes a single service, applies status-code and body
+// assertions, fuses the result with K8s readiness, and writes it to the store.
+func (c *Checker) checkService(ctx context.Context, s state.Service) {
+	probeCtx, cancel := context.WithTimeout(ctx, c.currentSchedule().timeoutFor(s))
+	defer cancel()
+
+	// Perform the probe selected by the service's probe type
+	result := c.runProbe(probeCtx, s)
+	c.currentMetrics().ObserveCheck(s.ScopedNamespace(), s.Name, time.Duration(result.responseTimeMs)*time.Millisecond)
+	responded := result.httpCode != nil || result.status == state.StatusHealthy
+
+	// Override status classification if ExpectedStatusCodes is set
+	if len(s.ExpectedStatusCodes) > 0 && result.httpCode != nil {
+		if containsInt(s.ExpectedStatusCodes, *result.httpCode) {
+			result.status = state.StatusHealthy
+			result.errorSnippet = nil
+		}
 	}
 
-	start := time.Now()
-
-	var wg sync.WaitGroup
-	wg.Add(len(services))
-	for _, svc := range services {
-		go func(s state.Service) {
-			defer wg.Done()
-
-			// Determine probe URL: HealthURL > URL
-			probeURL := s.URL
-			if s.HealthURL != "" {
-				probeURL = s.HealthURL
-			}
+	// Body assertions only run once the status code has passed
+	if result.status == state.StatusHealthy && len(s.Assertions) > 0 && result.body != nil {
+		if status, snippet, failed := evaluateAssertions(s.Assertions, result.body); failed {
+			result.status = status
+			result.errorSnippet = &snippet
+		}
+	}
 
-			// Perform the probe
-			result := c.probeService(ctx, probeURL)
+	// Certificate expiry and chain checks apply to any probe that used TLS
+	if result.tls != nil {
+		policy := c.currentTLSPolicy()
+		roots, err := c.certPoolFor(s.Probe, policy)
+		if err != nil {
+			c.logger.Warn("probe CA not loaded, using default roots", "service", s.Name, "namespace", s.Namespace, "error", err)
+			roots = policy.Roots
+		}
+		serverName := probeServerName(s.Probe)
+		if serverName == "" {
+			serverName = probeHost(s)
+		}
+		now := time.Now()
+		result.tlsCert = inspectCertificate(result.tls, serverName, roots, now)
+		applyCertPolicy(&result, policy, now)
+	}
 
-			// Override status classification if ExpectedStatusCodes is set
-			if len(s.ExpectedStatusCodes) > 0 && result.httpCode != nil {
-				if containsInt(s.ExpectedStatusCodes, *result.httpCode) {
-					result.status = state.StatusHealthy
-					result.errorSnippet = nil
-				}
-			}
+	// Composite health fusion: merge HTTP probe with K8s readiness
+	if c.endpointReader != nil {
+		er := c.endpointReader.GetEndpointReadiness(s.ScopedNamespace(), s.Name)
+		composite := CompositeHealth(result.status, result.httpCode, er)
+		result.status = composite.Status
+		result.compositeStatus = composite.Status
+		result.authGuarded = composite.AuthGuarded
+	} else {
+		result.compositeStatus = result.status
+	}
 
-			// Composite health fusion: merge HTTP probe with K8s readiness
-			if c.endpointReader != nil {
-				er := c.endpointReader.GetEndpointReadiness(s.Namespace, s.Name)
-				composite := CompositeHealth(result.status, result.httpCode, er)
-				result.status = composite.Status
-				result.compositeStatus = composite.Status
-				result.authGuarded = composite.AuthGuarded
-			} else {
-				result.compositeStatus = result.status
-			}
+	// Latency thresholds judge the fused status, so a slow service with ready
+	// endpoints still turns degraded or unhealthy
+	c.applyLatencySLO(&result, s, responded)
 
-			var transition *history.TransitionRecord
-			// Atomically update only health fields
-			c.writer.Update(s.Namespace, s.Name, func(svc *state.Service) {
-				transition = c.applyResult(svc, result)
-			})
-			if transition != nil {
-				c.recordTransition(*transition)
-			}
-		}(svc)
+	var transition *history.TransitionRecord
+	// Atomically update only health fields
+	c.writer.Update(s.ScopedNamespace(), s.Name, func(svc *state.Service) {
+		transition = c.applyResult(svc, result)
+	})
+	if transition != nil {
+		c.recordTransition(*transition)
 	}
-	wg.Wait()
-
-	c.logger.Info("health check cycle complete",
-		"services", len(services),
-		"durationMs", time.Since(start).Milliseconds(),
-	)
 }
 
 const maxSnippetLen = 256
@@ -157,11 +237,40 @@ type probeResult struct {
 	responseTimeMs  int64
 	errorSnippet    *string
 	authGuarded     bool
+	body            []byte // captured response body, only when assertions need it
+	tls             *tls.ConnectionState
+	tlsCert         *state.TLSCertInfo
+	latencyBreach   string // e.g. "p95=9200ms" when over a latency threshold
 }
 
 // probeService performs a single HTTP GET health check against a service URL.
 func (c *Checker) probeService(ctx context.Context, url string) probeResult {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	return c.probeHTTP(ctx, url, nil, 0)
+}
+
+// probeHTTP performs a single HTTP health check, customized by spec (method,
+// headers, credentials, TLS client settings, and redirect handling) when set.
+// When bodyLimit is positive, up to bodyLimit bytes of the response body are
+// captured for assertion evaluation.
+func (c *Checker) probeHTTP(ctx context.Context, url string, spec *state.ProbeSpec, bodyLimit int64) probeResult {
+	method := http.MethodGet
+	var reqBody io.Reader
+	if spec != nil {
+		if spec.Method != "" {
+			method = spec.Method
+		}
+		if spec.Body != "" {
+			reqBody = strings.NewReader(spec.Body)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err == nil {
+		err = applyProbeRequest(req, spec)
+	}
+	var client HTTPProber
+	if err == nil {
+		client, err = c.httpClientFor(spec)
+	}
 	if err != nil {
 		return probeResult{
 			status:          state.StatusUnhealthy,
@@ -171,7 +280,7 @@ func (c *Checker) probeService(ctx context.Context, url string) probeResult {
 	}
 
 	start := time.Now()
-	resp, err := c.client.Do(req)
+	resp, err := client.Do(req)
 	responseTimeMs := time.Since(start).Milliseconds()
 
 	if err != nil {
@@ -187,10 +296,36 @@ func (c *Checker) probeService(ctx context.Context, url string) probeResult {
 
 	code := resp.StatusCode
 	newStatus := classifyStatus(code)
+	if spec != nil && spec.RedirectsHealthy && code >= 300 && code <= 399 {
+		newStatus = state.StatusHealthy
+	}
+
+	var body []byte
+	if bodyLimit > 0 {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, bodyLimit))
+		if err != nil {
+			errMsg := err.Error()
+			return probeResult{
+				status:          state.StatusUnhealthy,
+				compositeStatus: state.StatusUnhealthy,
+				httpCode:        &code,
+				responseTimeMs:  responseTimeMs,
+				errorSnippet:    &errMsg,
+				tls:             resp.TLS,
+			}
+		}
+		if body == nil {
+			body = []byte{}
+		}
+	}
 
 	var snippet *string
 	if newStatus == state.StatusUnhealthy {
-		snippet = readSnippet(resp.Body)
+		if body != nil {
+			snippet = readSnippet(bytes.NewReader(body))
+		} else {
+			snippet = readSnippet(resp.Body)
+		}
 	}
 
 	return probeResult{
@@ -199,6 +334,8 @@ func (c *Checker) probeService(ctx context.Context, url string) probeResult {
 		httpCode:        &code,
 		responseTimeMs:  responseTimeMs,
 		errorSnippet:    snippet,
+		body:            body,
+		tls:             resp.TLS,
 	}
 }
 
@@ -209,22 +346,51 @@ func (c *Checker) recordTransition(rec history.TransitionRecord) {
 }
 
 // applyResult updates health fields on a service, preserving non-health fields.
+// A status change is held until the service's failure or success threshold is
+// met; until then only the measurement fields and pending counters change.
 // It returns a history transition record when a status transition occurred.
 func (c *Checker) applyResult(svc *state.Service, res probeResult) *history.TransitionRecord {
 	previousStatus := svc.Status
+	damping := c.currentDamping()
 
-	svc.Status = res.status
-	svc.CompositeStatus = res.compositeStatus
 	svc.HTTPCode = res.httpCode
 	svc.ResponseTimeMs = &res.responseTimeMs
 	svc.ErrorSnippet = res.errorSnippet
-	svc.AuthGuarded = res.authGuarded
+	svc.LatencyBreach = res.latencyBreach
+	// Keep the last known certificate when the probe could not connect.
+	if res.tlsCert != nil || res.httpCode != nil || res.status == state.StatusHealthy {
+		svc.TLSCert = res.tlsCert
+	}
 
 	now := time.Now()
 	svc.LastChecked = &now
+	svc.Stale = false
+
+	logArgs := []any{
+		"service", svc.Name,
+		"namespace", svc.Namespace,
+		"status", string(res.status),
+		"responseTimeMs", res.responseTimeMs,
+	}
+	if res.httpCode != nil {
+		logArgs = append(logArgs, "httpCode", *res.httpCode)
+	}
+
+	if res.status != previousStatus && c.holdTransition(svc, res.status, damping) {
+		c.updateFlapping(svc, false, now, damping)
+		logArgs = append(logArgs, "pending", svc.PendingCount, "threshold", svc.PendingThreshold)
+		c.logger.Debug("health check completed, transition pending", logArgs...)
+		return nil
+	}
+	clearPending(svc)
+
+	svc.Status = res.status
+	svc.CompositeStatus = res.compositeStatus
+	svc.AuthGuarded = res.authGuarded
 
 	var transition *history.TransitionRecord
-	if res.status != previousStatus {
+	changed := res.status != previousStatus
+	if changed {
 		svc.LastStateChange = &now
 		c.logger.Info("service health changed",
 			"service", svc.Name,
@@ -235,24 +401,19 @@ func (c *Checker) applyResult(svc *state.Service, res probeResult) *history.Tran
 
 		rec := history.TransitionRecord{
 			Timestamp:  now,
+			Cluster:    svc.Cluster,
 			ServiceKey: svc.Namespace + "/" + svc.Name,
 			PrevStatus: previousStatus,
 			NextStatus: res.status,
 			HTTPCode:   res.httpCode,
 			ResponseMs: &res.responseTimeMs,
+			Expected:   svc.Maintenance,
 		}
 		transition = &rec
 	}
+	// The first result for a new service is not a flap.
+	c.updateFlapping(svc, changed && previousStatus != "" && previousStatus != state.StatusUnknown, now, damping)
 
-	logArgs := []any{
-		"service", svc.Name,
-		"namespace", svc.Namespace,
-		"status", string(res.status),
-		"responseTimeMs", res.responseTimeMs,
-	}
-	if res.httpCode != nil {
-		logArgs = append(logArgs, "httpCode", *res.httpCode)
-	}
 	c.logger.Debug("health check completed", logArgs...)
 	return transition
 }
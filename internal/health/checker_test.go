@@ -62,6 +62,16 @@ func (m *mockHTTPProber) getCapturedRequests() []capturedRequest {
 	return cp
 }
 
+// checkEach checks every local service once, in turn, through the path a
+// scheduled check takes: the limiter, then checkService on its current state.
+func checkEach(c *Checker) {
+	for _, svc := range c.reader.All() {
+		if svc.Site == "" {
+			c.runCheck(context.Background(), c.limiter, svc)
+		}
+	}
+}
+
 func TestCheckService_Healthy(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
@@ -306,7 +316,7 @@ func TestCheckService_ConcurrentChecks(t *testing.T) {
 	}
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc1, _ := store.Get("ns1", "svc1")
 	svc2, _ := store.Get("ns1", "svc2")
@@ -501,7 +511,7 @@ func TestCheckService_HealthyNoErrorSnippet(t *testing.T) {
 	}
 }
 
-func TestCheckAll_ServiceRemovedDuringCheck(t *testing.T) {
+func TestCheckService_ServiceRemovedDuringCheck(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "svc1", Namespace: "ns1", URL: "https://svc1.example.com",
@@ -516,11 +526,12 @@ func TestCheckAll_ServiceRemovedDuringCheck(t *testing.T) {
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
 
-	// Remove the service before checkAll completes
+	// Remove the service before its check completes
+	svc, _ := store.Get("ns1", "svc1")
 	store.Remove("ns1", "svc1")
 
-	// Execute checkAll which uses the Read-Modify-Write pattern
-	checker.checkAll(context.Background())
+	// checkService writes through the Read-Modify-Write pattern
+	checker.checkService(context.Background(), svc)
 
 	// Service should NOT be re-added to the store
 	_, ok := store.Get("ns1", "svc1")
@@ -569,7 +580,7 @@ func TestCheckService_200Range(t *testing.T) {
 	}
 }
 
-func TestCheckAll_HealthURLOverride(t *testing.T) {
+func TestCheckService_HealthURLOverride(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name:      "truenas",
@@ -587,7 +598,7 @@ func TestCheckAll_HealthURLOverride(t *testing.T) {
 	}
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("custom", "truenas")
 	if svc.Status != state.StatusHealthy {
@@ -595,7 +606,7 @@ func TestCheckAll_HealthURLOverride(t *testing.T) {
 	}
 }
 
-func TestCheckAll_ExpectedStatusCodes401Healthy(t *testing.T) {
+func TestCheckService_ExpectedStatusCodes401Healthy(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name:                "authsvc",
@@ -612,7 +623,7 @@ func TestCheckAll_ExpectedStatusCodes401Healthy(t *testing.T) {
 	}
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("custom", "authsvc")
 	if svc.Status != state.StatusHealthy {
@@ -620,7 +631,7 @@ func TestCheckAll_ExpectedStatusCodes401Healthy(t *testing.T) {
 	}
 }
 
-func TestCheckAll_ExpectedStatusCodesNotInList(t *testing.T) {
+func TestCheckService_ExpectedStatusCodesNotInList(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name:                "svc",
@@ -637,7 +648,7 @@ func TestCheckAll_ExpectedStatusCodesNotInList(t *testing.T) {
 	}
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("custom", "svc")
 	if svc.Status != state.StatusUnhealthy {
@@ -645,7 +656,7 @@ func TestCheckAll_ExpectedStatusCodesNotInList(t *testing.T) {
 	}
 }
 
-func TestCheckAll_HealthURLDifferentHost(t *testing.T) {
+func TestCheckService_HealthURLDifferentHost(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name:      "svc",
@@ -663,7 +674,7 @@ func TestCheckAll_HealthURLDifferentHost(t *testing.T) {
 	}
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("custom", "svc")
 	if svc.Status != state.StatusHealthy {
@@ -695,7 +706,7 @@ func (m *mockHistoryWriter) getRecords() []history.TransitionRecord {
 	return cp
 }
 
-func TestCheckAll_TransitionRecordsHistory(t *testing.T) {
+func TestCheckService_TransitionRecordsHistory(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "svc1", Namespace: "ns1", URL: "https://svc1.example.com",
@@ -710,7 +721,7 @@ func TestCheckAll_TransitionRecordsHistory(t *testing.T) {
 
 	hw := &mockHistoryWriter{}
 	checker := NewChecker(store, store, client, time.Hour, hw, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	recs := hw.getRecords()
 	if len(recs) != 1 {
@@ -734,7 +745,30 @@ func TestCheckAll_TransitionRecordsHistory(t *testing.T) {
 	}
 }
 
-func TestCheckAll_NoTransitionNoHistory(t *testing.T) {
+func TestCheckService_MaintenanceTransitionRecordedAsExpected(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "svc1", Namespace: "ns1", URL: "https://svc1.example.com",
+		Status: state.StatusHealthy, Maintenance: true,
+	})
+
+	client := &mockHTTPProber{
+		responses: map[string]mockResponse{
+			"https://svc1.example.com": {statusCode: 503, body: "down for maintenance"},
+		},
+	}
+
+	hw := &mockHistoryWriter{}
+	checker := NewChecker(store, store, client, time.Hour, hw, nil)
+	checkEach(checker)
+
+	recs := hw.getRecords()
+	if len(recs) != 1 || !recs[0].Expected {
+		t.Fatalf("expected 1 transition tagged expected, got %+v", recs)
+	}
+}
+
+func TestCheckService_NoTransitionNoHistory(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "svc1", Namespace: "ns1", URL: "https://svc1.example.com",
@@ -749,7 +783,7 @@ func TestCheckAll_NoTransitionNoHistory(t *testing.T) {
 
 	hw := &mockHistoryWriter{}
 	checker := NewChecker(store, store, client, time.Hour, hw, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	recs := hw.getRecords()
 	if len(recs) != 0 {
@@ -757,7 +791,7 @@ func TestCheckAll_NoTransitionNoHistory(t *testing.T) {
 	}
 }
 
-func TestCheckAll_HistoryWriteErrorDoesNotBlockUpdates(t *testing.T) {
+func TestCheckService_HistoryWriteErrorDoesNotBlockUpdates(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "svc1", Namespace: "ns1", URL: "https://svc1.example.com",
@@ -772,7 +806,7 @@ func TestCheckAll_HistoryWriteErrorDoesNotBlockUpdates(t *testing.T) {
 
 	hw := &mockHistoryWriter{err: errors.New("disk full")}
 	checker := NewChecker(store, store, client, time.Hour, hw, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	// Status should still be updated despite history write failure
 	svc, _ := store.Get("ns1", "svc1")
@@ -795,7 +829,7 @@ func TestNewChecker_NilHistoryWriterDefaultsToNoop(t *testing.T) {
 	}
 
 	checker := NewChecker(store, store, client, time.Hour, nil, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("ns1", "svc1")
 	if svc.Status != state.StatusHealthy {
@@ -803,7 +837,7 @@ func TestNewChecker_NilHistoryWriterDefaultsToNoop(t *testing.T) {
 	}
 }
 
-func TestCheckAll_URLUsedWhenNoHealthURL(t *testing.T) {
+func TestCheckService_URLUsedWhenNoHealthURL(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name:      "config-svc",
@@ -820,7 +854,7 @@ func TestCheckAll_URLUsedWhenNoHealthURL(t *testing.T) {
 	}
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("custom", "config-svc")
 	if svc.Status != state.StatusHealthy {
@@ -846,7 +880,7 @@ func (m *mockEndpointReader) GetEndpointReadiness(namespace, name string) *Endpo
 	return m.data[namespace+"/"+name]
 }
 
-func TestCheckAll_CompositeHealth_AuthGuardedHealthy(t *testing.T) {
+func TestCheckService_CompositeHealth_AuthGuardedHealthy(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "auth-svc", Namespace: "ns1", URL: "https://auth-svc.example.com",
@@ -867,7 +901,7 @@ func TestCheckAll_CompositeHealth_AuthGuardedHealthy(t *testing.T) {
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
 	checker.SetEndpointReader(er)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("ns1", "auth-svc")
 	if svc.Status != state.StatusHealthy {
@@ -878,7 +912,7 @@ func TestCheckAll_CompositeHealth_AuthGuardedHealthy(t *testing.T) {
 	}
 }
 
-func TestCheckAll_CompositeHealth_Degraded(t *testing.T) {
+func TestCheckService_CompositeHealth_Degraded(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "bad-svc", Namespace: "ns1", URL: "https://bad-svc.example.com",
@@ -899,7 +933,7 @@ func TestCheckAll_CompositeHealth_Degraded(t *testing.T) {
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
 	checker.SetEndpointReader(er)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("ns1", "bad-svc")
 	if svc.Status != state.StatusDegraded {
@@ -910,7 +944,7 @@ func TestCheckAll_CompositeHealth_Degraded(t *testing.T) {
 	}
 }
 
-func TestCheckAll_CompositeHealth_NilEndpointReader(t *testing.T) {
+func TestCheckService_CompositeHealth_NilEndpointReader(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "svc", Namespace: "ns1", URL: "https://svc.example.com",
@@ -925,7 +959,7 @@ func TestCheckAll_CompositeHealth_NilEndpointReader(t *testing.T) {
 
 	// Do NOT call SetEndpointReader — endpointReader remains nil
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("ns1", "svc")
 	if svc.Status != state.StatusUnhealthy {
@@ -936,7 +970,7 @@ func TestCheckAll_CompositeHealth_NilEndpointReader(t *testing.T) {
 	}
 }
 
-func TestCheckAll_CompositeHealth_NoEndpointDataForService(t *testing.T) {
+func TestCheckService_CompositeHealth_NoEndpointDataForService(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "svc", Namespace: "ns1", URL: "https://svc.example.com",
@@ -956,7 +990,7 @@ func TestCheckAll_CompositeHealth_NoEndpointDataForService(t *testing.T) {
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
 	checker.SetEndpointReader(er)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("ns1", "svc")
 	if svc.Status != state.StatusUnhealthy {
@@ -967,7 +1001,7 @@ func TestCheckAll_CompositeHealth_NoEndpointDataForService(t *testing.T) {
 	}
 }
 
-func TestCheckAll_CompositeHealth_AuthGuardedClearedWhenPodsRecover(t *testing.T) {
+func TestCheckService_CompositeHealth_AuthGuardedClearedWhenPodsRecover(t *testing.T) {
 	store := state.NewStore()
 	store.AddOrUpdate(state.Service{
 		Name: "svc", Namespace: "ns1", URL: "https://svc.example.com",
@@ -989,7 +1023,7 @@ func TestCheckAll_CompositeHealth_AuthGuardedClearedWhenPodsRecover(t *testing.T
 
 	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
 	checker.SetEndpointReader(er)
-	checker.checkAll(context.Background())
+	checkEach(checker)
 
 	svc, _ := store.Get("ns1", "svc")
 	if svc.Status != state.StatusHealthy {
@@ -1000,3 +1034,39 @@ func TestCheckAll_CompositeHealth_AuthGuardedClearedWhenPodsRecover(t *testing.T
 	}
 }
 
+
+func TestRun_RestoredServicesCheckedAfterRediscovery(t *testing.T) {
+	store := state.NewStore()
+	checked := time.Now().Add(-time.Hour)
+	store.Restore([]state.Service{{
+		Name: "svc1", Namespace: "ns1", URL: "https://svc1.example.com",
+		Status: state.StatusUnhealthy, CompositeStatus: state.StatusUnhealthy, LastChecked: &checked,
+	}})
+
+	client := &mockHTTPProber{
+		responses: map[string]mockResponse{
+			"https://svc1.example.com": {statusCode: 200, body: "OK"},
+		},
+	}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go checker.Run(ctx)
+
+	time.Sleep(200 * time.Millisecond)
+	if n := len(client.getCapturedRequests()); n != 0 {
+		t.Fatalf("restored service probed %d times before rediscovery", n)
+	}
+
+	store.AddOrUpdate(state.Service{Name: "svc1", Namespace: "ns1", URL: "https://svc1.example.com", Status: state.StatusUnknown})
+	time.Sleep(1500 * time.Millisecond)
+
+	svc, _ := store.Get("ns1", "svc1")
+	if svc.Stale {
+		t.Error("expected stale cleared after the first check")
+	}
+	if svc.Status != state.StatusHealthy {
+		t.Errorf("expected status %q, got %q", state.StatusHealthy, svc.Status)
+	}
+}
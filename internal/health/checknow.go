@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// checkNowDebounce is how recently a service may have been checked for an
+// on-demand request to reuse that result instead of probing again, so
+// repeated clicks do not stack probes.
+const checkNowDebounce = 2 * time.Second
+
+// CheckService checks one service immediately and returns its updated state.
+// It returns false if the service does not exist.
+func (c *Checker) CheckService(ctx context.Context, namespace, name string) (state.Service, bool) {
+	svc, ok := c.reader.Get(namespace, name)
+	if !ok {
+		return state.Service{}, false
+	}
+	results := c.checkNow(ctx, []state.Service{svc})
+	if len(results) == 0 {
+		return state.Service{}, false
+	}
+	return results[0], true
+}
+
+// CheckGroup checks every service in group immediately and returns their
+// updated states.
+func (c *Checker) CheckGroup(ctx context.Context, group string) []state.Service {
+	var services []state.Service
+	for _, svc := range c.reader.All() {
+		if svc.Group == group {
+			services = append(services, svc)
+		}
+	}
+	return c.checkNow(ctx, services)
+}
+
+// CheckAllNow checks every service immediately and returns their updated
+// states.
+func (c *Checker) CheckAllNow(ctx context.Context) []state.Service {
+	return c.checkNow(ctx, c.reader.All())
+}
+
+// checkNow runs on-demand checks through the same limiter, probe, and fusion
+// path as scheduled checks and returns the resulting states sorted by key.
+// A service whose check is already running is not probed again; its result
+// is awaited instead. A service checked within checkNowDebounce keeps its
+// current result, and so does a federated service, which its site checks.
+// Once started, a check completes even if ctx is cancelled, so an abandoned
+// request never records a spurious failure.
+func (c *Checker) checkNow(ctx context.Context, services []state.Service) []state.Service {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make([]state.Service, 0, len(services))
+	)
+	for _, svc := range services {
+		wg.Add(1)
+		go func(s state.Service) {
+			defer wg.Done()
+			if s.Site == "" && !c.checkOnce(ctx, s) {
+				return
+			}
+			if current, ok := c.reader.Get(s.ScopedNamespace(), s.Name); ok {
+				mu.Lock()
+				results = append(results, current)
+				mu.Unlock()
+			}
+		}(svc)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ScopedNamespace() != results[j].ScopedNamespace() {
+			return results[i].ScopedNamespace() < results[j].ScopedNamespace()
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// checkOnce checks s unless a check is running or just ran, in which case it
+// waits for that one. It returns false if ctx ended while waiting.
+func (c *Checker) checkOnce(ctx context.Context, s state.Service) bool {
+	key := s.Key()
+	now := time.Now()
+
+	c.mu.Lock()
+	if done, running := c.inflight[key]; running {
+		c.mu.Unlock()
+		select {
+		case <-done:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if last, ok := c.lastStart[key]; ok && now.Sub(last) < checkNowDebounce {
+		c.mu.Unlock()
+		return true
+	}
+	done := make(chan struct{})
+	c.inflight[key] = done
+	c.lastStart[key] = now
+	lim := c.limiter
+	c.mu.Unlock()
+
+	c.runCheck(context.WithoutCancel(ctx), lim, s)
+	c.finishCheck(key, done)
+	return true
+}
@@ -0,0 +1,169 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+func TestCheckService_RunsImmediately(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "app", Namespace: "ns", URL: "https://app.example.com", Status: state.StatusUnknown})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://app.example.com": {statusCode: 200, body: "OK"},
+	}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	svc, ok := checker.CheckService(context.Background(), "ns", "app")
+	if !ok {
+		t.Fatal("expected service to be found")
+	}
+	if svc.Status != state.StatusHealthy || svc.LastChecked == nil {
+		t.Errorf("expected fresh healthy result, got %q (lastChecked %v)", svc.Status, svc.LastChecked)
+	}
+
+	// A second request right away reuses the result.
+	checker.CheckService(context.Background(), "ns", "app")
+	if got := len(client.getCapturedRequests()); got != 1 {
+		t.Errorf("expected debounced re-check, got %d probes", got)
+	}
+
+	if _, ok := checker.CheckService(context.Background(), "ns", "missing"); ok {
+		t.Error("expected missing service to be reported")
+	}
+}
+
+func TestCheckService_FederatedNotProbed(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "app", Namespace: "ns", Site: "east", URL: "https://app.example.com", Status: state.StatusDegraded})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://app.example.com": {statusCode: 200, body: "OK"},
+	}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	svc, ok := checker.CheckService(context.Background(), "east/ns", "app")
+	if !ok {
+		t.Fatal("expected federated service to be found")
+	}
+	if svc.Status != state.StatusDegraded {
+		t.Errorf("status = %q, want the site's degraded", svc.Status)
+	}
+	var wg sync.WaitGroup
+	checker.runDue(context.Background(), &wg)
+	wg.Wait()
+	if got := len(client.getCapturedRequests()); got != 0 {
+		t.Errorf("federated service probed %d times, want 0", got)
+	}
+}
+
+func TestCheckNow_WaitsForInflightCheck(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "app", Namespace: "ns", URL: "https://app.example.com"})
+
+	client := &blockingProber{calls: make(map[string]int), release: make(chan struct{})}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	var wg sync.WaitGroup
+	results := make([]state.Service, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = checker.CheckService(context.Background(), "ns", "app")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(client.release)
+	wg.Wait()
+
+	if got := client.count("https://app.example.com"); got != 1 {
+		t.Errorf("concurrent requests stacked %d probes, want 1", got)
+	}
+	for i, svc := range results {
+		if svc.Status != state.StatusHealthy {
+			t.Errorf("results[%d].Status = %q, want healthy", i, svc.Status)
+		}
+	}
+}
+
+func TestCheckHandler(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "a", Namespace: "ns", Group: "media", URL: "https://a.example.com"})
+	store.AddOrUpdate(state.Service{Name: "b", Namespace: "ns", Group: "media", URL: "https://b.example.com"})
+	store.AddOrUpdate(state.Service{Name: "c", Namespace: "other", Group: "infra", URL: "https://c.example.com"})
+	store.AddOrUpdate(state.Service{Name: "b", Namespace: "ns", Cluster: "lab", Group: "lab", URL: "https://b.lab.example.com"})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://a.example.com":     {statusCode: 200, body: "OK"},
+		"https://b.example.com":     {statusCode: 503, body: "down"},
+		"https://c.example.com":     {statusCode: 200, body: "OK"},
+		"https://b.lab.example.com": {statusCode: 200, body: "OK"},
+	}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	mux := http.NewServeMux()
+	handler := NewCheckHandler(checker)
+	mux.Handle("POST /api/services/check", handler)
+	mux.Handle("POST /api/services/{namespace}/{name}/check", handler)
+	mux.Handle("POST /api/services/{cluster}/{namespace}/{name}/check", handler)
+	mux.Handle("POST /api/groups/{group}/check", handler)
+
+	tests := []struct {
+		name      string
+		path      string
+		wantCode  int
+		wantNames []string
+	}{
+		{"single", "/api/services/ns/b/check", http.StatusOK, []string{"b"}},
+		{"cluster service", "/api/services/lab/ns/b/check", http.StatusOK, []string{"b"}},
+		{"group", "/api/groups/media/check", http.StatusOK, []string{"a", "b"}},
+		{"all", "/api/services/check", http.StatusOK, []string{"b", "a", "b", "c"}},
+		{"unknown service", "/api/services/ns/zzz/check", http.StatusNotFound, nil},
+		{"unknown group", "/api/groups/none/check", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantNames == nil {
+				return
+			}
+			var body struct {
+				Service  *state.Service  `json:"service"`
+				Services []state.Service `json:"services"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			services := body.Services
+			if body.Service != nil {
+				services = []state.Service{*body.Service}
+			}
+			if len(services) != len(tt.wantNames) {
+				t.Fatalf("got %d services, want %v", len(services), tt.wantNames)
+			}
+			for i, svc := range services {
+				if svc.Name != tt.wantNames[i] || svc.LastChecked == nil {
+					t.Errorf("services[%d] = %s (checked %v), want fresh %s", i, svc.Name, svc.LastChecked, tt.wantNames[i])
+				}
+			}
+		})
+	}
+
+	got, _ := store.Get("ns", "b")
+	if got.Status != state.StatusUnhealthy {
+		t.Errorf("expected store updated with check result, got %q", got.Status)
+	}
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// defaultFlapWindow is used when flap detection is enabled without a window.
+const defaultFlapWindow = 10 * time.Minute
+
+// Damping controls how many consecutive probe results are needed before a
+// service changes status, and when a service is considered flapping.
+// Zero thresholds mean 1 (every result is applied immediately); a zero
+// FlapThreshold disables flap detection.
+type Damping struct {
+	FailureThreshold int
+	SuccessThreshold int
+	FlapThreshold    int           // status changes within FlapWindow that mark a service flapping
+	FlapWindow       time.Duration // defaults to 10m
+}
+
+// thresholdFor returns how many consecutive results with status next are
+// needed before svc transitions to it.
+func (d Damping) thresholdFor(svc state.Service, next state.HealthStatus) int {
+	threshold := d.FailureThreshold
+	if svc.FailureThreshold > 0 {
+		threshold = svc.FailureThreshold
+	}
+	if next == state.StatusHealthy {
+		threshold = d.SuccessThreshold
+		if svc.SuccessThreshold > 0 {
+			threshold = svc.SuccessThreshold
+		}
+	}
+	return max(threshold, 1)
+}
+
+func (d Damping) flapWindow() time.Duration {
+	if d.FlapWindow > 0 {
+		return d.FlapWindow
+	}
+	return defaultFlapWindow
+}
+
+// SetDamping replaces the transition thresholds, e.g. after a config reload.
+// Pending counters already on services are compared against the new values
+// on their next check.
+func (c *Checker) SetDamping(d Damping) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.damping = d
+}
+
+func (c *Checker) currentDamping() Damping {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.damping
+}
+
+// holdTransition records a result whose status differs from the service's
+// current one and reports whether the change must wait for more consecutive
+// results. Degraded and unhealthy results count alike as failures, and the
+// latest one is applied once the threshold is met. A service with no status
+// yet transitions at once.
+func (c *Checker) holdTransition(svc *state.Service, next state.HealthStatus, damping Damping) bool {
+	if svc.Status == "" || svc.Status == state.StatusUnknown {
+		return false
+	}
+	if svc.PendingStatus != "" && isFailure(svc.PendingStatus) == isFailure(next) {
+		svc.PendingCount++
+	} else {
+		svc.PendingCount = 1
+	}
+	svc.PendingStatus = next
+	svc.PendingThreshold = damping.thresholdFor(*svc, next)
+	return svc.PendingCount < svc.PendingThreshold
+}
+
+// isFailure reports whether status counts towards the failure threshold.
+func isFailure(status state.HealthStatus) bool {
+	return status == state.StatusDegraded || status == state.StatusUnhealthy
+}
+
+func clearPending(svc *state.Service) {
+	svc.PendingStatus = ""
+	svc.PendingCount = 0
+	svc.PendingThreshold = 0
+}
+
+// updateFlapping records a status change at now (when changed is true),
+// forgets changes older than the flap window, and sets svc.Flapping when the
+// remaining count reaches the flap threshold.
+func (c *Checker) updateFlapping(svc *state.Service, changed bool, now time.Time, damping Damping) {
+	key := svc.Key()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if damping.FlapThreshold <= 0 {
+		delete(c.flaps, key)
+		svc.Flapping = false
+		return
+	}
+
+	changes := c.flaps[key]
+	if changed {
+		changes = append(changes, now)
+	}
+	cutoff := now.Add(-damping.flapWindow())
+	i := 0
+	for i < len(changes) && !changes[i].After(cutoff) {
+		i++
+	}
+	changes = changes[i:]
+	if len(changes) == 0 {
+		delete(c.flaps, key)
+	} else {
+		c.flaps[key] = changes
+	}
+
+	flapping := len(changes) >= damping.FlapThreshold
+	if flapping != svc.Flapping {
+		c.logger.Info("service flapping state changed",
+			"service", svc.Name,
+			"namespace", svc.Namespace,
+			"flapping", flapping,
+			"changes", len(changes),
+		)
+	}
+	svc.Flapping = flapping
+}
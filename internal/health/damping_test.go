@@ -0,0 +1,173 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+func TestDamping_ThresholdFor(t *testing.T) {
+	d := Damping{FailureThreshold: 3, SuccessThreshold: 2}
+	tests := []struct {
+		name string
+		svc  state.Service
+		next state.HealthStatus
+		want int
+	}{
+		{"global failure", state.Service{}, state.StatusUnhealthy, 3},
+		{"degraded counts as failure", state.Service{}, state.StatusDegraded, 3},
+		{"global success", state.Service{}, state.StatusHealthy, 2},
+		{"service failure override", state.Service{FailureThreshold: 5}, state.StatusUnhealthy, 5},
+		{"service success override", state.Service{SuccessThreshold: 1}, state.StatusHealthy, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.thresholdFor(tt.svc, tt.next); got != tt.want {
+				t.Errorf("thresholdFor = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := (Damping{}).thresholdFor(state.Service{}, state.StatusUnhealthy); got != 1 {
+		t.Errorf("zero damping threshold = %d, want 1", got)
+	}
+}
+
+func TestCheckService_FailureThresholdHoldsTransition(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "nas", Namespace: "custom", URL: "https://nas.local",
+		Status: state.StatusHealthy, CompositeStatus: state.StatusHealthy,
+	})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://nas.local": {statusCode: 503, body: "down"},
+	}}
+	hw := &mockHistoryWriter{}
+	checker := NewChecker(store, store, client, time.Hour, hw, nil)
+	checker.SetDamping(Damping{FailureThreshold: 3})
+
+	for i := 1; i <= 2; i++ {
+		checkEach(checker)
+		svc, _ := store.Get("custom", "nas")
+		if svc.Status != state.StatusHealthy {
+			t.Fatalf("check %d: status = %q, want healthy until threshold", i, svc.Status)
+		}
+		if svc.PendingStatus != state.StatusUnhealthy || svc.PendingCount != i || svc.PendingThreshold != 3 {
+			t.Fatalf("check %d: pending = %q %d/%d, want unhealthy %d/3", i, svc.PendingStatus, svc.PendingCount, svc.PendingThreshold, i)
+		}
+		if svc.HTTPCode == nil || *svc.HTTPCode != 503 {
+			t.Errorf("check %d: expected measurement fields to update, got HTTPCode %v", i, svc.HTTPCode)
+		}
+	}
+	if len(hw.getRecords()) != 0 {
+		t.Fatalf("expected no history while transition is pending, got %d", len(hw.getRecords()))
+	}
+
+	checkEach(checker)
+	svc, _ := store.Get("custom", "nas")
+	if svc.Status != state.StatusUnhealthy || svc.CompositeStatus != state.StatusUnhealthy {
+		t.Errorf("status = %q/%q, want unhealthy after threshold", svc.Status, svc.CompositeStatus)
+	}
+	if svc.PendingStatus != "" || svc.PendingCount != 0 || svc.PendingThreshold != 0 {
+		t.Errorf("expected pending counters cleared, got %q %d/%d", svc.PendingStatus, svc.PendingCount, svc.PendingThreshold)
+	}
+	if len(hw.getRecords()) != 1 {
+		t.Errorf("expected 1 history record, got %d", len(hw.getRecords()))
+	}
+}
+
+func TestCheckService_RecoveryResetsPendingFailures(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "nas", Namespace: "custom", URL: "https://nas.local",
+		Status: state.StatusHealthy, CompositeStatus: state.StatusHealthy,
+	})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://nas.local": {statusCode: 503, body: "down"},
+	}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	checker.SetDamping(Damping{FailureThreshold: 2})
+
+	checkEach(checker)
+	client.mu.Lock()
+	client.responses["https://nas.local"] = mockResponse{statusCode: 200, body: "OK"}
+	client.mu.Unlock()
+	checkEach(checker)
+
+	svc, _ := store.Get("custom", "nas")
+	if svc.Status != state.StatusHealthy || svc.PendingCount != 0 {
+		t.Errorf("status = %q pending %d, want healthy with counters reset", svc.Status, svc.PendingCount)
+	}
+}
+
+func TestCheckService_FirstResultIgnoresThreshold(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "nas", Namespace: "custom", URL: "https://nas.local",
+		Status: state.StatusUnknown,
+	})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://nas.local": {statusCode: 503, body: "down"},
+	}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	checker.SetDamping(Damping{FailureThreshold: 3})
+	checkEach(checker)
+
+	svc, _ := store.Get("custom", "nas")
+	if svc.Status != state.StatusUnhealthy {
+		t.Errorf("status = %q, want unhealthy on first result", svc.Status)
+	}
+}
+
+func TestApplyResult_MixedFailuresCountTowardsThreshold(t *testing.T) {
+	checker := NewChecker(state.NewStore(), state.NewStore(), &mockHTTPProber{}, time.Hour, history.NoopWriter{}, nil)
+	checker.SetDamping(Damping{FailureThreshold: 3})
+
+	svc := state.Service{Name: "nas", Namespace: "custom", Status: state.StatusHealthy}
+	statuses := []state.HealthStatus{state.StatusDegraded, state.StatusUnhealthy, state.StatusDegraded}
+	for i, status := range statuses[:2] {
+		checker.applyResult(&svc, probeResult{status: status, compositeStatus: status})
+		if svc.Status != state.StatusHealthy || svc.PendingStatus != status || svc.PendingCount != i+1 {
+			t.Fatalf("check %d: status %q pending %q %d, want healthy pending %q %d", i+1, svc.Status, svc.PendingStatus, svc.PendingCount, status, i+1)
+		}
+	}
+
+	checker.applyResult(&svc, probeResult{status: statuses[2], compositeStatus: statuses[2]})
+	if svc.Status != state.StatusDegraded || svc.PendingCount != 0 {
+		t.Errorf("status = %q pending %d, want the latest failure, degraded, applied", svc.Status, svc.PendingCount)
+	}
+}
+
+func TestApplyResult_FlapDetection(t *testing.T) {
+	checker := NewChecker(state.NewStore(), state.NewStore(), &mockHTTPProber{}, time.Hour, history.NoopWriter{}, nil)
+	checker.SetDamping(Damping{FlapThreshold: 3, FlapWindow: time.Minute})
+
+	svc := state.Service{Name: "wifi-ap", Namespace: "custom", Status: state.StatusHealthy}
+	statuses := []state.HealthStatus{state.StatusUnhealthy, state.StatusHealthy, state.StatusUnhealthy}
+	for i, status := range statuses {
+		checker.applyResult(&svc, probeResult{status: status, compositeStatus: status})
+		if want := i == len(statuses)-1; svc.Flapping != want {
+			t.Fatalf("after change %d: flapping = %v, want %v", i+1, svc.Flapping, want)
+		}
+	}
+
+	// Age the recorded changes past the window; the next check clears the flag.
+	checker.mu.Lock()
+	for key, changes := range checker.flaps {
+		for i := range changes {
+			changes[i] = changes[i].Add(-2 * time.Minute)
+		}
+		checker.flaps[key] = changes
+	}
+	checker.mu.Unlock()
+
+	checker.applyResult(&svc, probeResult{status: state.StatusUnhealthy, compositeStatus: state.StatusUnhealthy})
+	if svc.Flapping {
+		t.Error("expected flapping to clear once changes leave the window")
+	}
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+type checkServiceResponse struct {
+	Service state.Service `json:"service"`
+}
+
+type checkServicesResponse struct {
+	Services []state.Service `json:"services"`
+}
+
+type checkErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewCheckHandler returns an http.Handler for the on-demand check endpoints:
+//
+//	POST /api/services/{namespace}/{name}/check            one service
+//	POST /api/services/{cluster}/{namespace}/{name}/check  one service of a named cluster
+//	POST /api/groups/{group}/check                         every service in a group
+//	POST /api/services/check                               every service
+//
+// The response carries the fresh results; the same updates also reach SSE
+// clients through the state store.
+func NewCheckHandler(c *Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace, name, group := r.PathValue("namespace"), r.PathValue("name"), r.PathValue("group")
+		switch {
+		case namespace != "" && name != "":
+			svc, ok := c.CheckService(r.Context(), state.ScopedNamespace(r.PathValue("cluster"), namespace), name)
+			if !ok {
+				writeJSON(w, http.StatusNotFound, checkErrorResponse{Error: "service not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, checkServiceResponse{Service: svc})
+		case group != "":
+			services := c.CheckGroup(r.Context(), group)
+			if len(services) == 0 {
+				writeJSON(w, http.StatusNotFound, checkErrorResponse{Error: "group not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, checkServicesResponse{Services: services})
+		default:
+			writeJSON(w, http.StatusOK, checkServicesResponse{Services: c.CheckAllNow(r.Context())})
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,173 @@
+package health
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// cachedClient is an http.Client built for one probe configuration, with the
+// modification time of its client certificate so rotated certificates are
+// picked up.
+type cachedClient struct {
+	client  *http.Client
+	certMod time.Time
+}
+
+// cachedPool is a CA pool loaded from a file, with the file's modification time.
+type cachedPool struct {
+	pool *x509.CertPool
+	mod  time.Time
+}
+
+// applyProbeRequest sets the headers, Host override, and credentials from
+// spec on a probe request.
+func applyProbeRequest(req *http.Request, spec *state.ProbeSpec) error {
+	if spec == nil {
+		return nil
+	}
+	if spec.Host != "" {
+		req.Host = spec.Host
+	}
+	for name, ref := range spec.Headers {
+		v, err := ref.Resolve()
+		if err != nil {
+			return fmt.Errorf("probe header %s: %w", name, err)
+		}
+		req.Header.Set(name, v)
+	}
+	if spec.BearerToken != nil {
+		token, err := spec.BearerToken.Resolve()
+		if err != nil {
+			return fmt.Errorf("probe bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if spec.BasicAuth != nil {
+		password, err := spec.BasicAuth.Password.Resolve()
+		if err != nil {
+			return fmt.Errorf("probe basic auth password: %w", err)
+		}
+		req.SetBasicAuth(spec.BasicAuth.Username, password)
+	}
+	return nil
+}
+
+// probeServerName returns the TLS server name to present for spec: the
+// explicit serverName, else the host of a Host header override, else "".
+func probeServerName(spec *state.ProbeSpec) string {
+	if spec == nil {
+		return ""
+	}
+	if spec.ServerName != "" {
+		return spec.ServerName
+	}
+	if spec.Host != "" {
+		if host, _, err := net.SplitHostPort(spec.Host); err == nil {
+			return host
+		}
+		return spec.Host
+	}
+	return ""
+}
+
+// httpClientFor returns the client used to probe with spec. Probes without
+// client certificates, server name overrides, or redirect changes share the
+// Checker's client; every other configuration gets a dedicated client, built
+// once and cached.
+func (c *Checker) httpClientFor(spec *state.ProbeSpec) (HTTPProber, error) {
+	serverName := probeServerName(spec)
+	if spec == nil || (spec.CertFile == "" && serverName == "" && !spec.DisableRedirects) {
+		return c.client, nil
+	}
+	base, ok := c.client.(*http.Client)
+	if !ok && spec.CertFile == "" && serverName == "" {
+		// A non-standard prober (e.g. in tests) does not follow redirects.
+		return c.client, nil
+	}
+
+	var certMod time.Time
+	if spec.CertFile != "" {
+		info, err := os.Stat(spec.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("probe client certificate: %w", err)
+		}
+		certMod = info.ModTime()
+	}
+	key := strings.Join([]string{spec.CertFile, spec.KeyFile, serverName, fmt.Sprint(spec.DisableRedirects)}, "|")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.clients[key]; ok && cached.certMod.Equal(certMod) {
+		return cached.client, nil
+	}
+
+	// Certificates are verified by the checker, not the transport, so a
+	// transport without its own TLS settings skips verification.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	if ok {
+		if t, isTransport := base.Transport.(*http.Transport); isTransport {
+			transport = t.Clone()
+			if transport.TLSClientConfig != nil {
+				tlsConfig = transport.TLSClientConfig.Clone()
+			}
+		}
+	}
+	tlsConfig.ServerName = serverName
+	if spec.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(spec.CertFile, spec.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("probe client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	client := &http.Client{Transport: transport}
+	if spec.DisableRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	if old, ok := c.clients[key]; ok {
+		old.client.CloseIdleConnections()
+	}
+	c.clients[key] = cachedClient{client: client, certMod: certMod}
+	return client, nil
+}
+
+// certPoolFor returns the roots for verifying a service's certificate: the
+// service's own CA file when set, else the TLS policy roots.
+func (c *Checker) certPoolFor(spec *state.ProbeSpec, policy TLSPolicy) (*x509.CertPool, error) {
+	if spec == nil || spec.CAFile == "" {
+		return policy.Roots, nil
+	}
+	info, err := os.Stat(spec.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.certPools[spec.CAFile]
+	c.mu.Unlock()
+	if ok && cached.mod.Equal(info.ModTime()) {
+		return cached.pool, nil
+	}
+
+	pool, err := LoadCertPool(spec.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.certPools[spec.CAFile] = cachedPool{pool: pool, mod: info.ModTime()}
+	c.mu.Unlock()
+	return pool, nil
+}
@@ -0,0 +1,141 @@
+package health
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+func TestProbeHTTP_RequestOptions(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer srv.Close()
+	t.Setenv("PROBE_TEST_TOKEN", "s3cret")
+
+	checker := NewChecker(state.NewStore(), state.NewStore(), srv.Client(), time.Hour, history.NoopWriter{}, nil)
+
+	spec := &state.ProbeSpec{
+		Method:      http.MethodPost,
+		Host:        "app.internal",
+		Body:        `{"ping":true}`,
+		Headers:     map[string]state.SecretRef{"X-Api-Key": {Env: "PROBE_TEST_TOKEN"}},
+		BearerToken: &state.SecretRef{Value: "tok"},
+	}
+	res := checker.probeHTTP(context.Background(), srv.URL, spec, 0)
+	if res.status != state.StatusHealthy {
+		t.Fatalf("status = %q, want healthy (error %v)", res.status, res.errorSnippet)
+	}
+	if got.Method != http.MethodPost || got.Host != "app.internal" || gotBody != `{"ping":true}` {
+		t.Errorf("request = %s %s body %q", got.Method, got.Host, gotBody)
+	}
+	if v := got.Header.Get("X-Api-Key"); v != "s3cret" {
+		t.Errorf("X-Api-Key = %q", v)
+	}
+	if v := got.Header.Get("Authorization"); v != "Bearer tok" {
+		t.Errorf("Authorization = %q", v)
+	}
+
+	basic := &state.ProbeSpec{BasicAuth: &state.BasicAuth{Username: "admin", Password: state.SecretRef{Value: "pw"}}}
+	checker.probeHTTP(context.Background(), srv.URL, basic, 0)
+	if user, pass, ok := got.BasicAuth(); !ok || user != "admin" || pass != "pw" {
+		t.Errorf("basic auth = %q/%q (%v)", user, pass, ok)
+	}
+
+	missing := &state.ProbeSpec{BearerToken: &state.SecretRef{Env: "PROBE_TEST_UNSET"}}
+	res = checker.probeHTTP(context.Background(), srv.URL, missing, 0)
+	if res.status != state.StatusUnhealthy || res.errorSnippet == nil {
+		t.Errorf("expected unhealthy with an unresolvable secret, got %q", res.status)
+	}
+}
+
+func TestProbeHTTP_Redirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/login", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	checker := NewChecker(state.NewStore(), state.NewStore(), srv.Client(), time.Hour, history.NoopWriter{}, nil)
+	tests := []struct {
+		name       string
+		spec       *state.ProbeSpec
+		wantCode   int
+		wantStatus state.HealthStatus
+	}{
+		{"followed by default", nil, http.StatusInternalServerError, state.StatusUnhealthy},
+		{"not followed", &state.ProbeSpec{DisableRedirects: true}, http.StatusFound, state.StatusUnhealthy},
+		{"not followed, healthy", &state.ProbeSpec{DisableRedirects: true, RedirectsHealthy: true}, http.StatusFound, state.StatusHealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := checker.probeHTTP(context.Background(), srv.URL, tt.spec, 0)
+			if res.httpCode == nil || *res.httpCode != tt.wantCode {
+				t.Fatalf("httpCode = %v, want %d", res.httpCode, tt.wantCode)
+			}
+			if res.status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", res.status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHTTPClientFor_Caching(t *testing.T) {
+	base := &http.Client{}
+	checker := NewChecker(state.NewStore(), state.NewStore(), base, time.Hour, history.NoopWriter{}, nil)
+
+	if got, _ := checker.httpClientFor(&state.ProbeSpec{Method: http.MethodHead}); got != HTTPProber(base) {
+		t.Error("expected plain probes to share the checker's client")
+	}
+
+	spec := &state.ProbeSpec{ServerName: "nas.home"}
+	first, err := checker.httpClientFor(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == HTTPProber(base) {
+		t.Fatal("expected a dedicated client for a server name override")
+	}
+	if again, _ := checker.httpClientFor(&state.ProbeSpec{ServerName: "nas.home"}); again != first {
+		t.Error("expected the dedicated client to be reused")
+	}
+	tr := first.(*http.Client).Transport.(*http.Transport)
+	if tr.TLSClientConfig.ServerName != "nas.home" || !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("unexpected TLS config: serverName %q, skipVerify %v", tr.TLSClientConfig.ServerName, tr.TLSClientConfig.InsecureSkipVerify)
+	}
+
+	if _, err := checker.httpClientFor(&state.ProbeSpec{CertFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected error for missing client certificate")
+	}
+}
+
+func TestProbeServerName(t *testing.T) {
+	tests := []struct {
+		spec *state.ProbeSpec
+		want string
+	}{
+		{nil, ""},
+		{&state.ProbeSpec{}, ""},
+		{&state.ProbeSpec{Host: "app.internal:8443"}, "app.internal"},
+		{&state.ProbeSpec{Host: "app.internal", ServerName: "tls.internal"}, "tls.internal"},
+	}
+	for _, tt := range tests {
+		if got := probeServerName(tt.spec); got != tt.want {
+			t.Errorf("probeServerName(%+v) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
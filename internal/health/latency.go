@@ -0,0 +1,88 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// defaultLatencyWindow is how many recent response times feed the p95 when
+// a service's LatencySLO leaves Window unset.
+const defaultLatencyWindow = 20
+
+// observeLatency records ms as the newest response time for key, keeping at
+// most window samples, and returns a copy of the retained samples.
+func (c *Checker) observeLatency(key string, ms int64, window int) []int64 {
+	if window <= 0 {
+		window = defaultLatencyWindow
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	samples := append(c.latency[key], ms)
+	if len(samples) > window {
+		samples = slices.Clone(samples[len(samples)-window:])
+	}
+	c.latency[key] = samples
+	return slices.Clone(samples)
+}
+
+// percentile returns the nearest-rank p-th percentile (0 < p <= 100) of
+// samples, or 0 for no samples.
+func percentile(samples []int64, p float64) int64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// applyLatencySLO judges the response time of a probe that got a response
+// against the service's latency thresholds. Crossing a threshold sets
+// res.latencyBreach and downgrades res to degraded or unhealthy; a status
+// that is already worse is kept.
+func (c *Checker) applyLatencySLO(res *probeResult, s state.Service, responded bool) {
+	key := s.Key()
+	slo := s.Latency
+	if slo == nil {
+		c.mu.Lock()
+		delete(c.latency, key)
+		c.mu.Unlock()
+		return
+	}
+	if !responded {
+		return
+	}
+
+	observed := res.responseTimeMs
+	label := fmt.Sprintf("last=%dms", observed)
+	if slo.P95 {
+		observed = percentile(c.observeLatency(key, res.responseTimeMs, slo.Window), 95)
+		label = fmt.Sprintf("p95=%dms", observed)
+	}
+
+	var (
+		status    state.HealthStatus
+		threshold int64
+	)
+	switch {
+	case slo.UnhealthyAboveMs > 0 && observed > slo.UnhealthyAboveMs:
+		status, threshold = state.StatusUnhealthy, slo.UnhealthyAboveMs
+	case slo.DegradedAboveMs > 0 && observed > slo.DegradedAboveMs:
+		status, threshold = state.StatusDegraded, slo.DegradedAboveMs
+	default:
+		return
+	}
+	res.latencyBreach = label
+
+	if res.status == state.StatusUnhealthy || (res.status == state.StatusDegraded && status == state.StatusDegraded) {
+		return
+	}
+	res.status = status
+	res.compositeStatus = status
+	res.errorSnippet = ptrString(fmt.Sprintf("latency: %s above %dms", label, threshold))
+}
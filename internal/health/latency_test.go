@@ -0,0 +1,98 @@
+package health
+
+import (
+	"testing"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		samples []int64
+		want    int64
+	}{
+		{nil, 0},
+		{[]int64{120}, 120},
+		{[]int64{300, 100, 200}, 300},
+		{[]int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140, 150, 160, 170, 180, 190, 9200}, 190},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.samples, 95); got != tt.want {
+			t.Errorf("percentile(%v, 95) = %d, want %d", tt.samples, got, tt.want)
+		}
+	}
+}
+
+func TestApplyLatencySLO(t *testing.T) {
+	slo := &state.LatencySLO{DegradedAboveMs: 2000, UnhealthyAboveMs: 8000}
+	tests := []struct {
+		name       string
+		status     state.HealthStatus
+		ms         int64
+		responded  bool
+		slo        *state.LatencySLO
+		wantStatus state.HealthStatus
+		wantBreach string
+		wantSnip   string
+	}{
+		{"fast", state.StatusHealthy, 150, true, slo, state.StatusHealthy, "", ""},
+		{"slow", state.StatusHealthy, 3000, true, slo, state.StatusDegraded, "last=3000ms", "latency: last=3000ms above 2000ms"},
+		{"very slow", state.StatusHealthy, 9200, true, slo, state.StatusUnhealthy, "last=9200ms", "latency: last=9200ms above 8000ms"},
+		{"unhealthy only", state.StatusHealthy, 3000, true, &state.LatencySLO{UnhealthyAboveMs: 8000}, state.StatusHealthy, "", ""},
+		{"worse status kept", state.StatusUnhealthy, 3000, true, slo, state.StatusUnhealthy, "last=3000ms", ""},
+		{"no response", state.StatusUnhealthy, 10000, false, slo, state.StatusUnhealthy, "", ""},
+		{"no slo", state.StatusHealthy, 9200, true, nil, state.StatusHealthy, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(state.NewStore(), state.NewStore(), &mockHTTPProber{}, 0, history.NoopWriter{}, nil)
+			res := probeResult{status: tt.status, compositeStatus: tt.status, responseTimeMs: tt.ms}
+			checker.applyLatencySLO(&res, state.Service{Name: "app", Namespace: "ns", Latency: tt.slo}, tt.responded)
+			if res.status != tt.wantStatus || res.compositeStatus != tt.wantStatus {
+				t.Errorf("status = %q/%q, want %q", res.status, res.compositeStatus, tt.wantStatus)
+			}
+			if res.latencyBreach != tt.wantBreach {
+				t.Errorf("latencyBreach = %q, want %q", res.latencyBreach, tt.wantBreach)
+			}
+			if tt.wantSnip != "" && (res.errorSnippet == nil || *res.errorSnippet != tt.wantSnip) {
+				t.Errorf("errorSnippet = %v, want %q", res.errorSnippet, tt.wantSnip)
+			}
+		})
+	}
+}
+
+func TestApplyLatencySLO_RollingP95(t *testing.T) {
+	checker := NewChecker(state.NewStore(), state.NewStore(), &mockHTTPProber{}, 0, history.NoopWriter{}, nil)
+	svc := state.Service{Name: "nextcloud", Namespace: "apps", Latency: &state.LatencySLO{DegradedAboveMs: 1000, P95: true, Window: 4}}
+
+	check := func(ms int64) probeResult {
+		res := probeResult{status: state.StatusHealthy, compositeStatus: state.StatusHealthy, responseTimeMs: ms}
+		checker.applyLatencySLO(&res, svc, true)
+		return res
+	}
+
+	// A single slow response dominates a short window's p95...
+	for _, ms := range []int64{100, 100, 100} {
+		check(ms)
+	}
+	if res := check(5000); res.status != state.StatusDegraded || res.latencyBreach != "p95=5000ms" {
+		t.Fatalf("got %q %q, want degraded with p95=5000ms", res.status, res.latencyBreach)
+	}
+	// ...until it ages out of the window.
+	for range 3 {
+		check(100)
+	}
+	if res := check(100); res.status != state.StatusHealthy || res.latencyBreach != "" {
+		t.Errorf("got %q %q, want healthy once the slow sample left the window", res.status, res.latencyBreach)
+	}
+
+	// Removing the SLO forgets the samples.
+	svc.Latency = nil
+	check(100)
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	if _, ok := checker.latency["apps/nextcloud"]; ok {
+		t.Error("expected samples dropped once the SLO is removed")
+	}
+}
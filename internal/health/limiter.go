@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// Default concurrency limits used when the Schedule leaves them unset.
+const (
+	defaultMaxConcurrency = 32
+	defaultMaxPerHost     = 4
+)
+
+// limiter bounds how many probes run at once, overall and per target host.
+type limiter struct {
+	global  chan struct{}
+	perHost int
+
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+func newLimiter(maxConcurrency, maxPerHost int) *limiter {
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+	if maxPerHost <= 0 {
+		maxPerHost = defaultMaxPerHost
+	}
+	return &limiter{
+		global:  make(chan struct{}, maxConcurrency),
+		perHost: maxPerHost,
+		hosts:   make(map[string]chan struct{}),
+	}
+}
+
+// acquire blocks until a slot for host is free, or ctx ends. The host slot
+// is taken first so a check waiting on a busy host never holds a global slot
+// that another host could use. The returned func releases both slots.
+func (l *limiter) acquire(ctx context.Context, host string) (func(), error) {
+	hostSem := l.hostSem(host)
+	select {
+	case hostSem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case l.global <- struct{}{}:
+	case <-ctx.Done():
+		<-hostSem
+		return nil, ctx.Err()
+	}
+	return func() {
+		<-l.global
+		<-hostSem
+	}, nil
+}
+
+func (l *limiter) hostSem(host string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sem, ok := l.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, l.perHost)
+		l.hosts[host] = sem
+	}
+	return sem
+}
+
+// forget drops per-host semaphores for hosts not in keep that are idle.
+func (l *limiter) forget(keep map[string]struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for host, sem := range l.hosts {
+		if _, ok := keep[host]; !ok && len(sem) == 0 {
+			delete(l.hosts, host)
+		}
+	}
+}
+
+// probeHost returns the host a service's probe connects to, used as the key
+// for per-host concurrency limits.
+func probeHost(svc state.Service) string {
+	if addr, err := probeAddress(svc); err == nil {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return strings.ToLower(host)
+		}
+		return strings.ToLower(addr)
+	}
+	if u, err := url.Parse(probeTarget(svc)); err == nil && u.Hostname() != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	return probeTarget(svc)
+}
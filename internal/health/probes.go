@@ -0,0 +1,465 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// defaultProbeTimeout bounds a probe when no timeout is configured.
+const defaultProbeTimeout = 10 * time.Second
+
+// Dialer abstracts *net.Dialer for testability.
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DNSResolver abstracts *net.Resolver for testability.
+type DNSResolver interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// defaultPorts maps URL schemes to the port used when a probe target omits one.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"tls":   "443",
+	"grpc":  "80",
+	"grpcs": "443",
+	"dns":   "53",
+}
+
+// newResolver returns a resolver that queries server ("host:port"), or the
+// system resolver when server is empty.
+func newResolver(server string) DNSResolver {
+	if server == "" {
+		return net.DefaultResolver
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// newGRPCClient returns an HTTP client that speaks HTTP/2 only: over TLS for
+// https targets and with prior knowledge (h2c) for http targets, as gRPC requires.
+func newGRPCClient() *http.Client {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Client{
+		Transport: &http.Transport{
+			Protocols: protocols,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+}
+
+// probeTarget returns the URL a service is probed against: HealthURL > URL.
+func probeTarget(svc state.Service) string {
+	if svc.HealthURL != "" {
+		return svc.HealthURL
+	}
+	return svc.URL
+}
+
+// probeType returns the configured probe type, defaulting to HTTP.
+func probeType(svc state.Service) string {
+	if svc.Probe == nil || svc.Probe.Type == "" {
+		return state.ProbeHTTP
+	}
+	return svc.Probe.Type
+}
+
+// runProbe dispatches to the probe implementation selected by the service's
+// probe type. Every implementation produces a probeResult so that composite
+// fusion, history, and notifications treat all probe types alike.
+func (c *Checker) runProbe(ctx context.Context, svc state.Service) probeResult {
+	switch probeType(svc) {
+	case state.ProbeTCP:
+		return c.probeTCP(ctx, svc)
+	case state.ProbeTLS:
+		return c.probeTLS(ctx, svc)
+	case state.ProbeDNS:
+		return c.probeDNS(ctx, svc)
+	case state.ProbeGRPC:
+		return c.probeGRPC(ctx, svc)
+	default:
+		return c.probeHTTP(ctx, probeTarget(svc), svc.Probe, assertionBodyLimit(svc.Assertions))
+	}
+}
+
+// probeAddress resolves the host:port a connection-oriented probe dials.
+// probe.address wins; otherwise the host of the probe target URL is used,
+// falling back to the scheme's default port.
+func probeAddress(svc state.Service) (string, error) {
+	if svc.Probe != nil && svc.Probe.Address != "" {
+		return svc.Probe.Address, nil
+	}
+	raw := probeTarget(svc)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid probe target %q", raw)
+	}
+	if u.Port() != "" {
+		return u.Host, nil
+	}
+	port, ok := defaultPorts[strings.ToLower(u.Scheme)]
+	if !ok {
+		return "", fmt.Errorf("no port in probe target %q", raw)
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// failedProbe builds an unhealthy probeResult carrying err as the snippet.
+func failedProbe(err error, responseTimeMs int64) probeResult {
+	return probeResult{
+		status:          state.StatusUnhealthy,
+		compositeStatus: state.StatusUnhealthy,
+		responseTimeMs:  responseTimeMs,
+		errorSnippet:    ptrString(truncateSnippet(err.Error())),
+	}
+}
+
+// healthyProbe builds a healthy probeResult.
+func healthyProbe(responseTimeMs int64) probeResult {
+	return probeResult{
+		status:          state.StatusHealthy,
+		compositeStatus: state.StatusHealthy,
+		responseTimeMs:  responseTimeMs,
+	}
+}
+
+// probeTCP checks that a TCP connection can be established.
+func (c *Checker) probeTCP(ctx context.Context, svc state.Service) probeResult {
+	addr, err := probeAddress(svc)
+	if err != nil {
+		return failedProbe(err, 0)
+	}
+
+	start := time.Now()
+	conn, err := c.dialer.DialContext(ctx, "tcp", addr)
+	responseTimeMs := time.Since(start).Milliseconds()
+	if err != nil {
+		return failedProbe(err, responseTimeMs)
+	}
+	conn.Close()
+	return healthyProbe(responseTimeMs)
+}
+
+// probeTLS checks that a TCP connection can be established and completes a
+// TLS handshake. The server name for SNI is taken from the target host.
+func (c *Checker) probeTLS(ctx context.Context, svc state.Service) probeResult {
+	addr, err := probeAddress(svc)
+	if err != nil {
+		return failedProbe(err, 0)
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return failedProbe(err, 0)
+	}
+
+	start := time.Now()
+	rawConn, err := c.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return failedProbe(err, time.Since(start).Milliseconds())
+	}
+	defer rawConn.Close()
+
+	conn := tls.Client(rawConn, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true,
+	})
+	err = conn.HandshakeContext(ctx)
+	responseTimeMs := time.Since(start).Milliseconds()
+	if err != nil {
+		return failedProbe(fmt.Errorf("tls handshake: %w", err), responseTimeMs)
+	}
+	res := healthyProbe(responseTimeMs)
+	cs := conn.ConnectionState()
+	res.tls = &cs
+	return res
+}
+
+// probeDNS resolves probe.query and, when probe.expect is set, requires every
+// expected value to appear among the answers.
+func (c *Checker) probeDNS(ctx context.Context, svc state.Service) probeResult {
+	spec := svc.Probe
+	if spec == nil || spec.Query == "" {
+		return failedProbe(errors.New("dns probe: no query configured"), 0)
+	}
+
+	server := spec.Address
+	if server == "" {
+		// A dns:// URL names the resolver to query.
+		if u, err := url.Parse(probeTarget(svc)); err == nil && strings.EqualFold(u.Scheme, "dns") {
+			server = u.Host
+		}
+	}
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(strings.Trim(server, "[]"), defaultPorts["dns"])
+		}
+	}
+
+	start := time.Now()
+	answers, err := lookupRecords(ctx, c.resolverFor(server), spec.RecordType, spec.Query)
+	responseTimeMs := time.Since(start).Milliseconds()
+	if err != nil {
+		return failedProbe(err, responseTimeMs)
+	}
+	if len(answers) == 0 {
+		return failedProbe(fmt.Errorf("dns: no %s records for %s", recordTypeOrDefault(spec.RecordType), spec.Query), responseTimeMs)
+	}
+
+	for _, want := range spec.Expect {
+		if !slices.Contains(answers, normalizeDNSAnswer(want)) {
+			return failedProbe(fmt.Errorf("dns: expected %s, got %s", want, strings.Join(answers, ",")), responseTimeMs)
+		}
+	}
+	return healthyProbe(responseTimeMs)
+}
+
+func recordTypeOrDefault(recordType string) string {
+	if recordType == "" {
+		return "A"
+	}
+	return recordType
+}
+
+// lookupRecords resolves name for the given record type and returns the
+// answers in normalized form (lower-case, without a trailing dot).
+func lookupRecords(ctx context.Context, r DNSResolver, recordType, name string) ([]string, error) {
+	var answers []string
+	switch recordTypeOrDefault(recordType) {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		mxs, err := r.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			answers = append(answers, mx.Host)
+		}
+	case "NS":
+		nss, err := r.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			answers = append(answers, ns.Host)
+		}
+	case "TXT":
+		txts, err := r.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, txts...)
+	default:
+		return nil, fmt.Errorf("dns: unsupported record type %q", recordType)
+	}
+
+	for i, a := range answers {
+		answers[i] = normalizeDNSAnswer(a)
+	}
+	return answers, nil
+}
+
+func normalizeDNSAnswer(s string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+}
+
+// gRPC health protocol constants (grpc.health.v1).
+const (
+	grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+	grpcServingStatus   = 1 // HealthCheckResponse.ServingStatus SERVING
+	maxGRPCResponseLen  = 4096
+)
+
+var grpcServingStatusNames = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+// probeGRPC performs a grpc.health.v1.Health/Check call. The protobuf
+// messages are small enough to encode by hand, which avoids pulling in the
+// gRPC runtime for a single unary call.
+func (c *Checker) probeGRPC(ctx context.Context, svc state.Service) (res probeResult) {
+	addr, err := probeAddress(svc)
+	if err != nil {
+		return failedProbe(err, 0)
+	}
+
+	scheme := "http"
+	if u, err := url.Parse(probeTarget(svc)); err == nil {
+		switch strings.ToLower(u.Scheme) {
+		case "https", "grpcs":
+			scheme = "https"
+		}
+	}
+
+	var service string
+	if svc.Probe != nil {
+		service = svc.Probe.GRPCService
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scheme+"://"+addr+grpcHealthCheckPath,
+		bytes.NewReader(encodeGRPCHealthRequest(service)))
+	if err != nil {
+		return failedProbe(err, 0)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	start := time.Now()
+	resp, err := c.grpcClient.Do(req)
+	if err != nil {
+		return failedProbe(err, time.Since(start).Milliseconds())
+	}
+	defer resp.Body.Close()
+	defer func() { res.tls = resp.TLS }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxGRPCResponseLen))
+	responseTimeMs := time.Since(start).Milliseconds()
+	if err != nil {
+		return failedProbe(err, responseTimeMs)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return failedProbe(fmt.Errorf("grpc: unexpected HTTP status %d", resp.StatusCode), responseTimeMs)
+	}
+
+	// Trailers-only responses carry grpc-status in the headers.
+	grpcStatus := resp.Trailer.Get("Grpc-Status")
+	grpcMessage := resp.Trailer.Get("Grpc-Message")
+	if grpcStatus == "" {
+		grpcStatus = resp.Header.Get("Grpc-Status")
+		grpcMessage = resp.Header.Get("Grpc-Message")
+	}
+	if grpcStatus != "" && grpcStatus != "0" {
+		msg := "grpc: status " + grpcStatus
+		if grpcMessage != "" {
+			msg += ": " + grpcMessage
+		}
+		return failedProbe(errors.New(msg), responseTimeMs)
+	}
+
+	status, err := decodeGRPCHealthResponse(body)
+	if err != nil {
+		return failedProbe(err, responseTimeMs)
+	}
+	if status != grpcServingStatus {
+		name, ok := grpcServingStatusNames[status]
+		if !ok {
+			name = strconv.FormatUint(status, 10)
+		}
+		return failedProbe(fmt.Errorf("grpc: health status %s", name), responseTimeMs)
+	}
+	return healthyProbe(responseTimeMs)
+}
+
+// encodeGRPCHealthRequest returns a length-prefixed HealthCheckRequest frame.
+func encodeGRPCHealthRequest(service string) []byte {
+	var msg []byte
+	if service != "" {
+		msg = append(msg, 0x0a) // field 1, wire type 2 (length-delimited)
+		msg = binary.AppendUvarint(msg, uint64(len(service)))
+		msg = append(msg, service...)
+	}
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// decodeGRPCHealthResponse extracts the serving status from a length-prefixed
+// HealthCheckResponse frame. A message without field 1 decodes as UNKNOWN.
+func decodeGRPCHealthResponse(frame []byte) (uint64, error) {
+	if len(frame) < 5 {
+		return 0, errors.New("grpc: empty response")
+	}
+	if frame[0] != 0 {
+		return 0, errors.New("grpc: compressed responses are not supported")
+	}
+	n := binary.BigEndian.Uint32(frame[1:5])
+	if int(n) > len(frame)-5 {
+		return 0, errors.New("grpc: truncated response")
+	}
+	msg := frame[5 : 5+n]
+
+	for len(msg) > 0 {
+		tag, k := binary.Uvarint(msg)
+		if k <= 0 {
+			return 0, errors.New("grpc: malformed response")
+		}
+		msg = msg[k:]
+		field, wireType := tag>>3, tag&0x7
+		switch wireType {
+		case 0:
+			v, k := binary.Uvarint(msg)
+			if k <= 0 {
+				return 0, errors.New("grpc: malformed response")
+			}
+			if field == 1 {
+				return v, nil
+			}
+			msg = msg[k:]
+		case 2:
+			l, k := binary.Uvarint(msg)
+			if k <= 0 || uint64(len(msg)-k) < l {
+				return 0, errors.New("grpc: malformed response")
+			}
+			msg = msg[k+int(l):]
+		default:
+			return 0, errors.New("grpc: malformed response")
+		}
+	}
+	return 0, nil
+}
+
+// truncateSnippet limits an error message to maxSnippetLen bytes.
+func truncateSnippet(s string) string {
+	if len(s) > maxSnippetLen {
+		return s[:maxSnippetLen]
+	}
+	return s
+}
@@ -0,0 +1,379 @@
+package health
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+// mockResolver is a configurable DNSResolver returning canned answers.
+type mockResolver struct {
+	ips   []net.IP
+	cname string
+	txt   []string
+	err   error
+}
+
+func (m *mockResolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	return m.ips, m.err
+}
+
+func (m *mockResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	return m.cname, m.err
+}
+
+func (m *mockResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	return nil, m.err
+}
+
+func (m *mockResolver) LookupNS(ctx context.Context, name string) ([]*net.NS, error) {
+	return nil, m.err
+}
+
+func (m *mockResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return m.txt, m.err
+}
+
+func newProbeChecker() *Checker {
+	return NewChecker(state.NewStore(), state.NewStore(), &mockHTTPProber{}, time.Hour, history.NoopWriter{}, nil)
+}
+
+func TestProbeAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     state.Service
+		want    string
+		wantErr bool
+	}{
+		{"explicit port", state.Service{URL: "tcp://db.local:5432"}, "db.local:5432", false},
+		{"https default port", state.Service{URL: "https://app.local"}, "app.local:443", false},
+		{"health url wins", state.Service{URL: "https://app.local", HealthURL: "http://app.local:8080/health"}, "app.local:8080", false},
+		{"probe address wins", state.Service{URL: "https://app.local", Probe: &state.ProbeSpec{Type: state.ProbeTCP, Address: "10.0.0.5:1883"}}, "10.0.0.5:1883", false},
+		{"unknown scheme without port", state.Service{URL: "mqtt://broker.local"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probeAddress(tt.svc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("probeAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeTCP_Healthy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	c := newProbeChecker()
+	result := c.runProbe(context.Background(), state.Service{
+		URL:   "tcp://" + ln.Addr().String(),
+		Probe: &state.ProbeSpec{Type: state.ProbeTCP},
+	})
+
+	if result.status != state.StatusHealthy {
+		t.Errorf("expected status %q, got %q (snippet %v)", state.StatusHealthy, result.status, result.errorSnippet)
+	}
+	if result.httpCode != nil {
+		t.Errorf("expected nil httpCode for tcp probe, got %d", *result.httpCode)
+	}
+}
+
+func TestProbeTCP_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	c := newProbeChecker()
+	result := c.runProbe(context.Background(), state.Service{
+		URL:   "tcp://" + addr,
+		Probe: &state.ProbeSpec{Type: state.ProbeTCP},
+	})
+
+	if result.status != state.StatusUnhealthy {
+		t.Errorf("expected status %q, got %q", state.StatusUnhealthy, result.status)
+	}
+	if result.errorSnippet == nil {
+		t.Error("expected error snippet for refused connection")
+	}
+}
+
+func TestProbeTLS_Handshake(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := newProbeChecker()
+	result := c.runProbe(context.Background(), state.Service{
+		URL:   srv.URL,
+		Probe: &state.ProbeSpec{Type: state.ProbeTLS},
+	})
+	if result.status != state.StatusHealthy {
+		t.Errorf("expected status %q, got %q (snippet %v)", state.StatusHealthy, result.status, result.errorSnippet)
+	}
+}
+
+func TestProbeTLS_PlaintextServerFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := newProbeChecker()
+	result := c.runProbe(context.Background(), state.Service{
+		URL:   srv.URL,
+		Probe: &state.ProbeSpec{Type: state.ProbeTLS},
+	})
+	if result.status != state.StatusUnhealthy {
+		t.Errorf("expected status %q, got %q", state.StatusUnhealthy, result.status)
+	}
+	if result.errorSnippet == nil || !strings.Contains(*result.errorSnippet, "tls handshake") {
+		t.Errorf("expected tls handshake error snippet, got %v", result.errorSnippet)
+	}
+}
+
+func TestProbeDNS(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolver   *mockResolver
+		spec       state.ProbeSpec
+		wantStatus state.HealthStatus
+		wantSnip   string
+	}{
+		{
+			name:       "A record matches expected",
+			resolver:   &mockResolver{ips: []net.IP{net.ParseIP("192.168.1.10")}},
+			spec:       state.ProbeSpec{Type: state.ProbeDNS, Query: "nas.home", RecordType: "A", Expect: []string{"192.168.1.10"}},
+			wantStatus: state.StatusHealthy,
+		},
+		{
+			name:       "A record without expectation",
+			resolver:   &mockResolver{ips: []net.IP{net.ParseIP("192.168.1.10")}},
+			spec:       state.ProbeSpec{Type: state.ProbeDNS, Query: "nas.home"},
+			wantStatus: state.StatusHealthy,
+		},
+		{
+			name:       "A record mismatch",
+			resolver:   &mockResolver{ips: []net.IP{net.ParseIP("10.0.0.1")}},
+			spec:       state.ProbeSpec{Type: state.ProbeDNS, Query: "nas.home", RecordType: "A", Expect: []string{"192.168.1.10"}},
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   "dns: expected 192.168.1.10, got 10.0.0.1",
+		},
+		{
+			name:       "CNAME comparison ignores case and trailing dot",
+			resolver:   &mockResolver{cname: "Edge.Example.com."},
+			spec:       state.ProbeSpec{Type: state.ProbeDNS, Query: "www.example.com", RecordType: "CNAME", Expect: []string{"edge.example.com"}},
+			wantStatus: state.StatusHealthy,
+		},
+		{
+			name:       "no answers",
+			resolver:   &mockResolver{},
+			spec:       state.ProbeSpec{Type: state.ProbeDNS, Query: "nas.home", RecordType: "TXT"},
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   "dns: no TXT records for nas.home",
+		},
+		{
+			name:       "lookup error",
+			resolver:   &mockResolver{err: errors.New("server misbehaving")},
+			spec:       state.ProbeSpec{Type: state.ProbeDNS, Query: "nas.home"},
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   "server misbehaving",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newProbeChecker()
+			var gotServer string
+			c.resolverFor = func(server string) DNSResolver {
+				gotServer = server
+				return tt.resolver
+			}
+			spec := tt.spec
+			result := c.runProbe(context.Background(), state.Service{URL: "dns://192.168.1.1", Probe: &spec})
+
+			if result.status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q (snippet %v)", tt.wantStatus, result.status, result.errorSnippet)
+			}
+			if tt.wantSnip != "" && (result.errorSnippet == nil || *result.errorSnippet != tt.wantSnip) {
+				t.Errorf("expected snippet %q, got %v", tt.wantSnip, result.errorSnippet)
+			}
+			if gotServer != "192.168.1.1:53" {
+				t.Errorf("expected resolver 192.168.1.1:53, got %q", gotServer)
+			}
+		})
+	}
+}
+
+// grpcHealthHandler answers grpc.health.v1.Health/Check with the given status.
+func grpcHealthHandler(t *testing.T, servingStatus byte, grpcStatus string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != grpcHealthCheckPath {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.ProtoMajor != 2 {
+			t.Errorf("expected HTTP/2 request, got %s", r.Proto)
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		if grpcStatus == "0" {
+			w.Write([]byte{0, 0, 0, 0, 2, 0x08, servingStatus})
+		}
+		w.Header().Set("Grpc-Status", grpcStatus)
+	}
+}
+
+func TestProbeGRPC_ServingOverTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(grpcHealthHandler(t, 1, "0"))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	c := newProbeChecker()
+	result := c.runProbe(context.Background(), state.Service{
+		URL:   srv.URL,
+		Probe: &state.ProbeSpec{Type: state.ProbeGRPC},
+	})
+	if result.status != state.StatusHealthy {
+		t.Errorf("expected status %q, got %q (snippet %v)", state.StatusHealthy, result.status, result.errorSnippet)
+	}
+}
+
+func TestProbeGRPC_NotServingOverH2C(t *testing.T) {
+	srv := httptest.NewUnstartedServer(grpcHealthHandler(t, 2, "0"))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	c := newProbeChecker()
+	result := c.runProbe(context.Background(), state.Service{
+		URL:   "grpc://" + srv.Listener.Addr().String(),
+		Probe: &state.ProbeSpec{Type: state.ProbeGRPC, GRPCService: "mqtt"},
+	})
+	if result.status != state.StatusUnhealthy {
+		t.Errorf("expected status %q, got %q", state.StatusUnhealthy, result.status)
+	}
+	if result.errorSnippet == nil || *result.errorSnippet != "grpc: health status NOT_SERVING" {
+		t.Errorf("unexpected snippet %v", result.errorSnippet)
+	}
+}
+
+func TestProbeGRPC_ErrorStatus(t *testing.T) {
+	srv := httptest.NewUnstartedServer(grpcHealthHandler(t, 0, "12"))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	c := newProbeChecker()
+	result := c.runProbe(context.Background(), state.Service{
+		URL:   srv.URL,
+		Probe: &state.ProbeSpec{Type: state.ProbeGRPC},
+	})
+	if result.status != state.StatusUnhealthy {
+		t.Errorf("expected status %q, got %q", state.StatusUnhealthy, result.status)
+	}
+	if result.errorSnippet == nil || !strings.HasPrefix(*result.errorSnippet, "grpc: status 12") {
+		t.Errorf("unexpected snippet %v", result.errorSnippet)
+	}
+}
+
+func TestEncodeGRPCHealthRequest(t *testing.T) {
+	frame := encodeGRPCHealthRequest("mqtt")
+	if frame[0] != 0 {
+		t.Errorf("expected uncompressed flag, got %d", frame[0])
+	}
+	if n := binary.BigEndian.Uint32(frame[1:5]); n != 6 {
+		t.Errorf("expected message length 6, got %d", n)
+	}
+	if got := string(frame[5:]); got != "\x0a\x04mqtt" {
+		t.Errorf("unexpected message bytes %q", got)
+	}
+
+	if empty := encodeGRPCHealthRequest(""); len(empty) != 5 {
+		t.Errorf("expected bare 5-byte frame for empty service, got %d bytes", len(empty))
+	}
+}
+
+func TestDecodeGRPCHealthResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   []byte
+		want    uint64
+		wantErr bool
+	}{
+		{"serving", []byte{0, 0, 0, 0, 2, 0x08, 1}, 1, false},
+		{"empty message is unknown", []byte{0, 0, 0, 0, 0}, 0, false},
+		{"skips unknown fields", []byte{0, 0, 0, 0, 5, 0x12, 1, 'x', 0x08, 2}, 2, false},
+		{"truncated", []byte{0, 0, 0, 0, 9, 0x08}, 0, true},
+		{"compressed", []byte{1, 0, 0, 0, 0}, 0, true},
+		{"too short", []byte{0, 0}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeGRPCHealthResponse(tt.frame)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckService_NonHTTPProbeFeedsCompositeFusion(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "postgres", Namespace: "db", URL: "tcp://" + addr,
+		Status: state.StatusUnknown,
+		Probe:  &state.ProbeSpec{Type: state.ProbeTCP},
+	})
+
+	client := &mockHTTPProber{}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	checker.SetEndpointReader(&mockEndpointReader{data: map[string]*EndpointReadiness{
+		"db/postgres": {Ready: 1, Total: 1},
+	}})
+	checkEach(checker)
+
+	svc, _ := store.Get("db", "postgres")
+	// Refused TCP connection + ready endpoints fuses to degraded.
+	if svc.CompositeStatus != state.StatusDegraded {
+		t.Errorf("expected composite status %q, got %q", state.StatusDegraded, svc.CompositeStatus)
+	}
+	if len(client.getCapturedRequests()) != 0 {
+		t.Error("expected no HTTP requests for a tcp probe")
+	}
+}
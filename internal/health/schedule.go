@@ -0,0 +1,255 @@
+package health
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// scheduleResolution is how often Run looks for services that are due.
+// Intervals are at least 1s, so a finer resolution gains nothing.
+const scheduleResolution = time.Second
+
+// overrunLogInterval rate-limits overrun warnings.
+const overrunLogInterval = time.Minute
+
+// Timing holds a check interval and probe timeout. Zero fields inherit from
+// the next level of the Schedule.
+type Timing struct {
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+// Schedule resolves per-service check timing. Precedence is the service's
+// own CheckInterval/CheckTimeout, then its group's entry, then Default.
+// MaxConcurrency and MaxPerHost cap how many probes run at once, overall and
+// against a single host; zero uses the defaults (32 and 4).
+type Schedule struct {
+	Default        Timing
+	Groups         map[string]Timing
+	MaxConcurrency int
+	MaxPerHost     int
+}
+
+// intervalFor returns the effective check interval for svc.
+func (s Schedule) intervalFor(svc state.Service) time.Duration {
+	if svc.CheckInterval > 0 {
+		return svc.CheckInterval
+	}
+	if g, ok := s.Groups[svc.Group]; ok && g.Interval > 0 {
+		return g.Interval
+	}
+	return s.Default.Interval
+}
+
+// timeoutFor returns the effective probe timeout for svc.
+func (s Schedule) timeoutFor(svc state.Service) time.Duration {
+	if svc.CheckTimeout > 0 {
+		return svc.CheckTimeout
+	}
+	if g, ok := s.Groups[svc.Group]; ok && g.Timeout > 0 {
+		return g.Timeout
+	}
+	if s.Default.Timeout > 0 {
+		return s.Default.Timeout
+	}
+	return defaultProbeTimeout
+}
+
+// SetSchedule replaces the check schedule, e.g. after a config reload.
+// Services are re-evaluated against their new intervals immediately, measured
+// from their last check, so shortened intervals take effect without waiting
+// out the old one.
+func (c *Checker) SetSchedule(s Schedule) {
+	c.mu.Lock()
+	if s.MaxConcurrency != c.schedule.MaxConcurrency || s.MaxPerHost != c.schedule.MaxPerHost {
+		// Checks already holding slots release them to the old limiter.
+		c.limiter = newLimiter(s.MaxConcurrency, s.MaxPerHost)
+	}
+	c.schedule = s
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Checker) currentSchedule() Schedule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.schedule
+}
+
+// nextDue returns when a service last checked at last is due again. Each
+// service has a fixed phase within its interval, derived from a hash of key,
+// so checks of many services with the same interval are spread evenly rather
+// than firing together. The result is the first slot of that phase at least
+// half an interval after last, which keeps a late or rescheduled check from
+// running twice in quick succession.
+func nextDue(key string, last time.Time, interval time.Duration) time.Time {
+	if interval <= 0 {
+		return last
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	phase := int64(h.Sum64() % uint64(interval))
+
+	base := last.Add(interval / 2)
+	rem := (base.UnixNano() - phase) % int64(interval)
+	if rem < 0 {
+		rem += int64(interval)
+	}
+	if rem == 0 {
+		return base
+	}
+	return base.Add(interval - time.Duration(rem))
+}
+
+// runDue queues a check for every service whose next slot has arrived, in
+// due order. Checks then wait on the concurrency limiter, so at most
+// MaxConcurrency probes (and MaxPerHost per host) run at once. A service
+// whose previous check is still running when it falls due again is an
+// overrun: it is skipped, never stacked, and reported.
+func (c *Checker) runDue(ctx context.Context, wg *sync.WaitGroup) {
+	services := c.reader.All()
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	type dueCheck struct {
+		svc state.Service
+		key string
+		at  time.Time
+	}
+	var (
+		due     []dueCheck
+		overrun []string
+		seen    = make(map[string]struct{}, len(services))
+		hosts   = make(map[string]struct{})
+	)
+	for _, svc := range services {
+		key := svc.Key()
+		seen[key] = struct{}{}
+		if svc.Restored {
+			// Its probe configuration arrives with rediscovery.
+			continue
+		}
+		if svc.Site != "" {
+			// Federated: its site checks it.
+			continue
+		}
+		hosts[probeHost(svc)] = struct{}{}
+
+		at := now
+		if last, ok := c.lastStart[key]; ok {
+			at = nextDue(key, last, c.schedule.intervalFor(svc))
+		}
+		if at.After(now) {
+			continue
+		}
+		if _, running := c.inflight[key]; running {
+			if _, reported := c.overrun[key]; !reported {
+				c.overrun[key] = struct{}{}
+				c.overruns++
+				overrun = append(overrun, key)
+			}
+			continue
+		}
+		due = append(due, dueCheck{svc: svc, key: key, at: at})
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].at.Equal(due[j].at) {
+			return due[i].at.Before(due[j].at)
+		}
+		return due[i].key < due[j].key
+	})
+
+	lim := c.limiter
+	var cycle sync.WaitGroup
+	for _, d := range due {
+		c.lastStart[d.key] = now
+		done := make(chan struct{})
+		c.inflight[d.key] = done
+		wg.Add(1)
+		cycle.Add(1)
+		go func(s state.Service, key string) {
+			defer wg.Done()
+			defer cycle.Done()
+			c.runCheck(ctx, lim, s)
+			c.finishCheck(key, done)
+		}(d.svc, d.key)
+	}
+	if len(due) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cycle.Wait()
+			c.currentMetrics().ObserveCycle(time.Since(now))
+		}()
+	}
+
+	// Forget services that are gone so a re-added service is checked at once.
+	for key := range c.lastStart {
+		if _, ok := seen[key]; !ok {
+			delete(c.lastStart, key)
+			delete(c.flaps, key)
+			delete(c.latency, key)
+		}
+	}
+	lim.forget(hosts)
+
+	if len(due) > 0 {
+		c.logger.Debug("health checks queued", "services", len(due))
+	}
+	if len(overrun) > 0 && now.Sub(c.lastOverrunLog) >= overrunLogInterval {
+		c.lastOverrunLog = now
+		c.logger.Warn("health check overrun: previous check still running when next was due",
+			"services", len(overrun),
+			"example", overrun[0],
+			"totalOverruns", c.overruns,
+		)
+	}
+}
+
+// runCheck waits for a limiter slot, then checks the service's current state
+// (it may have changed or been removed while queued). Services restored from
+// a snapshot are not checked until their source rediscovers them.
+func (c *Checker) runCheck(ctx context.Context, lim *limiter, s state.Service) {
+	release, err := lim.acquire(ctx, probeHost(s))
+	if err != nil {
+		return
+	}
+	defer release()
+
+	current, ok := c.reader.Get(s.ScopedNamespace(), s.Name)
+	if !ok || current.Restored {
+		return
+	}
+	c.checkService(ctx, current)
+}
+
+// finishCheck marks the check of key started with done as finished.
+func (c *Checker) finishCheck(key string, done chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inflight[key] == done {
+		delete(c.inflight, key)
+		delete(c.overrun, key)
+	}
+	close(done)
+}
+
+// Overruns returns how many times a service fell due while its previous
+// check was still running.
+func (c *Checker) Overruns() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.overruns
+}
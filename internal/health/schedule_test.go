@@ -0,0 +1,316 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+func TestSchedule_Precedence(t *testing.T) {
+	schedule := Schedule{
+		Default: Timing{Interval: 30 * time.Second, Timeout: 10 * time.Second},
+		Groups: map[string]Timing{
+			"media": {Interval: 5 * time.Minute},
+			"infra": {Timeout: 2 * time.Second},
+		},
+	}
+	tests := []struct {
+		name         string
+		svc          state.Service
+		wantInterval time.Duration
+		wantTimeout  time.Duration
+	}{
+		{"default", state.Service{Group: "apps"}, 30 * time.Second, 10 * time.Second},
+		{"group interval", state.Service{Group: "media"}, 5 * time.Minute, 10 * time.Second},
+		{"group timeout", state.Service{Group: "infra"}, 30 * time.Second, 2 * time.Second},
+		{
+			"service overrides group",
+			state.Service{Group: "media", CheckInterval: 15 * time.Second, CheckTimeout: 3 * time.Second},
+			15 * time.Second, 3 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schedule.intervalFor(tt.svc); got != tt.wantInterval {
+				t.Errorf("intervalFor = %v, want %v", got, tt.wantInterval)
+			}
+			if got := schedule.timeoutFor(tt.svc); got != tt.wantTimeout {
+				t.Errorf("timeoutFor = %v, want %v", got, tt.wantTimeout)
+			}
+		})
+	}
+
+	if got := (Schedule{}).timeoutFor(state.Service{}); got != defaultProbeTimeout {
+		t.Errorf("empty schedule timeout = %v, want %v", got, defaultProbeTimeout)
+	}
+}
+
+// blockingProber holds every request until release is closed.
+type blockingProber struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	release chan struct{}
+}
+
+func (b *blockingProber) Do(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	b.calls[req.URL.String()]++
+	b.mu.Unlock()
+	<-b.release
+	return (&mockHTTPProber{responses: map[string]mockResponse{
+		req.URL.String(): {statusCode: 200, body: "OK"},
+	}}).Do(req)
+}
+
+func (b *blockingProber) count(url string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[url]
+}
+
+func TestRunDue_HonorsIntervalAndSkipsInflight(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "fast", Namespace: "ns", URL: "https://fast.example.com", CheckInterval: time.Second})
+	store.AddOrUpdate(state.Service{Name: "slow", Namespace: "ns", URL: "https://slow.example.com"})
+
+	client := &blockingProber{calls: make(map[string]int), release: make(chan struct{})}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	var wg sync.WaitGroup
+	checker.runDue(context.Background(), &wg)
+
+	// Both checks are still running: nothing may start twice.
+	checker.runDue(context.Background(), &wg)
+	close(client.release)
+	wg.Wait()
+
+	if got := client.count("https://fast.example.com"); got != 1 {
+		t.Errorf("fast checked %d times while in flight, want 1", got)
+	}
+
+	// Once the fast service's interval elapses it is due again; the slow one
+	// (1h default) is not.
+	checker.mu.Lock()
+	for key := range checker.lastStart {
+		checker.lastStart[key] = checker.lastStart[key].Add(-2 * time.Second)
+	}
+	checker.mu.Unlock()
+	checker.runDue(context.Background(), &wg)
+	wg.Wait()
+
+	if got := client.count("https://fast.example.com"); got != 2 {
+		t.Errorf("fast checked %d times, want 2", got)
+	}
+	if got := client.count("https://slow.example.com"); got != 1 {
+		t.Errorf("slow checked %d times, want 1", got)
+	}
+}
+
+func TestRunDue_ForgetsRemovedServices(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "app", Namespace: "ns", URL: "https://app.example.com"})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://app.example.com": {statusCode: 200, body: "OK"},
+	}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	var wg sync.WaitGroup
+	checker.runDue(context.Background(), &wg)
+	wg.Wait()
+
+	store.Remove("ns", "app")
+	checker.runDue(context.Background(), &wg)
+	wg.Wait()
+
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	if _, ok := checker.lastStart["ns/app"]; ok {
+		t.Error("expected lastStart entry for removed service to be pruned")
+	}
+}
+
+func TestCheckService_UsesScheduledTimeout(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{
+		Name: "hung", Namespace: "ns", URL: "https://hung.example.com",
+		CheckTimeout: 50 * time.Millisecond,
+	})
+
+	client := &blockingProber{calls: make(map[string]int), release: make(chan struct{})}
+	defer close(client.release)
+	checker := NewChecker(store, store, &contextProber{inner: client}, time.Hour, history.NoopWriter{}, nil)
+
+	svc, _ := store.Get("ns", "hung")
+	start := time.Now()
+	checker.checkService(context.Background(), svc)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("check took %v, expected the 50ms service timeout to apply", elapsed)
+	}
+
+	got, _ := store.Get("ns", "hung")
+	if got.Status != state.StatusUnhealthy {
+		t.Errorf("expected status %q, got %q", state.StatusUnhealthy, got.Status)
+	}
+}
+
+// contextProber returns as soon as the request context ends, like http.Client.
+type contextProber struct {
+	inner HTTPProber
+}
+
+func (p *contextProber) Do(req *http.Request) (*http.Response, error) {
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		resp, err := p.inner.Do(req)
+		ch <- result{resp, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.resp, r.err
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+func TestSetSchedule_WakesRun(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "app", Namespace: "ns", URL: "https://app.example.com"})
+
+	client := &mockHTTPProber{responses: map[string]mockResponse{
+		"https://app.example.com": {statusCode: 200, body: "OK"},
+	}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	checker.SetSchedule(Schedule{Default: Timing{Interval: time.Second}})
+
+	if got := checker.currentSchedule().Default.Interval; got != time.Second {
+		t.Errorf("schedule interval = %v, want 1s", got)
+	}
+	select {
+	case <-checker.wake:
+	default:
+		t.Error("expected SetSchedule to signal wake")
+	}
+}
+
+func TestNextDue_SpreadsServicesAcrossInterval(t *testing.T) {
+	interval := time.Minute
+	last := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	offsets := make(map[time.Duration]struct{})
+	for i := range 20 {
+		key := "ns/svc-" + string(rune('a'+i))
+		due := nextDue(key, last, interval)
+		if d := due.Sub(last); d < interval/2 || d >= interval/2+interval {
+			t.Fatalf("%s: next check %v after last, want within [30s, 90s)", key, d)
+		}
+		// The same key always lands on the same phase.
+		if again := nextDue(key, due, interval); again.Sub(due) != interval {
+			t.Errorf("%s: steady-state spacing = %v, want %v", key, again.Sub(due), interval)
+		}
+		offsets[due.Sub(last)] = struct{}{}
+	}
+	if len(offsets) < 10 {
+		t.Errorf("expected due times to be spread out, got %d distinct offsets for 20 services", len(offsets))
+	}
+}
+
+func TestLimiter_BoundsGlobalAndPerHost(t *testing.T) {
+	lim := newLimiter(3, 2)
+	ctx := context.Background()
+
+	var releases []func()
+	for _, host := range []string{"a", "a", "b"} {
+		release, err := lim.acquire(ctx, host)
+		if err != nil {
+			t.Fatalf("acquire(%s): %v", host, err)
+		}
+		releases = append(releases, release)
+	}
+
+	// Host "a" is at its limit, and the global limit is reached.
+	for _, host := range []string{"a", "c"} {
+		waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		if _, err := lim.acquire(waitCtx, host); err == nil {
+			t.Errorf("acquire(%s) succeeded past the limit", host)
+		}
+		cancel()
+	}
+
+	releases[2]() // frees a global slot from host "b"
+	release, err := lim.acquire(ctx, "c")
+	if err != nil {
+		t.Fatalf("acquire(c) after release: %v", err)
+	}
+	release()
+}
+
+func TestRunDue_BoundsConcurrentProbes(t *testing.T) {
+	store := state.NewStore()
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		store.AddOrUpdate(state.Service{Name: name, Namespace: "ns", URL: "https://" + name + ".example.com"})
+	}
+
+	client := &blockingProber{calls: make(map[string]int), release: make(chan struct{})}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	checker.SetSchedule(Schedule{Default: Timing{Interval: time.Hour}, MaxConcurrency: 2})
+
+	var wg sync.WaitGroup
+	checker.runDue(context.Background(), &wg)
+	time.Sleep(50 * time.Millisecond)
+
+	client.mu.Lock()
+	started := 0
+	for _, n := range client.calls {
+		started += n
+	}
+	client.mu.Unlock()
+	if started != 2 {
+		t.Errorf("expected 2 probes running under MaxConcurrency=2, got %d", started)
+	}
+
+	close(client.release)
+	wg.Wait()
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		if got := client.count("https://" + name + ".example.com"); got != 1 {
+			t.Errorf("%s checked %d times, want 1", name, got)
+		}
+	}
+}
+
+func TestRunDue_DetectsOverrun(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "slow", Namespace: "ns", URL: "https://slow.example.com", CheckInterval: time.Second})
+
+	client := &blockingProber{calls: make(map[string]int), release: make(chan struct{})}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+
+	var wg sync.WaitGroup
+	checker.runDue(context.Background(), &wg)
+
+	// Make the running check overdue, twice: it is reported once.
+	for range 2 {
+		checker.mu.Lock()
+		checker.lastStart["ns/slow"] = time.Now().Add(-5 * time.Second)
+		checker.mu.Unlock()
+		checker.runDue(context.Background(), &wg)
+	}
+
+	if got := checker.Overruns(); got != 1 {
+		t.Errorf("Overruns() = %d, want 1", got)
+	}
+	close(client.release)
+	wg.Wait()
+	if got := client.count("https://slow.example.com"); got != 1 {
+		t.Errorf("overrunning check was stacked: %d probes", got)
+	}
+}
@@ -0,0 +1,137 @@
+package health
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// defaultCertWarningWindow is how long before expiry a certificate turns a
+// service degraded when TLSPolicy leaves WarningWindow unset.
+const defaultCertWarningWindow = 14 * 24 * time.Hour
+
+// TLSPolicy controls how certificates presented by probed services are
+// judged. Probes always complete the handshake so the service itself can be
+// checked; the certificate is verified separately against Roots (nil means
+// the system roots).
+type TLSPolicy struct {
+	WarningWindow     time.Duration
+	Roots             *x509.CertPool
+	RequireValidChain bool // an unverifiable chain makes the service unhealthy
+}
+
+// SetTLSPolicy replaces the certificate policy, e.g. after a config reload.
+func (c *Checker) SetTLSPolicy(p TLSPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tlsPolicy = p
+}
+
+func (c *Checker) currentTLSPolicy() TLSPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tlsPolicy
+}
+
+// LoadCertPool returns the system roots plus the PEM certificates in caFile.
+func LoadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// inspectCertificate describes the leaf certificate of a completed handshake
+// and verifies its chain for serverName. It returns nil when the peer sent no
+// certificate.
+func inspectCertificate(cs *tls.ConnectionState, serverName string, roots *x509.CertPool, now time.Time) *state.TLSCertInfo {
+	if cs == nil || len(cs.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := cs.PeerCertificates[0]
+	info := &state.TLSCertInfo{
+		Subject:  leaf.Subject.String(),
+		Issuer:   leaf.Issuer.String(),
+		NotAfter: leaf.NotAfter,
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	if serverName == "" {
+		serverName = cs.ServerName
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		info.ChainError = err.Error()
+	} else {
+		info.ChainValid = true
+	}
+	return info
+}
+
+// applyCertPolicy downgrades res according to its certificate: unhealthy once
+// expired (or, with RequireValidChain, when the chain does not verify), and
+// degraded inside the warning window. A status that is already worse is kept.
+func applyCertPolicy(res *probeResult, policy TLSPolicy, now time.Time) {
+	cert := res.tlsCert
+	if cert == nil {
+		return
+	}
+	window := policy.WarningWindow
+	if window <= 0 {
+		window = defaultCertWarningWindow
+	}
+
+	var (
+		status state.HealthStatus
+		msg    string
+	)
+	switch {
+	case !now.Before(cert.NotAfter):
+		status = state.StatusUnhealthy
+		msg = fmt.Sprintf("tls: certificate expired %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	case policy.RequireValidChain && !cert.ChainValid:
+		status = state.StatusUnhealthy
+		msg = "tls: " + cert.ChainError
+	case cert.NotAfter.Sub(now) <= window:
+		status = state.StatusDegraded
+		msg = fmt.Sprintf("tls: certificate expires in %s (%s)",
+			formatDays(cert.NotAfter.Sub(now)), cert.NotAfter.UTC().Format(time.RFC3339))
+	default:
+		return
+	}
+
+	if res.status == state.StatusUnhealthy || (res.status == state.StatusDegraded && status == state.StatusDegraded) {
+		return
+	}
+	res.status = status
+	res.compositeStatus = status
+	res.errorSnippet = ptrString(truncateSnippet(msg))
+}
+
+// formatDays renders a duration as whole days, or hours below one day.
+func formatDays(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(d/time.Hour))
+}
@@ -0,0 +1,215 @@
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/history"
+	"github.com/rathix/command-center/internal/state"
+)
+
+// testChain returns a CA certificate and a leaf for host signed by it.
+func testChain(t *testing.T, host string, notAfter time.Time) (ca, leaf *x509.Certificate) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ = x509.ParseCertificate(caDER)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTmpl, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ = x509.ParseCertificate(leafDER)
+	return ca, leaf
+}
+
+func TestInspectCertificate(t *testing.T) {
+	now := time.Now()
+	ca, leaf := testChain(t, "nas.home", now.Add(30*24*time.Hour))
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	cs := &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}
+
+	info := inspectCertificate(cs, "nas.home", roots, now)
+	if info == nil || !info.ChainValid {
+		t.Fatalf("expected valid chain against configured CA, got %+v", info)
+	}
+	if info.Issuer != "CN=Test CA" || info.Subject != "CN=nas.home" || !info.NotAfter.Equal(leaf.NotAfter) {
+		t.Errorf("unexpected certificate details: %+v", info)
+	}
+
+	if info := inspectCertificate(cs, "nas.home", x509.NewCertPool(), now); info.ChainValid || info.ChainError == "" {
+		t.Errorf("expected unknown authority error, got %+v", info)
+	}
+	if info := inspectCertificate(cs, "other.home", roots, now); info.ChainValid || !strings.Contains(info.ChainError, "other.home") {
+		t.Errorf("expected host name mismatch, got %+v", info)
+	}
+	if info := inspectCertificate(&tls.ConnectionState{}, "nas.home", roots, now); info != nil {
+		t.Errorf("expected nil without peer certificates, got %+v", info)
+	}
+}
+
+func TestApplyCertPolicy(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		status     state.HealthStatus
+		cert       state.TLSCertInfo
+		policy     TLSPolicy
+		wantStatus state.HealthStatus
+		wantSnip   string
+	}{
+		{
+			name:       "valid and far from expiry",
+			status:     state.StatusHealthy,
+			cert:       state.TLSCertInfo{NotAfter: now.Add(90 * 24 * time.Hour), ChainValid: true},
+			wantStatus: state.StatusHealthy,
+		},
+		{
+			name:       "inside default warning window",
+			status:     state.StatusHealthy,
+			cert:       state.TLSCertInfo{NotAfter: now.Add(5*24*time.Hour + time.Hour), ChainValid: true},
+			wantStatus: state.StatusDegraded,
+			wantSnip:   "tls: certificate expires in 5d",
+		},
+		{
+			name:       "custom warning window",
+			status:     state.StatusHealthy,
+			cert:       state.TLSCertInfo{NotAfter: now.Add(20 * 24 * time.Hour), ChainValid: true},
+			policy:     TLSPolicy{WarningWindow: 30 * 24 * time.Hour},
+			wantStatus: state.StatusDegraded,
+			wantSnip:   "tls: certificate expires in",
+		},
+		{
+			name:       "expired",
+			status:     state.StatusHealthy,
+			cert:       state.TLSCertInfo{NotAfter: now.Add(-time.Hour)},
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   "tls: certificate expired",
+		},
+		{
+			name:       "invalid chain only recorded by default",
+			status:     state.StatusHealthy,
+			cert:       state.TLSCertInfo{NotAfter: now.Add(90 * 24 * time.Hour), ChainError: "x509: certificate signed by unknown authority"},
+			wantStatus: state.StatusHealthy,
+		},
+		{
+			name:       "invalid chain when required",
+			status:     state.StatusHealthy,
+			cert:       state.TLSCertInfo{NotAfter: now.Add(90 * 24 * time.Hour), ChainError: "x509: certificate signed by unknown authority"},
+			policy:     TLSPolicy{RequireValidChain: true},
+			wantStatus: state.StatusUnhealthy,
+			wantSnip:   "tls: x509: certificate signed by unknown authority",
+		},
+		{
+			name:       "already unhealthy is kept",
+			status:     state.StatusUnhealthy,
+			cert:       state.TLSCertInfo{NotAfter: now.Add(-time.Hour)},
+			wantStatus: state.StatusUnhealthy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := tt.cert
+			res := probeResult{status: tt.status, compositeStatus: tt.status, tlsCert: &cert}
+			applyCertPolicy(&res, tt.policy, now)
+			if res.status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", res.status, tt.wantStatus)
+			}
+			if tt.wantSnip != "" && (res.errorSnippet == nil || !strings.HasPrefix(*res.errorSnippet, tt.wantSnip)) {
+				t.Errorf("snippet = %v, want prefix %q", res.errorSnippet, tt.wantSnip)
+			}
+		})
+	}
+}
+
+func TestLoadCertPool(t *testing.T) {
+	ca, leaf := testChain(t, "nas.home", time.Now().Add(24*time.Hour))
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := LoadCertPool(path)
+	if err != nil {
+		t.Fatalf("LoadCertPool: %v", err)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "nas.home", Roots: pool}); err != nil {
+		t.Errorf("leaf does not verify against loaded pool: %v", err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("not a certificate"), 0o600)
+	if _, err := LoadCertPool(empty); err == nil {
+		t.Error("expected error for file without certificates")
+	}
+}
+
+func TestCheckService_RecordsTLSCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "web", Namespace: "ns", URL: srv.URL, Status: state.StatusUnknown})
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	checker := NewChecker(store, store, client, time.Hour, history.NoopWriter{}, nil)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	checker.SetTLSPolicy(TLSPolicy{Roots: roots})
+
+	svc, _ := store.Get("ns", "web")
+	checker.checkService(context.Background(), svc)
+
+	svc, _ = store.Get("ns", "web")
+	if svc.TLSCert == nil {
+		t.Fatal("expected TLS certificate details on service")
+	}
+	if !svc.TLSCert.ChainValid {
+		t.Errorf("expected valid chain against test server CA, got error %q", svc.TLSCert.ChainError)
+	}
+	if svc.Status != state.StatusHealthy {
+		t.Errorf("status = %q, want healthy", svc.Status)
+	}
+}
@@ -27,6 +27,7 @@ type PendingHistory struct {
 
 // ApplyIfPending checks whether the given service has a pending history record.
 // If so, it applies the record to the store and removes it from the pending set.
+// namespace is the service's scoped namespace.
 func (p *PendingHistory) ApplyIfPending(store StateWriter, namespace, name string) {
 	key := namespace + "/" + name
 	p.mu.Lock()
@@ -63,6 +64,7 @@ func (p *PendingHistory) ApplyIfPending(store StateWriter, namespace, name strin
 }
 
 // ReadHistory reads a JSONL history file and returns the latest TransitionRecord per service key.
+// Keys are store keys: a record's ServiceKey, qualified by its Cluster if set.
 // If the file does not exist, it returns an empty map with no error.
 func ReadHistory(path string) (map[string]TransitionRecord, error) {
 	f, err := os.Open(path)
@@ -94,8 +96,12 @@ func readRecords(r io.Reader, logger *slog.Logger) (map[string]TransitionRecord,
 			continue
 		}
 
-		if existing, ok := records[rec.ServiceKey]; !ok || rec.Timestamp.After(existing.Timestamp) {
-			records[rec.ServiceKey] = rec
+		key := rec.ServiceKey
+		if rec.Cluster != "" {
+			key = rec.Cluster + "/" + key
+		}
+		if existing, ok := records[key]; !ok || rec.Timestamp.After(existing.Timestamp) {
+			records[key] = rec
 		}
 	}
 
@@ -149,15 +155,18 @@ func RestoreHistory(store StateWriter, records map[string]TransitionRecord, logg
 	return pending
 }
 
-// splitServiceKey splits "namespace/name" into its components.
-// Returns false if the key does not contain a slash.
+// splitServiceKey splits "namespace/name" or "cluster/namespace/name" into
+// the scoped namespace and name. Returns false if the key is malformed.
 func splitServiceKey(key string) (namespace, name string, ok bool) {
-	idx := strings.Index(key, "/")
-	if idx <= 0 || idx >= len(key)-1 {
+	parts := strings.Split(key, "/")
+	if len(parts) < 2 || len(parts) > 3 {
 		return "", "", false
 	}
-	if strings.Contains(key[idx+1:], "/") {
-		return "", "", false
+	for _, p := range parts {
+		if p == "" {
+			return "", "", false
+		}
 	}
+	idx := strings.LastIndex(key, "/")
 	return key[:idx], key[idx+1:], true
 }
@@ -23,7 +23,7 @@ func newMockStateWriter() *mockStateWriter {
 func (m *mockStateWriter) Add(svc state.Service) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.services[svc.Namespace+"/"+svc.Name] = svc
+	m.services[svc.Key()] = svc
 }
 
 func (m *mockStateWriter) Get(namespace, name string) (state.Service, bool) {
@@ -117,6 +117,40 @@ func TestReadHistory_LatestRecordWins(t *testing.T) {
 	}
 }
 
+func TestReadHistory_KeysByCluster(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.jsonl")
+
+	content := `{"ts":"2025-01-01T10:00:00Z","svc":"media/jellyfin","prev":"unknown","next":"healthy"}
+{"ts":"2025-01-01T11:00:00Z","cluster":"lab","svc":"media/jellyfin","prev":"unknown","next":"unhealthy"}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadHistory(path)
+	if err != nil {
+		t.Fatalf("ReadHistory returned error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if rec := records["lab/media/jellyfin"]; rec.Cluster != "lab" || rec.NextStatus != state.StatusUnhealthy {
+		t.Errorf("lab record = %+v", rec)
+	}
+
+	store := newMockStateWriter()
+	store.Add(state.Service{Name: "jellyfin", Namespace: "media", Cluster: "lab", Status: state.StatusUnknown})
+	pending := RestoreHistory(store, records, nil)
+	if svc, _ := store.Get("lab/media", "jellyfin"); svc.Status != state.StatusUnhealthy {
+		t.Errorf("lab service status = %q, want %q", svc.Status, state.StatusUnhealthy)
+	}
+	if len(pending.pending) != 1 {
+		t.Errorf("expected the unclustered record pending, got %d", len(pending.pending))
+	}
+}
+
 func TestReadHistory_MalformedLinesSkipped(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
@@ -341,7 +375,9 @@ func TestSplitServiceKey_Validation(t *testing.T) {
 		{key: "default/svc-a", wantNS: "default", wantName: "svc-a", wantParsed: true},
 		{key: "/svc-a", wantParsed: false},
 		{key: "default/", wantParsed: false},
-		{key: "default/svc/a", wantParsed: false},
+		{key: "prod/default/svc-a", wantNS: "prod/default", wantName: "svc-a", wantParsed: true},
+		{key: "prod//svc-a", wantParsed: false},
+		{key: "a/b/c/d", wantParsed: false},
 		{key: "noslash", wantParsed: false},
 	}
 
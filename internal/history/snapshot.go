@@ -0,0 +1,141 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// snapshotVersion is bumped when the snapshot format changes incompatibly;
+// snapshots of another version are ignored.
+const snapshotVersion = 1
+
+// ServiceSource provides the services to snapshot.
+type ServiceSource interface {
+	All() []state.Service
+}
+
+type snapshotFile struct {
+	Version  int             `json:"version"`
+	SavedAt  time.Time       `json:"savedAt"`
+	Services json.RawMessage `json:"services"`
+}
+
+// ReadSnapshot returns the services in the snapshot at path. If the file
+// does not exist, it returns nil with no error.
+func ReadSnapshot(path string) ([]state.Service, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var f snapshotFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	if f.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", f.Version)
+	}
+	var services []state.Service
+	if err := json.Unmarshal(f.Services, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// Snapshotter periodically writes the services in a ServiceSource to a file,
+// so a restart can show their last known state at once (see
+// state.Store.Restore). Only what the service JSON carries is saved:
+// observed health and discovered metadata, never probe secrets.
+type Snapshotter struct {
+	source   ServiceSource
+	path     string
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu   sync.Mutex
+	last []byte // services as last written, to skip unchanged snapshots
+}
+
+// NewSnapshotter creates a Snapshotter that writes source to path every
+// interval. If logger is nil, a no-op logger is used.
+func NewSnapshotter(source ServiceSource, path string, interval time.Duration, logger *slog.Logger) *Snapshotter {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &Snapshotter{
+		source:   source,
+		path:     path,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run saves a snapshot every interval until ctx is cancelled. Call Save on
+// shutdown to persist the final state.
+func (s *Snapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil {
+				s.logger.Warn("snapshot write failed", "error", err)
+			}
+		}
+	}
+}
+
+// Save writes the current services to the snapshot file, replacing it
+// atomically. Nothing is written if they are unchanged since the last save.
+func (s *Snapshotter) Save() error {
+	services := s.source.All()
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Key() < services[j].Key()
+	})
+	encoded, err := json.Marshal(services)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(encoded, s.last) {
+		return nil
+	}
+	data, err := json.Marshal(snapshotFile{
+		Version:  snapshotVersion,
+		SavedAt:  time.Now().UTC(),
+		Services: encoded,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.last = encoded
+	return nil
+}
@@ -0,0 +1,141 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+type fakeServiceSource struct {
+	services []state.Service
+}
+
+func (f *fakeServiceSource) All() []state.Service { return f.services }
+
+func TestSnapshotter_SaveAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "snapshot.json")
+	checked := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	ms := int64(42)
+	src := &fakeServiceSource{services: []state.Service{
+		{Name: "web", Namespace: "media", Status: state.StatusHealthy, ResponseTimeMs: &ms, LastChecked: &checked,
+			GitOpsStatus: &state.GitOpsStatus{ReconciliationState: state.ReconcilSynced}},
+		{Name: "api", Namespace: "default", Status: state.StatusUnhealthy,
+			Probe: &state.ProbeSpec{Type: state.ProbeHTTP, BearerToken: &state.SecretRef{Value: "s3cret"}}},
+	}}
+
+	s := NewSnapshotter(src, path, time.Minute, nil)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Error("snapshot must not contain probe secrets")
+	}
+
+	services, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error: %v", err)
+	}
+	if len(services) != 2 || services[0].Name != "api" || services[1].Name != "web" {
+		t.Fatalf("expected services sorted by key, got %+v", services)
+	}
+	web := services[1]
+	if web.ResponseTimeMs == nil || *web.ResponseTimeMs != 42 || web.LastChecked == nil || !web.LastChecked.Equal(checked) {
+		t.Errorf("measurements not round-tripped: %+v", web)
+	}
+	if web.GitOpsStatus == nil || web.GitOpsStatus.ReconciliationState != state.ReconcilSynced {
+		t.Errorf("GitOps status not round-tripped: %+v", web.GitOpsStatus)
+	}
+}
+
+func TestSnapshotter_SkipsUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	src := &fakeServiceSource{services: []state.Service{{Name: "web", Namespace: "media"}}}
+	s := NewSnapshotter(src, path, time.Minute, nil)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("unchanged services should not be written again")
+	}
+
+	src.services[0].Status = state.StatusHealthy
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("changed services should be written: %v", err)
+	}
+}
+
+func TestSnapshotter_SortsByScopedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	src := &fakeServiceSource{services: []state.Service{
+		{Name: "web", Namespace: "media", Cluster: "lab"},
+		{Name: "web", Namespace: "media", Cluster: "home"},
+	}}
+	s := NewSnapshotter(src, path, time.Minute, nil)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same services in another order are unchanged.
+	src.services[0], src.services[1] = src.services[1], src.services[0]
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("services of several clusters should be written in a stable order")
+	}
+}
+
+func TestReadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "missing file"},
+		{name: "malformed", content: "{", wantErr: "unexpected end"},
+		{name: "other version", content: `{"version":99,"services":[]}`, wantErr: "unsupported snapshot version 99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			services, err := ReadSnapshot(path)
+			if tt.wantErr == "" {
+				if err != nil || services != nil {
+					t.Errorf("ReadSnapshot() = %v, %v; want nil, nil", services, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -15,11 +15,13 @@ import (
 // TransitionRecord captures a single health-status transition for a service.
 type TransitionRecord struct {
 	Timestamp  time.Time          `json:"ts"`
-	ServiceKey string             `json:"svc"`
+	Cluster    string             `json:"cluster,omitempty"` // Kubernetes cluster of the service, if named
+	ServiceKey string             `json:"svc"`               // "namespace/name"
 	PrevStatus state.HealthStatus `json:"prev"`
 	NextStatus state.HealthStatus `json:"next"`
 	HTTPCode   *int               `json:"code"`
 	ResponseMs *int64             `json:"ms"`
+	Expected   bool               `json:"expected,omitempty"` // Occurred during a maintenance window
 }
 
 // HistoryWriter persists health-status transition records.
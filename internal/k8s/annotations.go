@@ -0,0 +1,223 @@
+package k8s
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rathix/command-center/internal/state"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// annotationPrefix is shared by every annotation the watchers read.
+const annotationPrefix = "command-center.io/"
+
+// Annotations that configure the service of a discovered object. Values set
+// by a config override take precedence over them, and they take precedence
+// over the values derived from the object itself.
+const (
+	// DisplayNameAnnotation sets the display name, in place of the first
+	// label of the host.
+	DisplayNameAnnotation = "command-center.io/display-name"
+	// GroupAnnotation sets the group, in place of the namespace.
+	GroupAnnotation = "command-center.io/group"
+	// IconAnnotation sets the icon.
+	IconAnnotation = "command-center.io/icon"
+	// DescriptionAnnotation sets a free-text description.
+	DescriptionAnnotation = "command-center.io/description"
+	// HealthPathAnnotation sets the path probed on the service's host, e.g.
+	// "/healthz", in place of its URL.
+	HealthPathAnnotation = "command-center.io/health-path"
+	// ExpectedStatusAnnotation lists, comma-separated, the status codes
+	// treated as healthy.
+	ExpectedStatusAnnotation = "command-center.io/expected-status"
+	// IgnoreAnnotation set to "true" leaves the object out of discovery.
+	IgnoreAnnotation = "command-center.io/ignore"
+	// ExpandRulesAnnotation set to "true" gives an Ingress one service per
+	// host and path of its rules, in place of one for its first rule; set
+	// to "false", it opts an Ingress out of Scope.ExpandRules.
+	ExpandRulesAnnotation = "command-center.io/expand-rules"
+	// ProbeAnnotation sets the probe type: http, tcp, tls or grpc.
+	ProbeAnnotation = "command-center.io/probe"
+)
+
+// Annotations of the Services discovered by ServiceDiscovery.
+const (
+	// DiscoverAnnotation set to "true" opts a LoadBalancer or NodePort
+	// Service into discovery.
+	DiscoverAnnotation = "command-center.io/discover"
+	// SchemeAnnotation sets the scheme of a Service's URL, in place of http,
+	// or https for port 443 or a port named https.
+	SchemeAnnotation = "command-center.io/scheme"
+	// PortAnnotation selects the port of a Service's URL by name or number,
+	// in place of its first port.
+	PortAnnotation = "command-center.io/port"
+)
+
+// DependsOnAnnotation lists, comma-separated, the services an Ingress's
+// service depends on: "namespace/name" keys, or names in its own namespace.
+const DependsOnAnnotation = "command-center.io/depends-on"
+
+// LabelsAnnotation adds labels to an Ingress's service, as comma-separated
+// key=value pairs, e.g. "tier=critical,owner=alice". They take precedence
+// over the Ingress's own labels, which take precedence over its namespace's.
+const LabelsAnnotation = "command-center.io/labels"
+
+// schemePattern matches a URL scheme (RFC 3986).
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+
+var knownAnnotations = []string{
+	DisplayNameAnnotation,
+	GroupAnnotation,
+	IconAnnotation,
+	DescriptionAnnotation,
+	HealthPathAnnotation,
+	ExpectedStatusAnnotation,
+	IgnoreAnnotation,
+	ExpandRulesAnnotation,
+	ProbeAnnotation,
+	DiscoverAnnotation,
+	SchemeAnnotation,
+	PortAnnotation,
+	DependsOnAnnotation,
+	LabelsAnnotation,
+}
+
+// serviceAnnotations is the service configuration read from an object's
+// annotations. Unset fields are zero.
+type serviceAnnotations struct {
+	DisplayName         string
+	Group               string
+	Icon                string
+	Description         string
+	HealthPath          string
+	ExpectedStatusCodes []int
+	Ignore              bool
+	ExpandRules         *bool // nil when not annotated
+	Probe               string
+	Discover            bool
+	Scheme              string
+	Port                string
+	DependsOn           []string
+	Labels              map[string]string
+}
+
+// parseAnnotations reads the service configuration from annotations. An
+// invalid value is reported in the returned errors and left unset; so is an
+// unknown annotation with the command-center.io/ prefix, which is most
+// likely misspelled.
+func parseAnnotations(annotations map[string]string) (serviceAnnotations, []string) {
+	var (
+		out  serviceAnnotations
+		errs []string
+	)
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(annotations)) {
+		if strings.HasPrefix(key, annotationPrefix) && !slices.Contains(knownAnnotations, key) {
+			invalid(key, "unknown annotation")
+		}
+	}
+
+	out.DisplayName = strings.TrimSpace(annotations[DisplayNameAnnotation])
+	out.Group = strings.TrimSpace(annotations[GroupAnnotation])
+	out.Icon = strings.TrimSpace(annotations[IconAnnotation])
+	out.Description = strings.TrimSpace(annotations[DescriptionAnnotation])
+
+	if raw := strings.TrimSpace(annotations[HealthPathAnnotation]); raw != "" {
+		if !strings.HasPrefix(raw, "/") || strings.ContainsAny(raw, " \t#") {
+			invalid(HealthPathAnnotation, "invalid path %q: must start with /", raw)
+		} else {
+			out.HealthPath = raw
+		}
+	}
+
+	if raw := strings.TrimSpace(annotations[ExpectedStatusAnnotation]); raw != "" {
+		var codes []int
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.TrimSpace(field)
+			code, err := strconv.Atoi(field)
+			if err != nil || code < 100 || code > 599 {
+				invalid(ExpectedStatusAnnotation, "invalid status code %q", field)
+				codes = nil
+				break
+			}
+			codes = append(codes, code)
+		}
+		out.ExpectedStatusCodes = codes
+	}
+
+	if raw := strings.TrimSpace(annotations[IgnoreAnnotation]); raw != "" {
+		ignore, err := strconv.ParseBool(raw)
+		if err != nil {
+			invalid(IgnoreAnnotation, "invalid value %q: must be true or false", raw)
+		}
+		out.Ignore = ignore
+	}
+
+	if raw := strings.TrimSpace(annotations[ExpandRulesAnnotation]); raw != "" {
+		if expand, err := strconv.ParseBool(raw); err != nil {
+			invalid(ExpandRulesAnnotation, "invalid value %q: must be true or false", raw)
+		} else {
+			out.ExpandRules = &expand
+		}
+	}
+
+	if raw := strings.TrimSpace(annotations[ProbeAnnotation]); raw != "" {
+		switch raw {
+		case state.ProbeHTTP, state.ProbeTCP, state.ProbeTLS, state.ProbeGRPC:
+			out.Probe = raw
+		default:
+			invalid(ProbeAnnotation, "invalid probe type %q: must be http, tcp, tls or grpc", raw)
+		}
+	}
+
+	if raw := strings.TrimSpace(annotations[DiscoverAnnotation]); raw != "" {
+		discover, err := strconv.ParseBool(raw)
+		if err != nil {
+			invalid(DiscoverAnnotation, "invalid value %q: must be true or false", raw)
+		}
+		out.Discover = discover
+	}
+
+	if raw := strings.TrimSpace(annotations[SchemeAnnotation]); raw != "" {
+		if !schemePattern.MatchString(raw) {
+			invalid(SchemeAnnotation, "invalid scheme %q", raw)
+		} else {
+			out.Scheme = strings.ToLower(raw)
+		}
+	}
+
+	out.Port = strings.TrimSpace(annotations[PortAnnotation])
+
+	if raw, ok := annotations[DependsOnAnnotation]; ok {
+		for _, dep := range strings.Split(raw, ",") {
+			dep = strings.TrimSpace(dep)
+			if dep == "" {
+				continue
+			}
+			if slices.Contains(strings.Split(dep, "/"), "") || strings.Count(dep, "/") > 3 {
+				invalid(DependsOnAnnotation, "invalid service key %q", dep)
+				continue
+			}
+			out.DependsOn = append(out.DependsOn, dep)
+		}
+	}
+
+	if raw := strings.TrimSpace(annotations[LabelsAnnotation]); raw != "" {
+		annotated, err := labels.ConvertSelectorToLabelsMap(raw)
+		if err != nil {
+			invalid(LabelsAnnotation, "%v", err)
+		} else {
+			out.Labels = annotated
+		}
+	}
+
+	return out, errs
+}
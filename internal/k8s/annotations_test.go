@@ -0,0 +1,125 @@
+package k8s
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	expand := true
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        serviceAnnotations
+		wantErrs    []string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "all set",
+			annotations: map[string]string{
+				DisplayNameAnnotation:         " Jellyfin ",
+				GroupAnnotation:               "media",
+				IconAnnotation:                "jellyfin",
+				DescriptionAnnotation:         "Streams the film collection",
+				HealthPathAnnotation:          "/health",
+				ExpectedStatusAnnotation:      "200, 401",
+				IgnoreAnnotation:              "false",
+				ExpandRulesAnnotation:         "true",
+				DependsOnAnnotation:           "postgres, storage/minio",
+				LabelsAnnotation:              "tier=critical",
+				"kubernetes.io/ingress.class": "nginx",
+			},
+			want: serviceAnnotations{
+				DisplayName:         "Jellyfin",
+				Group:               "media",
+				Icon:                "jellyfin",
+				Description:         "Streams the film collection",
+				HealthPath:          "/health",
+				ExpectedStatusCodes: []int{200, 401},
+				ExpandRules:         &expand,
+				DependsOn:           []string{"postgres", "storage/minio"},
+				Labels:              map[string]string{"tier": "critical"},
+			},
+		},
+		{
+			name:        "ignore",
+			annotations: map[string]string{IgnoreAnnotation: "true"},
+			want:        serviceAnnotations{Ignore: true},
+		},
+		{
+			name: "invalid values are left unset",
+			annotations: map[string]string{
+				DisplayNameAnnotation:      "Jellyfin",
+				HealthPathAnnotation:       "health",
+				ExpectedStatusAnnotation:   "200,ok",
+				IgnoreAnnotation:           "yes please",
+				ExpandRulesAnnotation:      "maybe",
+				DependsOnAnnotation:        "postgres,storage/,a/b/c/d/e",
+				LabelsAnnotation:           "not a label",
+				"command-center.io/colour": "blue",
+			},
+			want: serviceAnnotations{
+				DisplayName: "Jellyfin",
+				DependsOn:   []string{"postgres"},
+			},
+			wantErrs: []string{
+				`command-center.io/colour: unknown annotation`,
+				`command-center.io/health-path: invalid path "health": must start with /`,
+				`command-center.io/expected-status: invalid status code "ok"`,
+				`command-center.io/ignore: invalid value "yes please": must be true or false`,
+				`command-center.io/expand-rules: invalid value "maybe": must be true or false`,
+				`command-center.io/depends-on: invalid service key "storage/"`,
+				`command-center.io/depends-on: invalid service key "a/b/c/d/e"`,
+				`command-center.io/labels: invalid selector: [not a label]`,
+			},
+		},
+		{
+			name: "service",
+			annotations: map[string]string{
+				DiscoverAnnotation: "true",
+				SchemeAnnotation:   "HTTPS",
+				PortAnnotation:     " web ",
+				ProbeAnnotation:    "tls",
+			},
+			want: serviceAnnotations{Discover: true, Scheme: "https", Port: "web", Probe: "tls"},
+		},
+		{
+			name: "invalid service values are left unset",
+			annotations: map[string]string{
+				DiscoverAnnotation: "sure",
+				SchemeAnnotation:   "http://",
+				ProbeAnnotation:    "dns",
+			},
+			wantErrs: []string{
+				`command-center.io/probe: invalid probe type "dns": must be http, tcp, tls or grpc`,
+				`command-center.io/discover: invalid value "sure": must be true or false`,
+				`command-center.io/scheme: invalid scheme "http://"`,
+			},
+		},
+		{
+			name:        "status code out of range",
+			annotations: map[string]string{ExpectedStatusAnnotation: "200,600"},
+			wantErrs:    []string{`command-center.io/expected-status: invalid status code "600"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := parseAnnotations(tt.annotations)
+			if got.DisplayName != tt.want.DisplayName || got.Group != tt.want.Group || got.Icon != tt.want.Icon ||
+				got.Description != tt.want.Description || got.HealthPath != tt.want.HealthPath || got.Ignore != tt.want.Ignore ||
+				!reflect.DeepEqual(got.ExpandRules, tt.want.ExpandRules) || got.Probe != tt.want.Probe || got.Discover != tt.want.Discover ||
+				got.Scheme != tt.want.Scheme || got.Port != tt.want.Port ||
+				!slices.Equal(got.ExpectedStatusCodes, tt.want.ExpectedStatusCodes) ||
+				!slices.Equal(got.DependsOn, tt.want.DependsOn) || !maps.Equal(got.Labels, tt.want.Labels) {
+				t.Errorf("parseAnnotations() = %+v, want %+v", got, tt.want)
+			}
+			if !slices.Equal(errs, tt.wantErrs) {
+				t.Errorf("errors = %q, want %q", errs, tt.wantErrs)
+			}
+		})
+	}
+}
@@ -4,13 +4,27 @@ import (
 	"fmt"
 
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-// BuildClientset creates a Kubernetes clientset from a kubeconfig path.
-// If kubeconfigPath is empty, in-cluster config is used.
-func BuildClientset(kubeconfigPath string) (kubernetes.Interface, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+// BuildConfig creates a Kubernetes client configuration from a kubeconfig
+// path and context. An empty kubeContext uses the kubeconfig's current
+// context. If both are empty, in-cluster config is used.
+func BuildConfig(kubeconfigPath, kubeContext string) (*rest.Config, error) {
+	if kubeContext == "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfigPath
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
+
+// BuildClientset creates a Kubernetes clientset from a kubeconfig path and
+// context. If both are empty, in-cluster config is used.
+func BuildClientset(kubeconfigPath, kubeContext string) (kubernetes.Interface, error) {
+	config, err := BuildConfig(kubeconfigPath, kubeContext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build kubeconfig")
 	}
@@ -0,0 +1,54 @@
+package k8s
+
+import "github.com/rathix/command-center/internal/state"
+
+// ClusterStateUpdater is the interface a cluster-scoped updater writes
+// through. Satisfied by *state.Store.
+type ClusterStateUpdater interface {
+	Get(namespace, name string) (state.Service, bool)
+	AddOrUpdate(svc state.Service)
+	Remove(namespace, name string)
+	SetClusterConnected(cluster string, connected bool)
+	Update(namespace, name string, fn func(*state.Service))
+	SetObjectErrors(kind, namespace, name string, errs []string)
+}
+
+// clusterUpdater scopes a watcher set to one named cluster: services it
+// writes are tagged with the cluster and keyed by their scoped namespace,
+// and its connectivity is reported for that cluster alone.
+type clusterUpdater struct {
+	cluster string
+	store   ClusterStateUpdater
+}
+
+// ForCluster returns a StateUpdater for the watchers of the named cluster.
+// The watchers keep using plain Kubernetes namespaces; an empty cluster
+// name is the unnamed cluster of a single-cluster setup.
+func ForCluster(cluster string, store ClusterStateUpdater) StateUpdater {
+	return &clusterUpdater{cluster: cluster, store: store}
+}
+
+func (c *clusterUpdater) Get(namespace, name string) (state.Service, bool) {
+	return c.store.Get(state.ScopedNamespace(c.cluster, namespace), name)
+}
+
+func (c *clusterUpdater) AddOrUpdate(svc state.Service) {
+	svc.Cluster = c.cluster
+	c.store.AddOrUpdate(svc)
+}
+
+func (c *clusterUpdater) Remove(namespace, name string) {
+	c.store.Remove(state.ScopedNamespace(c.cluster, namespace), name)
+}
+
+func (c *clusterUpdater) SetK8sConnected(connected bool) {
+	c.store.SetClusterConnected(c.cluster, connected)
+}
+
+func (c *clusterUpdater) Update(namespace, name string, fn func(*state.Service)) {
+	c.store.Update(state.ScopedNamespace(c.cluster, namespace), name, fn)
+}
+
+func (c *clusterUpdater) SetObjectErrors(kind, namespace, name string, errs []string) {
+	c.store.SetObjectErrors(kind, state.ScopedNamespace(c.cluster, namespace), name, errs)
+}
@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestForCluster_ScopesWatcherToCluster(t *testing.T) {
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Name: "web", Namespace: "media", Source: state.SourceKubernetes})
+
+	clientset := fake.NewSimpleClientset(newTestIngress("web", "media", "web.lab.example.com", true))
+	updater := ForCluster("lab", store)
+	w := NewWatcherWithClientAndESWatcher(clientset, updater, slog.Default(), NewEndpointSliceWatcher(clientset, updater, slog.Default()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+
+	waitFor(t, func() bool { _, ok := store.Get("lab/media", "web"); return ok })
+	svc, _ := store.Get("lab/media", "web")
+	if svc.Cluster != "lab" || svc.Namespace != "media" || svc.URL != "https://web.lab.example.com" {
+		t.Errorf("lab service = %+v", svc)
+	}
+	if clusters := store.Clusters(); len(clusters) != 1 || clusters[0].Name != "lab" || !clusters[0].Connected {
+		t.Errorf("Clusters() = %+v, want lab connected", clusters)
+	}
+
+	if err := clientset.NetworkingV1().Ingresses("media").Delete(ctx, "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := store.Get("lab/media", "web"); return !ok })
+	if _, ok := store.Get("media", "web"); !ok {
+		t.Error("service of the unnamed cluster should be untouched")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -23,17 +23,19 @@ type EndpointStateUpdater interface {
 	Update(namespace, name string, fn func(*state.Service))
 }
 
-// EndpointSliceWatcher manages a single cluster-wide EndpointSlice informer
-// and updates state for all registered services.
+// EndpointSliceWatcher manages a single cluster-wide EndpointSlice informer,
+// or one per namespace of a Scope, and updates state for all registered
+// services.
 type EndpointSliceWatcher struct {
 	clientset      kubernetes.Interface
 	updater        EndpointStateUpdater
 	logger         *slog.Logger
 	podDiagQuerier *PodDiagnosticQuerier
 
-	factory  informers.SharedInformerFactory
-	informer cache.SharedIndexInformer
-	cancel   context.CancelFunc
+	// factories holds the informer factory of each watched namespace; the
+	// key "" holds the cluster-wide one.
+	factories map[string]informers.SharedInformerFactory
+	cancel    context.CancelFunc
 
 	mu sync.RWMutex
 	// serviceToIngress maps "namespace/serviceName" to a set of ingress names
@@ -47,30 +49,39 @@ func NewEndpointSliceWatcher(clientset kubernetes.Interface, updater EndpointSta
 
 // NewEndpointSliceWatcherWithTweak allows providing a tweak function for the informer factory.
 func NewEndpointSliceWatcherWithTweak(clientset kubernetes.Interface, updater EndpointStateUpdater, logger *slog.Logger, tweak func(*metav1.ListOptions)) *EndpointSliceWatcher {
-	ctx, cancel := context.WithCancel(context.Background())
+	return NewEndpointSliceWatcherForNamespaces(clientset, updater, logger, []string{metav1.NamespaceAll}, tweak)
+}
 
-	// Cluster-wide informer factory
-	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithTweakListOptions(tweak))
-	informer := factory.Discovery().V1().EndpointSlices().Informer()
+// NewEndpointSliceWatcherForNamespaces creates an EndpointSliceWatcher with
+// an informer in each of namespaces, metav1.NamespaceAll for the whole
+// cluster, listing with tweak.
+func NewEndpointSliceWatcherForNamespaces(clientset kubernetes.Interface, updater EndpointStateUpdater, logger *slog.Logger, namespaces []string, tweak func(*metav1.ListOptions)) *EndpointSliceWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
 
 	e := &EndpointSliceWatcher{
 		clientset:        clientset,
 		updater:          updater,
 		logger:           logger,
 		podDiagQuerier:   NewPodDiagnosticQuerier(clientset, logger),
-		factory:          factory,
-		informer:         informer,
+		factories:        make(map[string]informers.SharedInformerFactory, len(namespaces)),
 		cancel:           cancel,
 		serviceToIngress: make(map[string]map[string]struct{}),
 	}
 
-	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    e.onAdd,
-		UpdateFunc: e.onUpdate,
-		DeleteFunc: e.onDelete,
-	})
-
-	factory.Start(ctx.Done())
+	for _, ns := range namespaces {
+		factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+			informers.WithNamespace(ns),
+			informers.WithTweakListOptions(tweak))
+		factory.Discovery().V1().EndpointSlices().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    e.onAdd,
+			UpdateFunc: e.onUpdate,
+			DeleteFunc: e.onDelete,
+		})
+		e.factories[ns] = factory
+	}
+	for _, factory := range e.factories {
+		factory.Start(ctx.Done())
+	}
 
 	return e
 }
@@ -124,7 +135,13 @@ func (e *EndpointSliceWatcher) triggerUpdate(namespace, serviceName string) {
 	e.mu.RUnlock()
 
 	// List all slices for this service to aggregate readiness
-	lister := e.factory.Discovery().V1().EndpointSlices().Lister()
+	factory, ok := e.factories[namespace]
+	if !ok {
+		if factory, ok = e.factories[metav1.NamespaceAll]; !ok {
+			return
+		}
+	}
+	lister := factory.Discovery().V1().EndpointSlices().Lister()
 	selector := labels.SelectorFromSet(labels.Set{"kubernetes.io/service-name": serviceName})
 	slices, err := lister.EndpointSlices(namespace).List(selector)
 	if err != nil {
@@ -195,7 +212,9 @@ func (e *EndpointSliceWatcher) Unwatch(ingressName, namespace string) {
 // StopAll shuts down the informer factory.
 func (e *EndpointSliceWatcher) StopAll() {
 	e.cancel()
-	e.factory.Shutdown()
+	for _, factory := range e.factories {
+		factory.Shutdown()
+	}
 
 	e.mu.Lock()
 	e.serviceToIngress = make(map[string]map[string]struct{})
@@ -206,13 +225,16 @@ func (e *EndpointSliceWatcher) StopAll() {
 
 // WaitForSync waits for the internal informer to sync.
 func (e *EndpointSliceWatcher) WaitForSync(ctx context.Context) bool {
-	syncStatus := e.factory.WaitForCacheSync(ctx.Done())
-	for _, synced := range syncStatus {
-		if !synced {
-			return false
+	count := 0
+	for _, factory := range e.factories {
+		for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				return false
+			}
+			count++
 		}
 	}
-	return len(syncStatus) > 0
+	return count > 0
 }
 
 // aggregateEndpointReadiness counts ready and total endpoints across all slices.
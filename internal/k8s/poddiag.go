@@ -1,14 +1,21 @@
 package k8s
 
 import (
+	"cmp"
 	"context"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/rathix/command-center/internal/state"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -64,9 +71,17 @@ func DiagFromPod(pod *corev1.Pod) (reasons []string, restartCount int) {
 // DiagFromPods aggregates diagnostics across multiple pods into a single PodDiagnostic.
 // Returns nil when there is nothing noteworthy to report.
 func DiagFromPods(pods []*corev1.Pod) *state.PodDiagnostic {
+	return diagFromPods(pods, nil)
+}
+
+// diagFromPods is DiagFromPods with the Warning Events of the pods, keyed by
+// pod name; a pod with events is noteworthy even without a reason, e.g. one
+// that cannot be scheduled.
+func diagFromPods(pods []*corev1.Pod, events map[string][]state.PodEvent) *state.PodDiagnostic {
 	var allReasons []string
 	totalRestarts := 0
 	seen := map[string]bool{}
+	details := make([]state.PodDetail, 0, len(pods))
 	for _, p := range pods {
 		reasons, rc := DiagFromPod(p)
 		totalRestarts += rc
@@ -76,17 +91,141 @@ func DiagFromPods(pods []*corev1.Pod) *state.PodDiagnostic {
 				allReasons = append(allReasons, r)
 			}
 		}
+		detail := PodDetailFromPod(p)
+		detail.Events = events[p.Name]
+		details = append(details, detail)
 	}
-	if len(allReasons) == 0 && totalRestarts == 0 {
+	if len(allReasons) == 0 && totalRestarts == 0 && len(events) == 0 {
 		return nil
 	}
-	diag := &state.PodDiagnostic{RestartCount: totalRestarts}
+	slices.SortFunc(details, func(a, b state.PodDetail) int { return strings.Compare(a.Name, b.Name) })
+	diag := &state.PodDiagnostic{RestartCount: totalRestarts, Pods: details}
 	if best := MostSevereReason(allReasons); best != "" {
 		diag.Reason = &best
 	}
 	return diag
 }
 
+// maxDiagMessage bounds the length in bytes of the termination and event
+// messages kept in a diagnostic.
+const maxDiagMessage = 512
+
+// maxPodEvents is the number of recent Warning Events kept per pod.
+const maxPodEvents = 5
+
+// PodDetailFromPod extracts the node and the state of each container of a pod.
+func PodDetailFromPod(pod *corev1.Pod) state.PodDetail {
+	detail := state.PodDetail{Name: pod.Name, Node: pod.Spec.NodeName}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		detail.Containers = append(detail.Containers, containerDetail(cs, true))
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		detail.Containers = append(detail.Containers, containerDetail(cs, false))
+	}
+	return detail
+}
+
+// containerDetail extracts the state of a container and its last
+// termination: the current one, or else the previous.
+func containerDetail(cs corev1.ContainerStatus, init bool) state.ContainerDetail {
+	detail := state.ContainerDetail{
+		Name:         cs.Name,
+		Init:         init,
+		Ready:        cs.Ready,
+		RestartCount: int(cs.RestartCount),
+	}
+	switch {
+	case cs.State.Running != nil:
+		detail.State = "running"
+	case cs.State.Waiting != nil:
+		detail.State = cmp.Or(cs.State.Waiting.Reason, "waiting")
+	case cs.State.Terminated != nil:
+		detail.State = cmp.Or(cs.State.Terminated.Reason, "terminated")
+	}
+	terminated := cs.State.Terminated
+	if terminated == nil {
+		terminated = cs.LastTerminationState.Terminated
+	}
+	if terminated != nil {
+		exitCode := int(terminated.ExitCode)
+		detail.ExitCode = &exitCode
+		detail.TerminationReason = terminated.Reason
+		detail.TerminationMessage = truncateMessage(terminated.Message)
+	}
+	return detail
+}
+
+// podEvents groups the Warning Events of the given pods, by pod name, newest
+// first and at most maxPodEvents each. An event of an earlier pod of the
+// same name, per its UID, is skipped.
+func podEvents(events []corev1.Event, pods []*corev1.Pod) map[string][]state.PodEvent {
+	uids := make(map[string]types.UID, len(pods))
+	for _, p := range pods {
+		uids[p.Name] = p.UID
+	}
+	byPod := make(map[string][]*corev1.Event)
+	for i := range events {
+		e := &events[i]
+		uid, ok := uids[e.InvolvedObject.Name]
+		if !ok || e.Type != corev1.EventTypeWarning || e.InvolvedObject.Kind != "Pod" {
+			continue
+		}
+		if uid != "" && e.InvolvedObject.UID != "" && e.InvolvedObject.UID != uid {
+			continue
+		}
+		byPod[e.InvolvedObject.Name] = append(byPod[e.InvolvedObject.Name], e)
+	}
+
+	out := make(map[string][]state.PodEvent, len(byPod))
+	for name, list := range byPod {
+		slices.SortFunc(list, func(a, b *corev1.Event) int { return eventTime(b).Compare(eventTime(a)) })
+		for _, e := range list[:min(len(list), maxPodEvents)] {
+			count := int(e.Count)
+			if e.Series != nil {
+				count = int(e.Series.Count)
+			}
+			event := state.PodEvent{
+				Reason:  e.Reason,
+				Message: truncateMessage(e.Message),
+				Count:   max(count, 1),
+			}
+			if t := eventTime(e); !t.IsZero() {
+				event.LastSeen = &t
+			}
+			out[name] = append(out[name], event)
+		}
+	}
+	return out
+}
+
+// eventTime returns when an event was last seen, whichever of its
+// timestamps its reporter set.
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+// truncateMessage trims a message and cuts it to maxDiagMessage bytes, on a
+// rune boundary.
+func truncateMessage(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if len(msg) <= maxDiagMessage {
+		return msg
+	}
+	cut := maxDiagMessage
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return msg[:cut] + "…"
+}
+
 // PodDiagnosticQuerier queries Kubernetes for pod-level diagnostics.
 type PodDiagnosticQuerier struct {
 	clientset kubernetes.Interface
@@ -124,5 +263,21 @@ func (q *PodDiagnosticQuerier) QueryForService(ctx context.Context, namespace st
 	}
 
 	wg.Wait()
-	return DiagFromPods(pods)
+	if len(pods) == 0 {
+		return nil
+	}
+	return diagFromPods(pods, podEvents(q.warningEvents(ctx, namespace), pods))
+}
+
+// warningEvents lists the Warning Events of the pods in a namespace. A
+// failure is logged, and the diagnostic goes without events.
+func (q *PodDiagnosticQuerier) warningEvents(ctx context.Context, namespace string) []corev1.Event {
+	list, err := q.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{"involvedObject.kind": "Pod", "type": corev1.EventTypeWarning}.String(),
+	})
+	if err != nil {
+		q.logger.Warn("failed to list events for diagnostics", "namespace", namespace, "error", err)
+		return nil
+	}
+	return list.Items
 }
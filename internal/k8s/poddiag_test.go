@@ -4,12 +4,17 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/rathix/command-center/internal/state"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -217,6 +222,105 @@ func TestDiagFromPods(t *testing.T) {
 	}
 }
 
+func TestPodDetailFromPod(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-abc"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "migrate", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "app",
+					State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					RestartCount: 4,
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1, Reason: "Error", Message: "panic: missing DATABASE_URL\n",
+					}},
+				},
+				{Name: "sidecar", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			},
+		},
+	}
+
+	got := PodDetailFromPod(pod)
+	if got.Name != "app-abc" || got.Node != "node-1" || len(got.Containers) != 3 {
+		t.Fatalf("PodDetailFromPod() = %+v", got)
+	}
+	migrate, app, sidecar := got.Containers[0], got.Containers[1], got.Containers[2]
+	if !migrate.Init || migrate.State != "Completed" || migrate.ExitCode == nil || *migrate.ExitCode != 0 {
+		t.Errorf("init container = %+v", migrate)
+	}
+	if app.State != "CrashLoopBackOff" || app.RestartCount != 4 || app.ExitCode == nil || *app.ExitCode != 1 ||
+		app.TerminationReason != "Error" || app.TerminationMessage != "panic: missing DATABASE_URL" {
+		t.Errorf("crashing container = %+v", app)
+	}
+	if !sidecar.Ready || sidecar.State != "running" || sidecar.ExitCode != nil {
+		t.Errorf("running container = %+v", sidecar)
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	long := strings.Repeat("é", maxDiagMessage)
+	got := truncateMessage(long)
+	if !utf8.ValidString(got) || len(got) > maxDiagMessage+len("…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("truncateMessage() = %d bytes, valid %v", len(got), utf8.ValidString(got))
+	}
+	if got := truncateMessage("  short\n"); got != "short" {
+		t.Errorf("truncateMessage() = %q, want %q", got, "short")
+	}
+}
+
+func TestPodEvents(t *testing.T) {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	pods := []*corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "app-1", UID: "uid-1"}}}
+	event := func(name, uid, typ, reason string, at time.Time) corev1.Event {
+		return corev1.Event{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: name, UID: types.UID(uid)},
+			Type:           typ,
+			Reason:         reason,
+			Message:        reason + " message",
+			LastTimestamp:  metav1.NewTime(at),
+		}
+	}
+	events := []corev1.Event{
+		event("app-1", "uid-1", corev1.EventTypeWarning, "FailedMount", base),
+		event("app-1", "uid-1", corev1.EventTypeWarning, "BackOff", base.Add(time.Minute)),
+		event("app-1", "uid-1", corev1.EventTypeNormal, "Pulled", base.Add(2*time.Minute)),
+		event("app-1", "uid-0", corev1.EventTypeWarning, "Unhealthy", base.Add(3*time.Minute)),
+		event("other", "uid-2", corev1.EventTypeWarning, "FailedScheduling", base),
+	}
+	series := event("app-1", "", corev1.EventTypeWarning, "Unhealthy", time.Time{})
+	series.Series = &corev1.EventSeries{Count: 7, LastObservedTime: metav1.NewMicroTime(base.Add(30 * time.Second))}
+	events = append(events, series)
+
+	got := podEvents(events, pods)
+	if len(got) != 1 {
+		t.Fatalf("podEvents() = %v, want events of app-1 alone", got)
+	}
+	var reasons []string
+	for _, e := range got["app-1"] {
+		reasons = append(reasons, e.Reason)
+	}
+	if want := []string{"BackOff", "Unhealthy", "FailedMount"}; !slices.Equal(reasons, want) {
+		t.Errorf("reasons = %v, want %v", reasons, want)
+	}
+	if unhealthy := got["app-1"][1]; unhealthy.Count != 7 || unhealthy.LastSeen == nil || !unhealthy.LastSeen.Equal(base.Add(30*time.Second)) {
+		t.Errorf("series event = %+v", unhealthy)
+	}
+	if backOff := got["app-1"][0]; backOff.Count != 1 || backOff.Message != "BackOff message" {
+		t.Errorf("event = %+v", backOff)
+	}
+
+	for i := range 2 * maxPodEvents {
+		events = append(events, event("app-1", "uid-1", corev1.EventTypeWarning, "BackOff", base.Add(time.Duration(i)*time.Hour)))
+	}
+	if got := podEvents(events, pods); len(got["app-1"]) != maxPodEvents {
+		t.Errorf("kept %d events, want %d", len(got["app-1"]), maxPodEvents)
+	}
+}
+
 func TestPodDiagnosticQuerier_QueryForService(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
@@ -277,6 +381,32 @@ func TestPodDiagnosticQuerier_QueryForService(t *testing.T) {
 		}
 	})
 
+	t.Run("unscheduled pod returns its warning events", func(t *testing.T) {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-abc", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		}
+		scheduling := &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "app-abc.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "app-abc", Namespace: "default"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "FailedScheduling",
+			Message:        "0/3 nodes are available: 3 Insufficient memory.",
+			Count:          2,
+		}
+		q := NewPodDiagnosticQuerier(fake.NewSimpleClientset(pod, scheduling), logger)
+		got := q.QueryForService(context.Background(), "default", []string{"app-abc"})
+		if got == nil {
+			t.Fatal("got nil, want non-nil")
+		}
+		if got.Reason != nil || len(got.Pods) != 1 || got.Pods[0].Node != "" {
+			t.Fatalf("got %+v, want one unscheduled pod without a reason", got)
+		}
+		if events := got.Pods[0].Events; len(events) != 1 || events[0].Reason != "FailedScheduling" || events[0].Count != 2 {
+			t.Errorf("events = %+v", events)
+		}
+	})
+
 	t.Run("mixed pods aggregate diagnostics", func(t *testing.T) {
 		pod1 := &corev1.Pod{
 			ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "default"},
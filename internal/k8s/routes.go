@@ -0,0 +1,343 @@
+package k8s
+
+import (
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	httpRouteGVR = schema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1",
+		Resource: "httproutes",
+	}
+
+	gatewayGVR = schema.GroupVersionResource{
+		Group:    "gateway.networking.k8s.io",
+		Version:  "v1",
+		Resource: "gateways",
+	}
+)
+
+// routeKind is a routing resource, other than Ingress, that services are
+// discovered from through the dynamic client. Adding a route CRD takes an
+// entry in routeKinds and a target function for it.
+type routeKind struct {
+	kind string // e.g. "HTTPRoute"; names the objects in logs and errors
+	// gvrs are the resources the kind is served as, in order of preference;
+	// the first one served is watched.
+	gvrs []schema.GroupVersionResource
+	// target reads the URL and backend of a route. It reports false if the
+	// route has no usable host.
+	target func(w *Watcher, route *unstructured.Unstructured) (routeTarget, bool)
+}
+
+// routeKinds are the routing resources discovered when the cluster serves them.
+var routeKinds = []routeKind{
+	{kind: "HTTPRoute", gvrs: []schema.GroupVersionResource{httpRouteGVR}, target: (*Watcher).httpRouteTarget},
+	{kind: "IngressRoute", gvrs: ingressRouteGVRs, target: (*Watcher).ingressRouteTarget},
+}
+
+// routeInformer is the informer of one enabled route kind.
+type routeInformer struct {
+	routeKind
+	lister cache.GenericLister
+}
+
+// EnableRoutes adds discovery of the route kinds the cluster serves, such
+// as Gateway API HTTPRoutes and Traefik IngressRoutes, through the dynamic
+// client, and returns the kinds enabled. Kinds whose resource is not served
+// are skipped, so that a missing CRD never holds up the initial sync. Call
+// it before Run.
+func (w *Watcher) EnableRoutes(client dynamic.Interface, disc discovery.DiscoveryInterface) []string {
+	// Routes are filtered by the scope's selectors; the Gateways they
+	// reference only by its namespaces.
+	namespaces := w.scope.informerNamespaces()
+	routeFactories := make([]dynamicinformer.DynamicSharedInformerFactory, len(namespaces))
+	gatewayFactories := make([]dynamicinformer.DynamicSharedInformerFactory, len(namespaces))
+	for i, ns := range namespaces {
+		routeFactories[i] = dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, ns, w.scope.tweak(true))
+		gatewayFactories[i] = dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, ns, w.scope.tweak(false))
+	}
+
+	var enabled []string
+	for _, rk := range routeKinds {
+		i := slices.IndexFunc(rk.gvrs, func(gvr schema.GroupVersionResource) bool {
+			return servesResource(disc, gvr)
+		})
+		if i < 0 {
+			continue
+		}
+		lister := &scopedLister{resource: rk.gvrs[i].GroupResource(), listers: make(map[string]cache.GenericLister)}
+		for j, ns := range namespaces {
+			informer := routeFactories[j].ForResource(rk.gvrs[i])
+			lister.listers[ns] = informer.Lister()
+			informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj interface{}) { w.onRoute(rk, obj, false) },
+				UpdateFunc: func(_, newObj interface{}) { w.onRoute(rk, newObj, true) },
+				DeleteFunc: func(obj interface{}) { w.onRouteDelete(rk, obj) },
+			})
+		}
+		w.routes = append(w.routes, routeInformer{routeKind: rk, lister: lister})
+		enabled = append(enabled, rk.kind)
+
+		if rk.kind == "HTTPRoute" && servesResource(disc, gatewayGVR) {
+			gateways := &scopedLister{resource: gatewayGVR.GroupResource(), listers: make(map[string]cache.GenericLister)}
+			for j, ns := range namespaces {
+				informer := gatewayFactories[j].ForResource(gatewayGVR)
+				gateways.listers[ns] = informer.Lister()
+				informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+					AddFunc:    w.onGateway,
+					UpdateFunc: func(_, newObj interface{}) { w.onGateway(newObj) },
+					DeleteFunc: w.onGateway,
+				})
+			}
+			w.gateways = gateways
+		}
+	}
+	if len(enabled) > 0 {
+		w.dynamicFactories = append(routeFactories, gatewayFactories...)
+	}
+	return enabled
+}
+
+// servesResource reports whether the API server serves gvr.
+func servesResource(disc discovery.DiscoveryInterface, gvr schema.GroupVersionResource) bool {
+	resources, err := disc.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(resources.APIResources, func(r metav1.APIResource) bool {
+		return r.Name == gvr.Resource
+	})
+}
+
+func (w *Watcher) onRoute(rk routeKind, obj interface{}, update bool) {
+	w.markK8sConnected()
+
+	route, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	var previous []string
+	if update {
+		previous = []string{route.GetName()}
+	}
+	ann := w.annotations(rk.kind, route)
+	var targets []routeTarget
+	if target, ok := rk.target(w, route); ok && !ann.Ignore {
+		targets = []routeTarget{target}
+	}
+	w.discover(rk.kind, route, ann, targets, previous)
+}
+
+func (w *Watcher) onRouteDelete(rk routeKind, obj interface{}) {
+	w.markK8sConnected()
+
+	route, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		route, ok = tombstone.Obj.(*unstructured.Unstructured)
+		if !ok {
+			return
+		}
+	}
+	w.forget(rk.kind, route, []string{route.GetName()})
+}
+
+// onGateway rediscovers the HTTPRoutes attached to a Gateway after it
+// changes, since their host and scheme may come from its listeners. A
+// route skipped for want of a host is picked up once its Gateway is cached.
+func (w *Watcher) onGateway(obj interface{}) {
+	gateway, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if gateway, ok = tombstone.Obj.(*unstructured.Unstructured); !ok {
+			return
+		}
+	}
+	for _, ri := range w.routes {
+		if ri.kind != "HTTPRoute" {
+			continue
+		}
+		routes, err := ri.lister.List(labels.Everything())
+		if err != nil {
+			return
+		}
+		for _, obj := range routes {
+			route, ok := obj.(*unstructured.Unstructured)
+			if !ok || !slices.ContainsFunc(gatewayParents(route), func(p parentRef) bool {
+				return p.namespace == gateway.GetNamespace() && p.name == gateway.GetName()
+			}) {
+				continue
+			}
+			w.onRoute(ri.routeKind, route, true)
+		}
+	}
+}
+
+// parentRef is a Gateway an HTTPRoute attaches to, optionally to one of
+// its listeners.
+type parentRef struct {
+	namespace string
+	name      string
+	section   string
+}
+
+// gatewayParents returns the Gateway parentRefs of an HTTPRoute.
+func gatewayParents(route *unstructured.Unstructured) []parentRef {
+	refs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	var out []parentRef
+	for _, r := range refs {
+		ref, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(ref, "group")
+		kind, _, _ := unstructured.NestedString(ref, "kind")
+		if (group != "" && group != gatewayGVR.Group) || (kind != "" && kind != "Gateway") {
+			continue
+		}
+		p := parentRef{namespace: route.GetNamespace()}
+		p.name, _, _ = unstructured.NestedString(ref, "name")
+		if ns, _, _ := unstructured.NestedString(ref, "namespace"); ns != "" {
+			p.namespace = ns
+		}
+		p.section, _, _ = unstructured.NestedString(ref, "sectionName")
+		out = append(out, p)
+	}
+	return out
+}
+
+// httpRouteTarget reads an HTTPRoute: its host is the first of its
+// hostnames that is not a wildcard, or else that of the listener it
+// attaches to. The scheme is https when that listener terminates TLS, and
+// the backend is the first Service of its first rule.
+func (w *Watcher) httpRouteTarget(route *unstructured.Unstructured) (routeTarget, bool) {
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	host := firstConcreteHost(hostnames)
+
+	var listeners []map[string]interface{}
+	for _, p := range gatewayParents(route) {
+		listeners = append(listeners, w.gatewayListeners(p)...)
+	}
+	if host == "" {
+		for _, l := range listeners {
+			hostname, _, _ := unstructured.NestedString(l, "hostname")
+			if host = firstConcreteHost([]string{hostname}); host != "" {
+				break
+			}
+		}
+	}
+	if host == "" {
+		return routeTarget{}, false
+	}
+
+	scheme := "http"
+	for _, l := range listeners {
+		hostname, _, _ := unstructured.NestedString(l, "hostname")
+		if hostname != "" && !hostMatches(hostname, host) {
+			continue
+		}
+		protocol, _, _ := unstructured.NestedString(l, "protocol")
+		_, hasTLS, _ := unstructured.NestedMap(l, "tls")
+		if protocol == "HTTPS" || hasTLS {
+			scheme = "https"
+			break
+		}
+	}
+
+	return routeTarget{url: scheme + "://" + host, host: host, backend: httpRouteBackend(route)}, true
+}
+
+// gatewayListeners returns the listeners of a parent Gateway that an
+// HTTPRoute attaches to: the one its sectionName names, or all of them.
+func (w *Watcher) gatewayListeners(p parentRef) []map[string]interface{} {
+	if w.gateways == nil {
+		return nil
+	}
+	obj, err := w.gateways.ByNamespace(p.namespace).Get(p.name)
+	if err != nil {
+		return nil
+	}
+	gateway, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	var out []map[string]interface{}
+	for _, l := range listeners {
+		listener, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(listener, "name"); p.section != "" && name != p.section {
+			continue
+		}
+		out = append(out, listener)
+	}
+	return out
+}
+
+// httpRouteBackend returns the first Service backendRef of an HTTPRoute's
+// first rule, if it is in the route's namespace.
+func httpRouteBackend(route *unstructured.Unstructured) string {
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if len(rules) == 0 {
+		return ""
+	}
+	rule, ok := rules[0].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	refs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+	for _, r := range refs {
+		ref, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(ref, "group")
+		kind, _, _ := unstructured.NestedString(ref, "kind")
+		ns, _, _ := unstructured.NestedString(ref, "namespace")
+		if group != "" || (kind != "" && kind != "Service") || (ns != "" && ns != route.GetNamespace()) {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(ref, "name")
+		return name
+	}
+	return ""
+}
+
+// firstConcreteHost returns the first of hosts that is not a wildcard.
+func firstConcreteHost(hosts []string) string {
+	for _, h := range hosts {
+		if h != "" && !strings.HasPrefix(h, "*") {
+			return h
+		}
+	}
+	return ""
+}
+
+// hostMatches reports whether a listener hostname, which may be a
+// "*.example.com" wildcard, matches host.
+func hostMatches(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return pattern == host
+}
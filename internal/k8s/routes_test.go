@@ -0,0 +1,362 @@
+package k8s
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestGateway(name, namespace string, listeners ...map[string]interface{}) *unstructured.Unstructured {
+	ls := make([]interface{}, len(listeners))
+	for i, l := range listeners {
+		ls[i] = l
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       map[string]interface{}{"gatewayClassName": "test", "listeners": ls},
+	}}
+}
+
+func newTestHTTPRoute(name, namespace string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       spec,
+	}}
+}
+
+func gatewayLister(t *testing.T, gateways ...*unstructured.Unstructured) cache.GenericLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, gw := range gateways {
+		if err := indexer.Add(gw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cache.NewGenericLister(indexer, gatewayGVR.GroupResource())
+}
+
+func TestHTTPRouteTarget(t *testing.T) {
+	gateways := gatewayLister(t,
+		newTestGateway("public", "infra",
+			map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80)},
+			map[string]interface{}{"name": "https", "protocol": "HTTPS", "port": int64(443), "hostname": "*.example.com"},
+		),
+		newTestGateway("internal", "infra",
+			map[string]interface{}{"name": "web", "protocol": "HTTP", "port": int64(80), "hostname": "intranet.lan"},
+		),
+	)
+	w := &Watcher{gateways: gateways}
+
+	parent := func(name, section string) map[string]interface{} {
+		ref := map[string]interface{}{"name": name, "namespace": "infra"}
+		if section != "" {
+			ref["sectionName"] = section
+		}
+		return ref
+	}
+	backend := func(refs ...map[string]interface{}) []interface{} {
+		out := make([]interface{}, len(refs))
+		for i, r := range refs {
+			out[i] = r
+		}
+		return []interface{}{map[string]interface{}{"backendRefs": out}}
+	}
+
+	tests := []struct {
+		name   string
+		spec   map[string]interface{}
+		want   routeTarget
+		wantOK bool
+	}{
+		{
+			name: "https listener",
+			spec: map[string]interface{}{
+				"parentRefs": []interface{}{parent("public", "")},
+				"hostnames":  []interface{}{"jellyfin.example.com"},
+				"rules":      backend(map[string]interface{}{"name": "jellyfin", "port": int64(8096)}),
+			},
+			want:   routeTarget{url: "https://jellyfin.example.com", host: "jellyfin.example.com", backend: "jellyfin"},
+			wantOK: true,
+		},
+		{
+			name: "plain http section",
+			spec: map[string]interface{}{
+				"parentRefs": []interface{}{parent("public", "http")},
+				"hostnames":  []interface{}{"jellyfin.example.com"},
+			},
+			want:   routeTarget{url: "http://jellyfin.example.com", host: "jellyfin.example.com"},
+			wantOK: true,
+		},
+		{
+			name: "wildcard hostname skipped",
+			spec: map[string]interface{}{
+				"parentRefs": []interface{}{parent("public", "")},
+				"hostnames":  []interface{}{"*.example.com", "app.example.com"},
+			},
+			want:   routeTarget{url: "https://app.example.com", host: "app.example.com"},
+			wantOK: true,
+		},
+		{
+			name: "host from listener",
+			spec: map[string]interface{}{
+				"parentRefs": []interface{}{parent("internal", "")},
+			},
+			want:   routeTarget{url: "http://intranet.lan", host: "intranet.lan"},
+			wantOK: true,
+		},
+		{
+			name: "listener hostname not matching host",
+			spec: map[string]interface{}{
+				"parentRefs": []interface{}{parent("public", "https")},
+				"hostnames":  []interface{}{"app.other.org"},
+			},
+			want:   routeTarget{url: "http://app.other.org", host: "app.other.org"},
+			wantOK: true,
+		},
+		{
+			name: "non-Service and cross-namespace backends skipped",
+			spec: map[string]interface{}{
+				"hostnames": []interface{}{"app.example.com"},
+				"rules": backend(
+					map[string]interface{}{"name": "bucket", "group": "storage.example.com", "kind": "Bucket"},
+					map[string]interface{}{"name": "remote", "namespace": "other"},
+					map[string]interface{}{"name": "local", "kind": "Service"},
+				),
+			},
+			want:   routeTarget{url: "http://app.example.com", host: "app.example.com", backend: "local"},
+			wantOK: true,
+		},
+		{
+			name: "missing gateway",
+			spec: map[string]interface{}{
+				"parentRefs": []interface{}{parent("gone", "")},
+				"hostnames":  []interface{}{"app.example.com"},
+			},
+			want:   routeTarget{url: "http://app.example.com", host: "app.example.com"},
+			wantOK: true,
+		},
+		{
+			name: "no host",
+			spec: map[string]interface{}{
+				"parentRefs": []interface{}{parent("public", "")},
+				"hostnames":  []interface{}{"*.example.com"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := w.httpRouteTarget(newTestHTTPRoute("app", "apps", tt.spec))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("httpRouteTarget() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// newRouteClients returns a clientset whose discovery serves the given
+// resources, and a dynamic client holding objs.
+func newRouteClients(t *testing.T, served []schema.GroupVersionResource, objs ...*unstructured.Unstructured) (*fake.Clientset, *fakedynamic.FakeDynamicClient) {
+	t.Helper()
+	clientset := fake.NewSimpleClientset()
+	listKinds := make(map[schema.GroupVersionResource]string)
+	lists := make(map[schema.GroupVersion]*metav1.APIResourceList)
+	for _, gvr := range served {
+		list, ok := lists[gvr.GroupVersion()]
+		if !ok {
+			list = &metav1.APIResourceList{GroupVersion: gvr.GroupVersion().String()}
+			lists[gvr.GroupVersion()] = list
+			clientset.Resources = append(clientset.Resources, list)
+		}
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: gvr.Resource, Namespaced: true})
+		listKinds[gvr] = routeResourceKinds[gvr.Resource] + "List"
+	}
+
+	dyn := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	// Added by resource: the tracker's guess from the kind would pluralize
+	// Gateway as "gatewaies".
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		var resource string
+		for r, kind := range routeResourceKinds {
+			if kind == gvk.Kind {
+				resource = r
+			}
+		}
+		if err := dyn.Tracker().Create(gvk.GroupVersion().WithResource(resource), obj, obj.GetNamespace()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return clientset, dyn
+}
+
+// routeResourceKinds maps the route resources used in tests to their kind.
+var routeResourceKinds = map[string]string{
+	"httproutes":    "HTTPRoute",
+	"gateways":      "Gateway",
+	"ingressroutes": "IngressRoute",
+}
+
+func TestEnableRoutesSkipsUnservedKinds(t *testing.T) {
+	clientset, dyn := newRouteClients(t, nil)
+	w := NewWatcherWithClient(clientset, &fakeStateUpdater{}, slog.Default())
+	if kinds := w.EnableRoutes(dyn, clientset.Discovery()); len(kinds) != 0 {
+		t.Errorf("EnableRoutes() = %v, want none", kinds)
+	}
+	if len(w.dynamicFactories) != 0 {
+		t.Error("expected no dynamic informers without served kinds")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Error("expected sync without route kinds")
+	}
+}
+
+func TestWatcherDiscoversHTTPRoutes(t *testing.T) {
+	gateway := newTestGateway("public", "infra",
+		map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80)})
+	route := newTestHTTPRoute("jellyfin", "media", map[string]interface{}{
+		"parentRefs": []interface{}{map[string]interface{}{"name": "public", "namespace": "infra"}},
+		"hostnames":  []interface{}{"jellyfin.example.com"},
+		"rules": []interface{}{map[string]interface{}{
+			"backendRefs": []interface{}{map[string]interface{}{"name": "jellyfin-svc", "port": int64(8096)}},
+		}},
+	})
+	route.SetAnnotations(map[string]string{IconAnnotation: "jellyfin"})
+
+	clientset, dyn := newRouteClients(t, []schema.GroupVersionResource{httpRouteGVR, gatewayGVR}, gateway, route)
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+	if kinds := w.EnableRoutes(dyn, clientset.Discovery()); len(kinds) != 1 || kinds[0] != "HTTPRoute" {
+		t.Fatalf("EnableRoutes() = %v, want [HTTPRoute]", kinds)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+
+	svc, ok := updater.Get("media", "jellyfin")
+	if !ok {
+		t.Fatal("expected the HTTPRoute's service")
+	}
+	if svc.URL != "http://jellyfin.example.com" || svc.Icon != "jellyfin" || svc.Source != state.SourceKubernetes {
+		t.Errorf("unexpected service %+v", svc)
+	}
+	w.endpointSliceWatcher.mu.Lock()
+	_, watched := w.endpointSliceWatcher.serviceToIngress["media/jellyfin-svc"]["jellyfin"]
+	w.endpointSliceWatcher.mu.Unlock()
+	if !watched {
+		t.Error("expected an EndpointSlice watch on the route's backend")
+	}
+
+	// Terminating TLS on the Gateway moves its routes to https.
+	unstructured.SetNestedSlice(gateway.Object, []interface{}{
+		map[string]interface{}{"name": "https", "protocol": "HTTPS", "port": int64(443)},
+	}, "spec", "listeners")
+	if _, err := dyn.Resource(gatewayGVR).Namespace("infra").Update(ctx, gateway, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		svc, _ := updater.Get("media", "jellyfin")
+		return svc.URL == "https://jellyfin.example.com"
+	})
+
+	if err := dyn.Resource(httpRouteGVR).Namespace("media").Delete(ctx, "jellyfin", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := updater.Get("media", "jellyfin"); return !ok })
+}
+
+func TestWatcherDiscoversHTTPRouteWhenGatewayArrives(t *testing.T) {
+	// The route's only host is its Gateway listener's, which is not cached
+	// yet.
+	route := newTestHTTPRoute("intranet", "apps", map[string]interface{}{
+		"parentRefs": []interface{}{map[string]interface{}{"name": "internal", "namespace": "infra"}},
+	})
+	clientset, dyn := newRouteClients(t, []schema.GroupVersionResource{httpRouteGVR, gatewayGVR}, route)
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+	w.EnableRoutes(dyn, clientset.Discovery())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+	if _, ok := updater.Get("apps", "intranet"); ok {
+		t.Fatal("expected no service before the Gateway exists")
+	}
+
+	gateway := newTestGateway("internal", "infra",
+		map[string]interface{}{"name": "web", "protocol": "HTTP", "port": int64(80), "hostname": "intranet.lan"})
+	if _, err := dyn.Resource(gatewayGVR).Namespace("infra").Create(ctx, gateway, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		svc, ok := updater.Get("apps", "intranet")
+		return ok && svc.URL == "http://intranet.lan"
+	})
+}
+
+func TestWatcherKeepsServiceSharedWithIngress(t *testing.T) {
+	route := newTestHTTPRoute("jellyfin", "media", map[string]interface{}{
+		"hostnames": []interface{}{"jellyfin.example.com"},
+	})
+	clientset, dyn := newRouteClients(t, []schema.GroupVersionResource{httpRouteGVR}, route)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := clientset.NetworkingV1().Ingresses("media").Create(ctx,
+		newTestIngress("jellyfin", "media", "jellyfin.example.com", true), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+	w.EnableRoutes(dyn, clientset.Discovery())
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+	if _, ok := updater.Get("media", "jellyfin"); !ok {
+		t.Fatal("expected the shared service")
+	}
+
+	// The Ingress still gives the service once the route is deleted.
+	if err := dyn.Resource(httpRouteGVR).Namespace("media").Delete(ctx, "jellyfin", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		w.ownersMu.Lock()
+		defer w.ownersMu.Unlock()
+		return !w.owners["media/jellyfin"]["HTTPRoute/jellyfin"]
+	})
+	if _, ok := updater.Get("media", "jellyfin"); !ok {
+		t.Fatal("service removed while the Ingress still gives it")
+	}
+
+	if err := clientset.NetworkingV1().Ingresses("media").Delete(ctx, "jellyfin", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := updater.Get("media", "jellyfin"); return !ok })
+}
@@ -0,0 +1,168 @@
+package k8s
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	networkingv1listers "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Scope limits discovery to some of a cluster's objects. The zero Scope
+// discovers every Ingress and route.
+//
+// With Namespaces set, informers are started in each of those namespaces
+// alone, and namespace labels are read for those namespaces by name, so
+// neither needs cluster-wide access; otherwise they watch the whole
+// cluster, less ExcludeNamespaces. LabelSelector and FieldSelector filter
+// the discovered objects on the API server. EndpointSlices and Gateways are
+// scoped by namespace only, since they are referenced by the discovered
+// objects rather than discovered themselves. Services, if set, adds
+// LoadBalancer and NodePort Services to the discovered objects. ExpandRules
+// expands every Ingress as ExpandRulesAnnotation does, unless annotated
+// "false".
+type Scope struct {
+	Namespaces        []string
+	ExcludeNamespaces []string
+	LabelSelector     string
+	FieldSelector     string
+	Services          *ServiceDiscovery
+	ExpandRules       bool
+}
+
+// informerNamespaces returns the namespaces to start informers in: each
+// included namespace, or all of them.
+func (s Scope) informerNamespaces() []string {
+	var out []string
+	for _, ns := range s.Namespaces {
+		if !slices.Contains(s.ExcludeNamespaces, ns) && !slices.Contains(out, ns) {
+			out = append(out, ns)
+		}
+	}
+	if len(s.Namespaces) == 0 {
+		out = []string{metav1.NamespaceAll}
+	}
+	return out
+}
+
+// tweak returns the list options of the scope's informers: the excluded
+// namespaces and, with selectors, the label and field selectors. It returns
+// nil when there is nothing to tweak.
+func (s Scope) tweak(selectors bool) func(*metav1.ListOptions) {
+	var fieldSelectors []string
+	if selectors && s.FieldSelector != "" {
+		fieldSelectors = append(fieldSelectors, s.FieldSelector)
+	}
+	if len(s.Namespaces) == 0 {
+		for _, ns := range s.ExcludeNamespaces {
+			fieldSelectors = append(fieldSelectors, "metadata.namespace!="+ns)
+		}
+	}
+	labelSelector := ""
+	if selectors {
+		labelSelector = s.LabelSelector
+	}
+	if labelSelector == "" && len(fieldSelectors) == 0 {
+		return nil
+	}
+	fieldSelector := strings.Join(fieldSelectors, ",")
+	return func(opts *metav1.ListOptions) {
+		opts.LabelSelector = labelSelector
+		opts.FieldSelector = fieldSelector
+	}
+}
+
+// scopedLister serves a resource from the informers of several namespaces,
+// keyed by namespace; the key "" holds the informer of all namespaces.
+type scopedLister struct {
+	resource schema.GroupResource
+	listers  map[string]cache.GenericLister
+}
+
+func (l *scopedLister) List(selector labels.Selector) ([]runtime.Object, error) {
+	var out []runtime.Object
+	for _, lister := range l.listers {
+		objs, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, objs...)
+	}
+	return out, nil
+}
+
+func (l *scopedLister) Get(name string) (runtime.Object, error) {
+	return nil, errors.NewNotFound(l.resource, name)
+}
+
+func (l *scopedLister) ByNamespace(namespace string) cache.GenericNamespaceLister {
+	if lister, ok := l.listers[namespace]; ok {
+		return lister.ByNamespace(namespace)
+	}
+	if lister, ok := l.listers[metav1.NamespaceAll]; ok {
+		return lister.ByNamespace(namespace)
+	}
+	// Out of scope: nothing to list.
+	return cache.NewGenericLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}), l.resource).ByNamespace(namespace)
+}
+
+// scopedIngressLister is the IngressLister over the Ingress informers of
+// several namespaces, keyed like those of scopedLister.
+type scopedIngressLister map[string]networkingv1listers.IngressLister
+
+func (l scopedIngressLister) List(selector labels.Selector) ([]*networkingv1.Ingress, error) {
+	var out []*networkingv1.Ingress
+	for _, lister := range l {
+		ingresses, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ingresses...)
+	}
+	return out, nil
+}
+
+func (l scopedIngressLister) Ingresses(namespace string) networkingv1listers.IngressNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.Ingresses(namespace)
+	}
+	if lister, ok := l[metav1.NamespaceAll]; ok {
+		return lister.Ingresses(namespace)
+	}
+	return networkingv1listers.NewIngressLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})).Ingresses(namespace)
+}
+
+// scopedNamespaceLister is the NamespaceLister over the Namespace informers
+// of several namespaces, each holding that namespace alone, or of the whole
+// cluster, keyed like those of scopedLister.
+type scopedNamespaceLister map[string]corev1listers.NamespaceLister
+
+func (l scopedNamespaceLister) List(selector labels.Selector) ([]*corev1.Namespace, error) {
+	var out []*corev1.Namespace
+	for _, lister := range l {
+		namespaces, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, namespaces...)
+	}
+	return out, nil
+}
+
+func (l scopedNamespaceLister) Get(name string) (*corev1.Namespace, error) {
+	if lister, ok := l[name]; ok {
+		return lister.Get(name)
+	}
+	if lister, ok := l[metav1.NamespaceAll]; ok {
+		return lister.Get(name)
+	}
+	return nil, errors.NewNotFound(corev1.Resource("namespaces"), name)
+}
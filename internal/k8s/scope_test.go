@@ -0,0 +1,155 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/rathix/command-center/internal/state"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScope(t *testing.T) {
+	tests := []struct {
+		name           string
+		scope          Scope
+		wantNamespaces []string
+		wantObjects    metav1.ListOptions
+		wantRefs       metav1.ListOptions
+		wantNilTweak   bool
+	}{
+		{
+			name:           "everything",
+			wantNamespaces: []string{""},
+			wantNilTweak:   true,
+		},
+		{
+			name:           "excluded namespaces",
+			scope:          Scope{ExcludeNamespaces: []string{"kube-system", "test"}, LabelSelector: "expose=true"},
+			wantNamespaces: []string{""},
+			wantObjects:    metav1.ListOptions{LabelSelector: "expose=true", FieldSelector: "metadata.namespace!=kube-system,metadata.namespace!=test"},
+			wantRefs:       metav1.ListOptions{FieldSelector: "metadata.namespace!=kube-system,metadata.namespace!=test"},
+		},
+		{
+			name: "included namespaces",
+			scope: Scope{
+				Namespaces:        []string{"media", "apps", "media", "test"},
+				ExcludeNamespaces: []string{"test"},
+				FieldSelector:     "metadata.name!=internal",
+			},
+			wantNamespaces: []string{"media", "apps"},
+			wantObjects:    metav1.ListOptions{FieldSelector: "metadata.name!=internal"},
+			wantNilTweak:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scope.informerNamespaces(); !slices.Equal(got, tt.wantNamespaces) {
+				t.Errorf("informerNamespaces() = %q, want %q", got, tt.wantNamespaces)
+			}
+			if tweak := tt.scope.tweak(true); tweak != nil {
+				var got metav1.ListOptions
+				tweak(&got)
+				if got != tt.wantObjects {
+					t.Errorf("object list options = %+v, want %+v", got, tt.wantObjects)
+				}
+			} else if tt.wantObjects != (metav1.ListOptions{}) {
+				t.Errorf("object tweak = nil, want %+v", tt.wantObjects)
+			}
+			tweak := tt.scope.tweak(false)
+			if tt.wantNilTweak != (tweak == nil) {
+				t.Fatalf("reference tweak nil = %v, want %v", tweak == nil, tt.wantNilTweak)
+			}
+			if tweak != nil {
+				var got metav1.ListOptions
+				tweak(&got)
+				if got != tt.wantRefs {
+					t.Errorf("reference list options = %+v, want %+v", got, tt.wantRefs)
+				}
+			}
+		})
+	}
+}
+
+func TestWatcherScope(t *testing.T) {
+	exposed := newTestIngressWithBackend("jellyfin", "media", "jellyfin.example.com", true, "jellyfin", 8096)
+	exposed.Labels = map[string]string{"expose": "true"}
+	unlabeled := newTestIngress("internal", "media", "internal.example.com", true)
+	elsewhere := newTestIngress("grafana", "monitoring", "grafana.example.com", true)
+	elsewhere.Labels = map[string]string{"expose": "true"}
+
+	clientset := fake.NewSimpleClientset(exposed, unlabeled, elsewhere)
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default(),
+		WithScope(Scope{Namespaces: []string{"media"}, LabelSelector: "expose=true"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+
+	for _, key := range [][2]string{{"media", "internal"}, {"monitoring", "grafana"}} {
+		if _, ok := updater.Get(key[0], key[1]); ok {
+			t.Errorf("out-of-scope Ingress %s/%s should not be discovered", key[0], key[1])
+		}
+	}
+	if _, ok := updater.Get("media", "jellyfin"); !ok {
+		t.Error("expected the in-scope Ingress to be discovered")
+	}
+	if _, ok := w.endpointSliceWatcher.factories["media"]; !ok || len(w.endpointSliceWatcher.factories) != 1 {
+		t.Errorf("expected EndpointSlices watched in media alone, got %d factories", len(w.endpointSliceWatcher.factories))
+	}
+	if got, _ := w.lister.Ingresses("monitoring").List(labels.Everything()); len(got) != 0 {
+		t.Errorf("out-of-scope namespace listed %d Ingresses", len(got))
+	}
+}
+
+func TestWatcherScopeWithoutNamespaceAccess(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newTestIngress("jellyfin", "media", "jellyfin.example.com", true))
+	var (
+		mu        sync.Mutex
+		selectors []string
+	)
+	// Only Roles in the included namespaces: Namespaces are forbidden.
+	clientset.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		selectors = append(selectors, action.(k8stesting.ListAction).GetListRestrictions().Fields.String())
+		mu.Unlock()
+		return true, nil, apierrors.NewForbidden(corev1.Resource("namespaces"), "", errors.New("no access"))
+	})
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default(), WithScope(Scope{Namespaces: []string{"media"}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher should sync without namespace labels")
+	}
+	if _, ok := updater.Get("media", "jellyfin"); !ok {
+		t.Error("expected the Ingress to be discovered")
+	}
+	updater.mu.Lock()
+	connected := updater.k8sConnected
+	updater.mu.Unlock()
+	if !connected {
+		t.Error("expected the cluster to be reported connected")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(selectors) == 0 || selectors[0] != "metadata.name=media" {
+		t.Errorf("namespace list field selectors = %q, want metadata.name=media", selectors)
+	}
+}
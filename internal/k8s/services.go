@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"cmp"
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ServiceDiscovery discovers the core/v1 Services of type LoadBalancer and
+// NodePort, which expose an app without an Ingress or route: those annotated
+// with DiscoverAnnotation, or all of them with All.
+//
+// A LoadBalancer Service's URL is on its load balancer's IP or hostname and
+// service port; a NodePort Service's is on NodeAddress, a node or a proxy in
+// front of the nodes, and its node port. Without NodeAddress, NodePort
+// Services are skipped. Their readiness is that of the Service's own
+// EndpointSlices.
+type ServiceDiscovery struct {
+	All         bool
+	NodeAddress string
+}
+
+// onService discovers a Service once eligible.
+func (w *Watcher) onService(obj interface{}) {
+	w.markK8sConnected()
+
+	svc, ok := obj.(*corev1.Service)
+	if !ok || !w.serviceEligible(svc) {
+		return
+	}
+	ann := w.annotations("Service", svc)
+	w.discover("Service", svc, ann, w.serviceTargets(svc, ann), nil)
+}
+
+func (w *Watcher) onServiceUpdate(oldObj, newObj interface{}) {
+	w.markK8sConnected()
+
+	svc, ok := newObj.(*corev1.Service)
+	if !ok {
+		return
+	}
+	var previous []string
+	if old, ok := oldObj.(*corev1.Service); ok {
+		previous = w.exposedServiceNames(old)
+	}
+	if !w.serviceEligible(svc) {
+		// No longer opted in, or no longer exposed outside the cluster.
+		if previous != nil {
+			w.forget("Service", svc, previous)
+		}
+		return
+	}
+	ann := w.annotations("Service", svc)
+	w.discover("Service", svc, ann, w.serviceTargets(svc, ann), previous)
+}
+
+func (w *Watcher) onServiceDelete(obj interface{}) {
+	w.markK8sConnected()
+
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		svc, ok = tombstone.Obj.(*corev1.Service)
+		if !ok {
+			return
+		}
+	}
+	if w.serviceEligible(svc) {
+		w.forget("Service", svc, w.exposedServiceNames(svc))
+	}
+}
+
+// serviceEligible reports whether a Service is discovered at all: a
+// LoadBalancer or NodePort Service, opted in unless every one is.
+func (w *Watcher) serviceEligible(svc *corev1.Service) bool {
+	if w.scope.Services == nil {
+		return false
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && svc.Spec.Type != corev1.ServiceTypeNodePort {
+		return false
+	}
+	if w.scope.Services.All {
+		return true
+	}
+	discover, _ := strconv.ParseBool(strings.TrimSpace(svc.Annotations[DiscoverAnnotation]))
+	return discover
+}
+
+// exposedServiceNames returns the names of the services a Service gives:
+// nil if it is not eligible.
+func (w *Watcher) exposedServiceNames(svc *corev1.Service) []string {
+	if !w.serviceEligible(svc) {
+		return nil
+	}
+	ann, _ := parseAnnotations(svc.Annotations)
+	return serviceNames(svc.Name, w.serviceTargets(svc, ann))
+}
+
+// serviceTargets returns the target of an eligible Service: none if it is
+// ignored or has no address yet.
+func (w *Watcher) serviceTargets(svc *corev1.Service, ann serviceAnnotations) []routeTarget {
+	if ann.Ignore {
+		return nil
+	}
+	if target, ok := serviceTarget(svc, ann, w.scope.Services.NodeAddress); ok {
+		return []routeTarget{target}
+	}
+	return nil
+}
+
+// serviceTarget reads the target of a LoadBalancer or NodePort Service, on
+// the port selected by PortAnnotation. It reports false if the Service has
+// no such port, or no address: a load balancer still pending, or a NodePort
+// Service without nodeAddress.
+func serviceTarget(svc *corev1.Service, ann serviceAnnotations, nodeAddress string) (routeTarget, bool) {
+	port, ok := servicePort(svc, ann.Port)
+	if !ok {
+		return routeTarget{}, false
+	}
+	var (
+		host   string
+		number int32
+	)
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if host = cmp.Or(ingress.IP, ingress.Hostname); host != "" {
+				break
+			}
+		}
+		number = port.Port
+	case corev1.ServiceTypeNodePort:
+		host = nodeAddress
+		number = port.NodePort
+	}
+	if host == "" || number == 0 {
+		return routeTarget{}, false
+	}
+	scheme := cmp.Or(ann.Scheme, portScheme(port))
+	return routeTarget{
+		url:     scheme + "://" + net.JoinHostPort(host, strconv.Itoa(int(number))),
+		host:    host,
+		display: svc.Name,
+		backend: svc.Name,
+	}, true
+}
+
+// servicePort returns the Service's port named or numbered selector, or its
+// first port when selector is empty.
+func servicePort(svc *corev1.Service, selector string) (corev1.ServicePort, bool) {
+	for _, port := range svc.Spec.Ports {
+		if selector == "" || port.Name == selector || strconv.Itoa(int(port.Port)) == selector {
+			return port, true
+		}
+	}
+	return corev1.ServicePort{}, false
+}
+
+// portScheme returns "https" for port 443 or a port named or with the
+// application protocol https, else "http".
+func portScheme(port corev1.ServicePort) string {
+	if port.Port == 443 || port.Name == "https" || (port.AppProtocol != nil && *port.AppProtocol == "https") {
+		return "https"
+	}
+	return "http"
+}
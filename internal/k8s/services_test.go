@@ -0,0 +1,207 @@
+package k8s
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/rathix/command-center/internal/state"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestService(name, namespace string, typ corev1.ServiceType, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.ServiceSpec{Type: typ, Ports: ports},
+	}
+}
+
+func withLoadBalancer(svc *corev1.Service, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+	svc.Status.LoadBalancer.Ingress = ingress
+	return svc
+}
+
+func TestServiceTarget(t *testing.T) {
+	https := "https"
+	web := corev1.ServicePort{Name: "web", Port: 8080, NodePort: 30080}
+	tls := corev1.ServicePort{Name: "tls", Port: 443, NodePort: 30443}
+
+	tests := []struct {
+		name        string
+		svc         *corev1.Service
+		ann         serviceAnnotations
+		nodeAddress string
+		want        routeTarget
+		wantOK      bool
+	}{
+		{
+			name:   "load balancer IP",
+			svc:    withLoadBalancer(newTestService("plex", "media", corev1.ServiceTypeLoadBalancer, web), corev1.LoadBalancerIngress{IP: "192.168.1.50"}),
+			want:   routeTarget{url: "http://192.168.1.50:8080", host: "192.168.1.50", display: "plex", backend: "plex"},
+			wantOK: true,
+		},
+		{
+			name:   "load balancer hostname and https port",
+			svc:    withLoadBalancer(newTestService("plex", "media", corev1.ServiceTypeLoadBalancer, web, tls), corev1.LoadBalancerIngress{Hostname: "lb.example.com"}),
+			ann:    serviceAnnotations{Port: "443"},
+			want:   routeTarget{url: "https://lb.example.com:443", host: "lb.example.com", display: "plex", backend: "plex"},
+			wantOK: true,
+		},
+		{
+			name: "app protocol and IPv6",
+			svc: withLoadBalancer(newTestService("plex", "media", corev1.ServiceTypeLoadBalancer,
+				corev1.ServicePort{Port: 32400, AppProtocol: &https}), corev1.LoadBalancerIngress{IP: "fd00::50"}),
+			want:   routeTarget{url: "https://[fd00::50]:32400", host: "fd00::50", display: "plex", backend: "plex"},
+			wantOK: true,
+		},
+		{
+			name:   "scheme annotation",
+			svc:    withLoadBalancer(newTestService("minecraft", "games", corev1.ServiceTypeLoadBalancer, corev1.ServicePort{Port: 25565}), corev1.LoadBalancerIngress{IP: "192.168.1.51"}),
+			ann:    serviceAnnotations{Scheme: "tcp"},
+			want:   routeTarget{url: "tcp://192.168.1.51:25565", host: "192.168.1.51", display: "minecraft", backend: "minecraft"},
+			wantOK: true,
+		},
+		{
+			name: "pending load balancer",
+			svc:  newTestService("plex", "media", corev1.ServiceTypeLoadBalancer, web),
+		},
+		{
+			name:        "node port by name",
+			svc:         newTestService("plex", "media", corev1.ServiceTypeNodePort, tls, web),
+			ann:         serviceAnnotations{Port: "web"},
+			nodeAddress: "node1.lan",
+			want:        routeTarget{url: "http://node1.lan:30080", host: "node1.lan", display: "plex", backend: "plex"},
+			wantOK:      true,
+		},
+		{
+			name: "node port without node address",
+			svc:  newTestService("plex", "media", corev1.ServiceTypeNodePort, web),
+		},
+		{
+			name:        "unknown port",
+			svc:         newTestService("plex", "media", corev1.ServiceTypeNodePort, web),
+			ann:         serviceAnnotations{Port: "metrics"},
+			nodeAddress: "node1.lan",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := serviceTarget(tt.svc, tt.ann, tt.nodeAddress)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("serviceTarget() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWatcherDiscoversServices(t *testing.T) {
+	plex := withLoadBalancer(newTestService("plex", "media", corev1.ServiceTypeLoadBalancer, corev1.ServicePort{Port: 32400}),
+		corev1.LoadBalancerIngress{IP: "192.168.1.50"})
+	plex.Annotations = map[string]string{DiscoverAnnotation: "true", ProbeAnnotation: "tcp"}
+	unannotated := withLoadBalancer(newTestService("dns", "infra", corev1.ServiceTypeLoadBalancer, corev1.ServicePort{Port: 53}),
+		corev1.LoadBalancerIngress{IP: "192.168.1.53"})
+	internal := newTestService("postgres", "media", corev1.ServiceTypeClusterIP, corev1.ServicePort{Port: 5432})
+	internal.Annotations = map[string]string{DiscoverAnnotation: "true"}
+
+	clientset := fake.NewSimpleClientset(plex, unannotated, internal)
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default(), WithScope(Scope{Services: &ServiceDiscovery{}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+
+	svc, ok := updater.Get("media", "plex")
+	if !ok {
+		t.Fatal("expected the annotated LoadBalancer Service")
+	}
+	if svc.URL != "http://192.168.1.50:32400" || svc.DisplayName != "plex" || svc.Probe == nil || svc.Probe.Type != state.ProbeTCP {
+		t.Errorf("unexpected service %+v", svc)
+	}
+	w.endpointSliceWatcher.mu.Lock()
+	_, watched := w.endpointSliceWatcher.serviceToIngress["media/plex"]["plex"]
+	w.endpointSliceWatcher.mu.Unlock()
+	if !watched {
+		t.Error("expected an EndpointSlice watch on the Service itself")
+	}
+	for _, key := range [][2]string{{"infra", "dns"}, {"media", "postgres"}} {
+		if _, ok := updater.Get(key[0], key[1]); ok {
+			t.Errorf("Service %s/%s should not be discovered", key[0], key[1])
+		}
+	}
+
+	// Opting out removes the service.
+	plex = plex.DeepCopy()
+	plex.Annotations[DiscoverAnnotation] = "false"
+	if _, err := clientset.CoreV1().Services("media").Update(ctx, plex, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := updater.Get("media", "plex"); return !ok })
+}
+
+func TestWatcherIgnoresServicesByDefault(t *testing.T) {
+	plex := withLoadBalancer(newTestService("plex", "media", corev1.ServiceTypeLoadBalancer, corev1.ServicePort{Port: 32400}),
+		corev1.LoadBalancerIngress{IP: "192.168.1.50"})
+	plex.Annotations = map[string]string{DiscoverAnnotation: "true"}
+
+	clientset := fake.NewSimpleClientset(plex)
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+	if _, ok := updater.Get("media", "plex"); ok {
+		t.Error("Services should not be discovered without ServiceDiscovery")
+	}
+	if w.services != nil {
+		t.Error("expected no Service informer without ServiceDiscovery")
+	}
+}
+
+func TestWatcherKeepsIngressSharedWithService(t *testing.T) {
+	plex := withLoadBalancer(newTestService("plex", "media", corev1.ServiceTypeLoadBalancer, corev1.ServicePort{Port: 32400}),
+		corev1.LoadBalancerIngress{IP: "192.168.1.50"})
+	plex.Annotations = map[string]string{DiscoverAnnotation: "true"}
+
+	clientset := fake.NewSimpleClientset(plex, newTestIngress("plex", "media", "plex.example.com", true))
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default(), WithScope(Scope{Services: &ServiceDiscovery{}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+	if _, ok := updater.Get("media", "plex"); !ok {
+		t.Fatal("expected the shared service")
+	}
+
+	// Deleting the Service keeps the service the Ingress still gives.
+	if err := clientset.CoreV1().Services("media").Delete(ctx, "plex", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		w.ownersMu.Lock()
+		defer w.ownersMu.Unlock()
+		return !w.owners["media/plex"]["Service/plex"]
+	})
+	if _, ok := updater.Get("media", "plex"); !ok {
+		t.Fatal("service removed while the Ingress still gives it")
+	}
+
+	if err := clientset.NetworkingV1().Ingresses("media").Delete(ctx, "plex", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := updater.Get("media", "plex"); return !ok })
+}
@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ingressRouteGVRs are the resources of Traefik's IngressRoute: the
+// traefik.io group of Traefik v2.10 and later, then the older
+// traefik.containo.us group.
+var ingressRouteGVRs = []schema.GroupVersionResource{
+	{Group: "traefik.io", Version: "v1alpha1", Resource: "ingressroutes"},
+	{Group: "traefik.containo.us", Version: "v1alpha1", Resource: "ingressroutes"},
+}
+
+var (
+	// hostMatcher finds the Host() and HostHeader() matchers of a Traefik
+	// rule, but not HostRegexp() or HostSNI().
+	hostMatcher = regexp.MustCompile("(?:^|[^A-Za-z])Host(?:Header)?\\(([^)]*)\\)")
+	// matcherArg is a quoted argument of a matcher.
+	matcherArg = regexp.MustCompile("`([^`]*)`|\"([^\"]*)\"|'([^']*)'")
+)
+
+// ingressRouteTarget reads a Traefik IngressRoute: its host is the first
+// one, not a wildcard, in the Host() matchers of its routes, and its
+// backend is the first Service of that route. The scheme is https when the
+// route has a tls section.
+func (w *Watcher) ingressRouteTarget(route *unstructured.Unstructured) (routeTarget, bool) {
+	routes, _, _ := unstructured.NestedSlice(route.Object, "spec", "routes")
+	for _, r := range routes {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		match, _, _ := unstructured.NestedString(rule, "match")
+		host := firstConcreteHost(matchHosts(match))
+		if host == "" {
+			continue
+		}
+		scheme := "http"
+		if _, hasTLS, _ := unstructured.NestedFieldNoCopy(route.Object, "spec", "tls"); hasTLS {
+			scheme = "https"
+		}
+		return routeTarget{
+			url:     scheme + "://" + host,
+			host:    host,
+			backend: ingressRouteBackend(rule, route.GetNamespace()),
+		}, true
+	}
+	return routeTarget{}, false
+}
+
+// matchHosts returns the hosts in the Host() matchers of a Traefik rule,
+// e.g. "Host(`a.example.com`) && PathPrefix(`/api`)".
+func matchHosts(match string) []string {
+	var hosts []string
+	for _, m := range hostMatcher.FindAllStringSubmatch(match, -1) {
+		for _, arg := range matcherArg.FindAllStringSubmatch(m[1], -1) {
+			hosts = append(hosts, arg[1]+arg[2]+arg[3])
+		}
+	}
+	return hosts
+}
+
+// ingressRouteBackend returns the first Kubernetes Service among the
+// services of an IngressRoute's route, if it is in namespace.
+func ingressRouteBackend(rule map[string]interface{}, namespace string) string {
+	services, _, _ := unstructured.NestedSlice(rule, "services")
+	for _, s := range services {
+		ref, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _, _ := unstructured.NestedString(ref, "kind")
+		ns, _, _ := unstructured.NestedString(ref, "namespace")
+		if (kind != "" && kind != "Service") || (ns != "" && ns != namespace) {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(ref, "name")
+		return name
+	}
+	return ""
+}
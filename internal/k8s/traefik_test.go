@@ -0,0 +1,195 @@
+package k8s
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/rathix/command-center/internal/state"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newTestIngressRoute(apiVersion, name, namespace string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       "IngressRoute",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       spec,
+	}}
+}
+
+func TestMatchHosts(t *testing.T) {
+	tests := []struct {
+		match string
+		want  []string
+	}{
+		{"Host(`app.example.com`)", []string{"app.example.com"}},
+		{"Host(`a.example.com`, `b.example.com`) && PathPrefix(`/api`)", []string{"a.example.com", "b.example.com"}},
+		{"Host(`a.example.com`) || Host(\"b.example.com\")", []string{"a.example.com", "b.example.com"}},
+		{"HostHeader(`legacy.example.com`)", []string{"legacy.example.com"}},
+		{"HostRegexp(`^.+\\.example\\.com$`)", nil},
+		{"PathPrefix(`/`)", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.match, func(t *testing.T) {
+			if got := matchHosts(tt.match); !slices.Equal(got, tt.want) {
+				t.Errorf("matchHosts(%q) = %v, want %v", tt.match, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressRouteTarget(t *testing.T) {
+	route := func(match string, services ...map[string]interface{}) map[string]interface{} {
+		svcs := make([]interface{}, len(services))
+		for i, s := range services {
+			svcs[i] = s
+		}
+		return map[string]interface{}{"kind": "Rule", "match": match, "services": svcs}
+	}
+
+	tests := []struct {
+		name   string
+		spec   map[string]interface{}
+		want   routeTarget
+		wantOK bool
+	}{
+		{
+			name: "tls",
+			spec: map[string]interface{}{
+				"routes": []interface{}{route("Host(`jellyfin.example.com`)", map[string]interface{}{"name": "jellyfin", "port": int64(8096)})},
+				"tls":    map[string]interface{}{"certResolver": "letsencrypt"},
+			},
+			want:   routeTarget{url: "https://jellyfin.example.com", host: "jellyfin.example.com", backend: "jellyfin"},
+			wantOK: true,
+		},
+		{
+			name: "empty tls section",
+			spec: map[string]interface{}{
+				"routes": []interface{}{route("Host(`app.example.com`)")},
+				"tls":    map[string]interface{}{},
+			},
+			want:   routeTarget{url: "https://app.example.com", host: "app.example.com"},
+			wantOK: true,
+		},
+		{
+			name: "plain http",
+			spec: map[string]interface{}{
+				"routes": []interface{}{route("Host(`app.example.com`) && PathPrefix(`/`)")},
+			},
+			want:   routeTarget{url: "http://app.example.com", host: "app.example.com"},
+			wantOK: true,
+		},
+		{
+			name: "first route with a host",
+			spec: map[string]interface{}{
+				"routes": []interface{}{
+					route("PathPrefix(`/metrics`)", map[string]interface{}{"name": "metrics"}),
+					route("Host(`app.example.com`)",
+						map[string]interface{}{"name": "weighted", "kind": "TraefikService"},
+						map[string]interface{}{"name": "remote", "namespace": "other"},
+						map[string]interface{}{"name": "app", "kind": "Service"},
+					),
+				},
+			},
+			want:   routeTarget{url: "http://app.example.com", host: "app.example.com", backend: "app"},
+			wantOK: true,
+		},
+		{
+			name: "no host",
+			spec: map[string]interface{}{
+				"routes": []interface{}{route("HostRegexp(`.+`)")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := (&Watcher{}).ingressRouteTarget(newTestIngressRoute("traefik.io/v1alpha1", "app", "apps", tt.spec))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ingressRouteTarget() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWatcherDiscoversIngressRoutes(t *testing.T) {
+	// Only the older traefik.containo.us group is served.
+	legacy := schema.GroupVersionResource{Group: "traefik.containo.us", Version: "v1alpha1", Resource: "ingressroutes"}
+	route := newTestIngressRoute("traefik.containo.us/v1alpha1", "grafana", "monitoring", map[string]interface{}{
+		"routes": []interface{}{map[string]interface{}{
+			"kind":     "Rule",
+			"match":    "Host(`grafana.example.com`)",
+			"services": []interface{}{map[string]interface{}{"name": "grafana", "port": int64(3000)}},
+		}},
+		"tls": map[string]interface{}{},
+	})
+
+	clientset, dyn := newRouteClients(t, []schema.GroupVersionResource{legacy}, route)
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+	if kinds := w.EnableRoutes(dyn, clientset.Discovery()); !slices.Equal(kinds, []string{"IngressRoute"}) {
+		t.Fatalf("EnableRoutes() = %v, want [IngressRoute]", kinds)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+
+	svc, ok := updater.Get("monitoring", "grafana")
+	if !ok || svc.URL != "https://grafana.example.com" || svc.DisplayName != "grafana" {
+		t.Fatalf("unexpected service %+v (found %v)", svc, ok)
+	}
+	w.endpointSliceWatcher.mu.Lock()
+	_, watched := w.endpointSliceWatcher.serviceToIngress["monitoring/grafana"]["grafana"]
+	w.endpointSliceWatcher.mu.Unlock()
+	if !watched {
+		t.Error("expected an EndpointSlice watch on the route's Service")
+	}
+}
+
+func TestWatcherKeepsIngressRouteServiceSharedWithIngress(t *testing.T) {
+	route := newTestIngressRoute("traefik.io/v1alpha1", "grafana", "monitoring", map[string]interface{}{
+		"routes": []interface{}{map[string]interface{}{"kind": "Rule", "match": "Host(`grafana.example.com`)"}},
+	})
+	clientset, dyn := newRouteClients(t, ingressRouteGVRs[:1], route)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := clientset.NetworkingV1().Ingresses("monitoring").Create(ctx,
+		newTestIngress("grafana", "monitoring", "grafana.example.com", true), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+	w.EnableRoutes(dyn, clientset.Discovery())
+	go w.Run(ctx)
+	if !w.WaitForSync(ctx) {
+		t.Fatal("watcher did not sync")
+	}
+
+	// Deleting the Ingress keeps the service the IngressRoute still gives.
+	if err := clientset.NetworkingV1().Ingresses("monitoring").Delete(ctx, "grafana", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		w.ownersMu.Lock()
+		defer w.ownersMu.Unlock()
+		return !w.owners["monitoring/grafana"]["Ingress/grafana"]
+	})
+	if _, ok := updater.Get("monitoring", "grafana"); !ok {
+		t.Fatal("service removed while the IngressRoute still gives it")
+	}
+
+	if err := dyn.Resource(ingressRouteGVRs[0]).Namespace("monitoring").Delete(ctx, "grafana", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := updater.Get("monitoring", "grafana"); return !ok })
+}
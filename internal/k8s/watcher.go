@@ -1,22 +1,31 @@
 package k8s
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/rathix/command-center/internal/state"
 
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	networkingv1listers "k8s.io/client-go/listers/networking/v1"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // StateUpdater is the interface the watcher uses to update service state.
@@ -27,6 +36,7 @@ type StateUpdater interface {
 	Remove(namespace, name string)
 	SetK8sConnected(connected bool)
 	Update(namespace, name string, fn func(*state.Service))
+	SetObjectErrors(kind, namespace, name string, errs []string)
 }
 
 // IngressLister defines the subset of the Kubernetes Ingress lister
@@ -38,21 +48,50 @@ type IngressLister interface {
 
 // Watcher watches Kubernetes Ingress resources and updates the state store.
 type Watcher struct {
-	factory              informers.SharedInformerFactory
+	namespaceFactories   []informers.SharedInformerFactory // labels only; see namespaceInformers
+	ingressFactories     []informers.SharedInformerFactory
 	lister               IngressLister
+	namespaces           corev1listers.NamespaceLister
 	updater              StateUpdater
 	logger               *slog.Logger
+	scope                Scope
 	k8sConnected         atomic.Bool
 	cacheSynced          atomic.Bool
 	syncedCh             chan struct{}
 	syncOnce             sync.Once
 	endpointSliceWatcher *EndpointSliceWatcher
+
+	// Route kinds discovered through the dynamic client; see EnableRoutes.
+	dynamicFactories []dynamicinformer.DynamicSharedInformerFactory
+	routes           []routeInformer
+	gateways         cache.GenericLister
+
+	// LoadBalancer and NodePort Services, with Scope.Services; see
+	// ServiceDiscovery.
+	services cache.GenericLister
+
+	// The objects, as "kind/name", that give each discovered service, by
+	// "namespace/name": an Ingress, a route and a Service of the same name
+	// share one. The service is removed with its last owner.
+	ownersMu sync.Mutex
+	owners   map[string]map[string]bool
+}
+
+// WatcherOption configures a Watcher.
+type WatcherOption func(*Watcher)
+
+// WithScope limits discovery to the objects in scope.
+func WithScope(scope Scope) WatcherOption {
+	return func(w *Watcher) {
+		w.scope = scope
+	}
 }
 
-// NewWatcher creates a Watcher from a kubeconfig path. Supports both
-// external kubeconfig files and in-cluster config (when kubeconfigPath is "").
-func NewWatcher(kubeconfigPath string, updater StateUpdater, logger *slog.Logger) (*Watcher, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+// NewWatcher creates a Watcher from a kubeconfig path and context (empty
+// for its current context). Supports both external kubeconfig files and
+// in-cluster config (when kubeconfigPath and kubeContext are "").
+func NewWatcher(kubeconfigPath, kubeContext string, updater StateUpdater, logger *slog.Logger, opts ...WatcherOption) (*Watcher, error) {
+	config, err := BuildConfig(kubeconfigPath, kubeContext)
 	if err != nil {
 		return nil, fmt.Errorf("k8s watcher: failed to build configuration from kubeconfig")
 	}
@@ -62,61 +101,145 @@ func NewWatcher(kubeconfigPath string, updater StateUpdater, logger *slog.Logger
 		return nil, fmt.Errorf("k8s watcher: failed to create Kubernetes client")
 	}
 
-	return NewWatcherWithClient(clientset, updater, logger), nil
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("k8s watcher: failed to create dynamic client")
+	}
+
+	w := NewWatcherWithClient(clientset, updater, logger, opts...)
+	if kinds := w.EnableRoutes(dynamicClient, clientset.Discovery()); len(kinds) > 0 {
+		logger.Info("route discovery enabled", "kinds", kinds)
+	}
+	return w, nil
 }
 
 // NewWatcherWithClient creates a Watcher from an existing Kubernetes clientset.
 // This is primarily used for testing with fake clientsets.
-func NewWatcherWithClient(clientset kubernetes.Interface, updater StateUpdater, logger *slog.Logger) *Watcher {
-	return NewWatcherWithClientAndESWatcher(clientset, updater, logger, nil)
+func NewWatcherWithClient(clientset kubernetes.Interface, updater StateUpdater, logger *slog.Logger, opts ...WatcherOption) *Watcher {
+	return NewWatcherWithClientAndESWatcher(clientset, updater, logger, nil, opts...)
 }
 
 // NewWatcherWithClientAndESWatcher allows injecting a custom EndpointSliceWatcher.
-func NewWatcherWithClientAndESWatcher(clientset kubernetes.Interface, updater StateUpdater, logger *slog.Logger, esWatcher *EndpointSliceWatcher) *Watcher {
-	factory := informers.NewSharedInformerFactory(clientset, 0)
-	ingressInformer := factory.Networking().V1().Ingresses()
-
-	if esWatcher == nil {
-		esWatcher = NewEndpointSliceWatcher(clientset, updater, logger)
-	}
-
+func NewWatcherWithClientAndESWatcher(clientset kubernetes.Interface, updater StateUpdater, logger *slog.Logger, esWatcher *EndpointSliceWatcher, opts ...WatcherOption) *Watcher {
 	w := &Watcher{
-		factory:              factory,
-		lister:               ingressInformer.Lister(),
-		updater:              updater,
-		logger:               logger,
-		syncedCh:             make(chan struct{}),
-		endpointSliceWatcher: esWatcher,
+		updater:  updater,
+		logger:   logger,
+		syncedCh: make(chan struct{}),
+		owners:   make(map[string]map[string]bool),
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	// Namespaces are watched for their labels; Ingresses, and Services when
+	// discovered, in each namespace of the scope.
+	w.namespaceInformers(clientset)
+
+	listers := make(scopedIngressLister)
+	serviceListers := make(map[string]cache.GenericLister)
+	for _, ns := range w.scope.informerNamespaces() {
+		factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+			informers.WithNamespace(ns),
+			informers.WithTweakListOptions(w.scope.tweak(true)))
+		w.ingressFactories = append(w.ingressFactories, factory)
+		ingressInformer := factory.Networking().V1().Ingresses()
+		listers[ns] = ingressInformer.Lister()
+
+		if err := ingressInformer.Informer().SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+			w.logger.Warn("k8s API watch error")
+			if w.k8sConnected.CompareAndSwap(true, false) {
+				w.updater.SetK8sConnected(false)
+			}
+		}); err != nil {
+			w.logger.Warn("failed to set k8s watch error handler", "error", err)
+		}
 
-	if err := ingressInformer.Informer().SetWatchErrorHandler(func(r *cache.Reflector, err error) {
-		w.logger.Warn("k8s API watch error")
-		if w.k8sConnected.CompareAndSwap(true, false) {
-			w.updater.SetK8sConnected(false)
+		ingressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.onAdd,
+			UpdateFunc: w.onUpdate,
+			DeleteFunc: w.onDelete,
+		})
+
+		if w.scope.Services != nil {
+			serviceInformer := factory.Core().V1().Services().Informer()
+			serviceListers[ns] = cache.NewGenericLister(serviceInformer.GetIndexer(), corev1.Resource("services"))
+			serviceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc:    w.onService,
+				UpdateFunc: w.onServiceUpdate,
+				DeleteFunc: w.onServiceDelete,
+			})
 		}
-	}); err != nil {
-		w.logger.Warn("failed to set k8s watch error handler", "error", err)
+	}
+	w.lister = listers
+	if w.scope.Services != nil {
+		w.services = &scopedLister{resource: corev1.Resource("services"), listers: serviceListers}
 	}
 
-	ingressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    w.onAdd,
-		UpdateFunc: w.onUpdate,
-		DeleteFunc: w.onDelete,
-	})
+	if esWatcher == nil {
+		esWatcher = NewEndpointSliceWatcherForNamespaces(clientset, updater, logger,
+			w.scope.informerNamespaces(), w.scope.tweak(false))
+	}
+	w.endpointSliceWatcher = esWatcher
 
 	return w
 }
 
+// namespaceInformers sets up the informers of namespace labels: one per
+// included namespace, selecting it by name, so that access to those
+// namespaces alone suffices, or else one for the whole cluster. Their sync
+// does not gate the watcher's; without access, services go without
+// namespace labels.
+func (w *Watcher) namespaceInformers(clientset kubernetes.Interface) {
+	listers := make(scopedNamespaceLister)
+	for _, ns := range w.scope.informerNamespaces() {
+		var opts []informers.SharedInformerOption
+		if ns != metav1.NamespaceAll {
+			selector := "metadata.name=" + ns
+			opts = append(opts, informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+				o.FieldSelector = selector
+			}))
+		}
+		factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, opts...)
+		w.namespaceFactories = append(w.namespaceFactories, factory)
+		informer := factory.Core().V1().Namespaces()
+		listers[ns] = informer.Lister()
+		informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: w.onNamespace,
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldNS, ok1 := oldObj.(*corev1.Namespace)
+				newNS, ok2 := newObj.(*corev1.Namespace)
+				if ok1 && ok2 && !maps.Equal(oldNS.Labels, newNS.Labels) {
+					w.onNamespace(newNS)
+				}
+			},
+		})
+	}
+	w.namespaces = listers
+}
+
 // Run starts the informer factory and blocks until the context is cancelled.
 func (w *Watcher) Run(ctx context.Context) {
 	w.logger.Info("Starting Kubernetes Ingress watcher")
-	w.factory.Start(ctx.Done())
-	syncStatus := w.factory.WaitForCacheSync(ctx.Done())
-	allSynced := len(syncStatus) > 0
-	for _, synced := range syncStatus {
-		if !synced {
-			allSynced = false
-			break
+	for _, factory := range w.namespaceFactories {
+		factory.Start(ctx.Done())
+	}
+	for _, factory := range w.ingressFactories {
+		factory.Start(ctx.Done())
+	}
+	for _, factory := range w.dynamicFactories {
+		factory.Start(ctx.Done())
+	}
+	go w.waitForNamespaces(ctx)
+
+	allSynced := len(w.ingressFactories) > 0
+	for _, factory := range w.ingressFactories {
+		for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			allSynced = allSynced && synced
+		}
+	}
+	for _, factory := range w.dynamicFactories {
+		for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			allSynced = allSynced && synced
 		}
 	}
 	w.cacheSynced.Store(allSynced)
@@ -133,10 +256,38 @@ func (w *Watcher) Run(ctx context.Context) {
 
 	<-ctx.Done()
 	w.endpointSliceWatcher.StopAll()
-	w.factory.Shutdown()
+	for _, factory := range w.namespaceFactories {
+		factory.Shutdown()
+	}
+	for _, factory := range w.ingressFactories {
+		factory.Shutdown()
+	}
+	for _, factory := range w.dynamicFactories {
+		factory.Shutdown()
+	}
 	w.logger.Info("Kubernetes Ingress watcher stopped")
 }
 
+// namespaceSyncTimeout is how long namespace labels may take to load before
+// their absence is reported.
+const namespaceSyncTimeout = 30 * time.Second
+
+// waitForNamespaces warns once if namespace labels are still unreadable
+// after namespaceSyncTimeout, e.g. without access to the namespaces.
+func (w *Watcher) waitForNamespaces(ctx context.Context) {
+	timeout, cancel := context.WithTimeout(ctx, namespaceSyncTimeout)
+	defer cancel()
+	for _, factory := range w.namespaceFactories {
+		for _, synced := range factory.WaitForCacheSync(timeout.Done()) {
+			if !synced && ctx.Err() == nil {
+				w.logger.Warn("namespace labels unavailable; services are discovered without them",
+					"namespaces", w.scope.informerNamespaces())
+				return
+			}
+		}
+	}
+}
+
 // WaitForSync waits until the watcher has completed its initial cache sync.
 func (w *Watcher) WaitForSync(ctx context.Context) bool {
 	select {
@@ -161,33 +312,8 @@ func (w *Watcher) onAdd(obj interface{}) {
 	if !ok {
 		return
 	}
-
-	url, host, ok := extractServiceURL(ingress)
-	if !ok {
-		w.logger.Warn("skipping Ingress with no valid host",
-			"namespace", ingress.Namespace,
-			"name", ingress.Name)
-		return
-	}
-
-	svc := state.Service{
-		Name:                ingress.Name,
-		DisplayName:         displayName(host),
-		OriginalDisplayName: displayName(host),
-		Namespace:           ingress.Namespace,
-		Group:               ingress.Namespace,
-		URL:                 url,
-		Source:              state.SourceKubernetes,
-		Status:              state.StatusUnknown,
-	}
-	w.updater.AddOrUpdate(svc)
-	w.logger.Info("service discovered",
-		"namespace", ingress.Namespace,
-		"name", ingress.Name,
-		"url", url)
-	if svcName, _, ok := extractBackendServiceName(ingress); ok {
-		w.endpointSliceWatcher.Watch(ingress.Name, ingress.Namespace, svcName)
-	}
+	ann := w.annotations("Ingress", ingress)
+	w.discover("Ingress", ingress, ann, w.ingressTargets(ingress, ann), nil)
 }
 
 func (w *Watcher) onUpdate(oldObj, newObj interface{}) {
@@ -197,58 +323,256 @@ func (w *Watcher) onUpdate(oldObj, newObj interface{}) {
 	if !ok {
 		return
 	}
+	previous := []string{ingress.Name}
+	if old, ok := oldObj.(*networkingv1.Ingress); ok {
+		previous = w.ingressServiceNames(old)
+	}
+	ann := w.annotations("Ingress", ingress)
+	w.discover("Ingress", ingress, ann, w.ingressTargets(ingress, ann), previous)
+}
+
+// routeTarget is what discovery reads from a routing object: the URL it
+// serves and the backend Service, in the object's namespace, whose
+// endpoints back it.
+type routeTarget struct {
+	name     string // service name; empty for the object's own name
+	url      string
+	host     string
+	display  string // display name; empty for the first label of the host
+	path     string // set when the URL is one path of the host
+	expanded bool   // one of an object's targets per host and path
+	backend  string // empty without a Service backend
+}
 
+// ingressTarget reads the target of an Ingress. It reports false if the
+// Ingress has no host.
+func ingressTarget(ingress *networkingv1.Ingress) (routeTarget, bool) {
 	url, host, ok := extractServiceURL(ingress)
 	if !ok {
-		// Ingress is no longer valid for discovery; stop any EndpointSlice watch.
-		w.endpointSliceWatcher.Unwatch(ingress.Name, ingress.Namespace)
-		w.updater.Remove(ingress.Namespace, ingress.Name)
-		w.logger.Warn("skipping Ingress with no valid host after update",
-			"namespace", ingress.Namespace,
-			"name", ingress.Name)
+		return routeTarget{}, false
+	}
+	backend, _, _ := extractBackendServiceName(ingress)
+	return routeTarget{url: url, host: host, backend: backend}, true
+}
+
+// ingressTargets returns the targets of an Ingress: one per host and path
+// when expanded, by ExpandRulesAnnotation or else Scope.ExpandRules, else
+// the single target of its first rule. It returns none if the Ingress is
+// ignored or has no host.
+func (w *Watcher) ingressTargets(ingress *networkingv1.Ingress, ann serviceAnnotations) []routeTarget {
+	if ann.Ignore {
+		return nil
+	}
+	expand := w.scope.ExpandRules
+	if ann.ExpandRules != nil {
+		expand = *ann.ExpandRules
+	}
+	if expand {
+		if targets := expandRules(ingress); len(targets) > 0 {
+			return targets
+		}
+	}
+	if target, ok := ingressTarget(ingress); ok {
+		return []routeTarget{target}
+	}
+	return nil
+}
+
+// ingressServiceNames returns the names of the services an Ingress gives.
+func (w *Watcher) ingressServiceNames(ingress *networkingv1.Ingress) []string {
+	ann, _ := parseAnnotations(ingress.Annotations)
+	return serviceNames(ingress.Name, w.ingressTargets(ingress, ann))
+}
+
+// serviceNames returns the names of the services of an object's targets.
+func serviceNames(objName string, targets []routeTarget) []string {
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = cmp.Or(t.name, objName)
+	}
+	return names
+}
+
+// expandRules returns a target for each host and path of an Ingress's
+// rules, named "<ingress>@<host>" with the path appended, its slashes
+// replaced by "~": "web@app.example.com~api" for app.example.com/api.
+// Rules without a host are skipped, and a repeated host and path keeps its
+// first backend.
+func expandRules(ingress *networkingv1.Ingress) []routeTarget {
+	var (
+		targets []routeTarget
+		seen    = make(map[string]bool)
+	)
+	add := func(host, path, backend string) {
+		if path == "/" {
+			path = ""
+		}
+		name := ingress.Name + "@" + host + strings.ReplaceAll(path, "/", "~")
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		targets = append(targets, routeTarget{
+			name:     name,
+			url:      ingressScheme(ingress, host) + "://" + host + path,
+			host:     host,
+			path:     path,
+			backend:  backend,
+			expanded: true,
+		})
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" {
+			continue
+		}
+		if rule.HTTP == nil || len(rule.HTTP.Paths) == 0 {
+			add(rule.Host, "", "")
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			var backend string
+			if p.Backend.Service != nil {
+				backend = p.Backend.Service.Name
+			}
+			add(rule.Host, p.Path, backend)
+		}
+	}
+	return targets
+}
+
+// discover adds the services of a routing object of the given kind, one
+// per target, or, on update, refreshes them. previous names the services
+// of the object's earlier version, nil when it is added; those it no longer
+// gives, e.g. after it lost its host or became ignored, are removed.
+func (w *Watcher) discover(kind string, obj metav1.Object, ann serviceAnnotations, targets []routeTarget, previous []string) {
+	namespace := obj.GetNamespace()
+	update := previous != nil
+	current := serviceNames(obj.GetName(), targets)
+	for _, name := range previous {
+		if !slices.Contains(current, name) && w.release(kind, obj, name) {
+			// No longer valid for discovery; stop any EndpointSlice watch.
+			w.endpointSliceWatcher.Unwatch(name, namespace)
+			w.updater.Remove(namespace, name)
+		}
+	}
+
+	if len(targets) == 0 {
+		suffix := ""
+		if update {
+			suffix = " after update"
+		}
+		if ann.Ignore {
+			w.logger.Info("skipping ignored "+kind+suffix, "namespace", namespace, "name", obj.GetName())
+		} else {
+			w.logger.Warn("skipping "+kind+" with no valid host"+suffix, "namespace", namespace, "name", obj.GetName())
+		}
 		return
 	}
 
-	hostDisplayName := displayName(host)
-	svc := state.Service{
-		Name:                ingress.Name,
-		DisplayName:         hostDisplayName,
-		OriginalDisplayName: hostDisplayName,
-		Namespace:           ingress.Namespace,
-		Group:               ingress.Namespace,
-		URL:                 url,
-		Source:              state.SourceKubernetes,
-		Status:              state.StatusUnknown,
+	serviceLabels := w.serviceLabels(namespace, obj.GetLabels(), ann)
+	for i, target := range targets {
+		w.claim(kind, obj, current[i])
+		w.discoverTarget(kind, namespace, current[i], target, ann, serviceLabels, update)
 	}
+}
 
-	if existing, ok := w.updater.Get(ingress.Namespace, ingress.Name); ok {
+// discoverTarget adds or refreshes the service of one target of a routing
+// object.
+func (w *Watcher) discoverTarget(kind, namespace, name string, target routeTarget, ann serviceAnnotations, serviceLabels map[string]string, update bool) {
+	discovered := discoveredService(namespace, name, target, ann, serviceLabels)
+	svc := discovered
+	if existing, ok := w.updater.Get(namespace, name); update && ok {
 		svc = existing
-		svc.Name = ingress.Name
-		svc.Namespace = ingress.Namespace
-		svc.URL = url
+		svc.Name = name
+		svc.Namespace = namespace
+		svc.URL = target.url
 		svc.Source = state.SourceKubernetes
+		svc.Description = discovered.Description
+		svc.Group = discovered.Group
 
-		// Preserve user override names unless they are still following discovery defaults.
+		// Preserve user override values unless they are still following discovery defaults.
 		if svc.DisplayName == svc.OriginalDisplayName {
-			svc.DisplayName = hostDisplayName
+			svc.DisplayName = discovered.DisplayName
+		}
+		svc.OriginalDisplayName = discovered.DisplayName
+		if svc.Icon == svc.OriginalIcon {
+			svc.Icon = discovered.Icon
 		}
-		svc.OriginalDisplayName = hostDisplayName
-		if svc.Group == "" {
-			svc.Group = ingress.Namespace
+		svc.OriginalIcon = discovered.Icon
+		if svc.HealthURL == svc.OriginalHealthURL {
+			svc.HealthURL = discovered.HealthURL
 		}
+		svc.OriginalHealthURL = discovered.HealthURL
+		if slices.Equal(svc.ExpectedStatusCodes, svc.OriginalExpectedStatusCodes) {
+			svc.ExpectedStatusCodes = discovered.ExpectedStatusCodes
+		}
+		svc.OriginalExpectedStatusCodes = discovered.OriginalExpectedStatusCodes
+		if reflect.DeepEqual(svc.Probe, svc.OriginalProbe) {
+			svc.Probe = discovered.Probe
+		}
+		svc.OriginalProbe = discovered.OriginalProbe
+		// Likewise keep an override's dependsOn over the annotation.
+		if slices.Equal(svc.DependsOn, svc.OriginalDependsOn) {
+			svc.DependsOn = discovered.DependsOn
+		}
+		svc.OriginalDependsOn = discovered.OriginalDependsOn
+		// Labels an override added on top of discovery are kept.
+		svc.Labels = rebaseLabels(svc.Labels, svc.OriginalLabels, discovered.OriginalLabels)
+		svc.OriginalLabels = discovered.OriginalLabels
 	}
 
 	w.updater.AddOrUpdate(svc)
-	w.logger.Info("service updated",
-		"namespace", ingress.Namespace,
-		"name", ingress.Name,
-		"url", url)
+	if update {
+		w.logger.Info("service updated", "kind", kind, "namespace", namespace, "name", name, "url", target.url)
+		// Always clear prior watch mapping first to avoid stale backend watches.
+		w.endpointSliceWatcher.Unwatch(name, namespace)
+	} else {
+		w.logger.Info("service discovered", "kind", kind, "namespace", namespace, "name", name, "url", target.url)
+	}
+	if target.backend != "" {
+		w.endpointSliceWatcher.Watch(name, namespace, target.backend)
+	}
+}
+
+// forget removes the services of a deleted routing object, except those
+// another object still gives.
+func (w *Watcher) forget(kind string, obj metav1.Object, services []string) {
+	for _, name := range services {
+		if !w.release(kind, obj, name) {
+			w.logger.Info("service kept for its other owners", "kind", kind, "namespace", obj.GetNamespace(), "name", name)
+			continue
+		}
+		w.endpointSliceWatcher.Unwatch(name, obj.GetNamespace())
+		w.updater.Remove(obj.GetNamespace(), name)
+		w.logger.Info("service removed", "kind", kind, "namespace", obj.GetNamespace(), "name", name)
+	}
+	w.updater.SetObjectErrors(kind, obj.GetNamespace(), obj.GetName(), nil)
+}
+
+// claim records obj, of the given kind, as an owner of the service name in
+// its namespace.
+func (w *Watcher) claim(kind string, obj metav1.Object, name string) {
+	key := obj.GetNamespace() + "/" + name
+	w.ownersMu.Lock()
+	defer w.ownersMu.Unlock()
+	if w.owners[key] == nil {
+		w.owners[key] = make(map[string]bool)
+	}
+	w.owners[key][kind+"/"+obj.GetName()] = true
+}
 
-	// Always clear prior watch mapping first to avoid stale backend watches.
-	w.endpointSliceWatcher.Unwatch(ingress.Name, ingress.Namespace)
-	if svcName, _, ok := extractBackendServiceName(ingress); ok {
-		w.endpointSliceWatcher.Watch(ingress.Name, ingress.Namespace, svcName)
+// release drops obj as an owner of the service name in its namespace and
+// reports whether the service has no owner left and must be removed.
+func (w *Watcher) release(kind string, obj metav1.Object, name string) bool {
+	key := obj.GetNamespace() + "/" + name
+	w.ownersMu.Lock()
+	defer w.ownersMu.Unlock()
+	delete(w.owners[key], kind+"/"+obj.GetName())
+	if len(w.owners[key]) > 0 {
+		return false
 	}
+	delete(w.owners, key)
+	return true
 }
 
 func (w *Watcher) onDelete(obj interface{}) {
@@ -267,11 +591,152 @@ func (w *Watcher) onDelete(obj interface{}) {
 		}
 	}
 
-	w.endpointSliceWatcher.Unwatch(ingress.Name, ingress.Namespace)
-	w.updater.Remove(ingress.Namespace, ingress.Name)
-	w.logger.Info("service removed",
-		"namespace", ingress.Namespace,
-		"name", ingress.Name)
+	w.forget("Ingress", ingress, w.ingressServiceNames(ingress))
+}
+
+// onNamespace relabels the services of a namespace's Ingresses, routes and
+// Services after its labels change, or once it is first seen.
+func (w *Watcher) onNamespace(obj interface{}) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	ingresses, err := w.lister.Ingresses(ns.Name).List(labels.Everything())
+	if err != nil {
+		w.logger.Warn("failed to list Ingresses for namespace", "namespace", ns.Name, "error", err)
+		return
+	}
+	// The services of each object; an Ingress may give several.
+	objs := make(map[metav1.Object][]string, len(ingresses))
+	for _, ingress := range ingresses {
+		objs[ingress] = w.ingressServiceNames(ingress)
+	}
+	for _, ri := range w.routes {
+		routes, err := ri.lister.ByNamespace(ns.Name).List(labels.Everything())
+		if err != nil {
+			w.logger.Warn("failed to list "+ri.kind+"s for namespace", "namespace", ns.Name, "error", err)
+			continue
+		}
+		for _, route := range routes {
+			if obj, ok := route.(metav1.Object); ok {
+				objs[obj] = []string{obj.GetName()}
+			}
+		}
+	}
+	if w.services != nil {
+		services, err := w.services.ByNamespace(ns.Name).List(labels.Everything())
+		if err != nil {
+			w.logger.Warn("failed to list Services for namespace", "namespace", ns.Name, "error", err)
+		}
+		for _, obj := range services {
+			if svc, ok := obj.(*corev1.Service); ok {
+				if names := w.exposedServiceNames(svc); len(names) > 0 {
+					objs[svc] = names
+				}
+			}
+		}
+	}
+	for obj, names := range objs {
+		ann, _ := parseAnnotations(obj.GetAnnotations())
+		serviceLabels := w.serviceLabels(obj.GetNamespace(), obj.GetLabels(), ann)
+		for _, name := range names {
+			w.updater.Update(obj.GetNamespace(), name, func(svc *state.Service) {
+				svc.Labels = rebaseLabels(svc.Labels, svc.OriginalLabels, serviceLabels)
+				svc.OriginalLabels = maps.Clone(serviceLabels)
+			})
+		}
+	}
+}
+
+// annotations parses the annotations of a routing object of the given kind
+// and reports their errors, clearing those of an earlier version once fixed.
+func (w *Watcher) annotations(kind string, obj metav1.Object) serviceAnnotations {
+	ann, errs := parseAnnotations(obj.GetAnnotations())
+	for _, err := range errs {
+		w.logger.Warn("invalid "+kind+" annotation",
+			"namespace", obj.GetNamespace(),
+			"name", obj.GetName(),
+			"error", err)
+	}
+	w.updater.SetObjectErrors(kind, obj.GetNamespace(), obj.GetName(), errs)
+	return ann
+}
+
+// serviceLabels returns the labels of a routing object's service: its
+// namespace's labels, overlaid with the object's labels and then the
+// LabelsAnnotation.
+func (w *Watcher) serviceLabels(namespace string, objLabels map[string]string, ann serviceAnnotations) map[string]string {
+	out := make(map[string]string)
+	if w.namespaces != nil {
+		if ns, err := w.namespaces.Get(namespace); err == nil {
+			maps.Copy(out, ns.Labels)
+		}
+	}
+	maps.Copy(out, objLabels)
+	maps.Copy(out, ann.Labels)
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// discoveredService builds the service discovered for a routing object.
+// Annotations take the place of the values derived from the object, and are
+// kept as the originals a config override falls back to.
+func discoveredService(namespace, name string, target routeTarget, ann serviceAnnotations, serviceLabels map[string]string) state.Service {
+	display := cmp.Or(ann.DisplayName, target.display, displayName(target.host))
+	if target.expanded {
+		// Name the host and path, which the object's other services differ in.
+		display = strings.TrimSpace(ann.DisplayName + " " + target.host + target.path)
+	}
+	var healthURL string
+	if ann.HealthPath != "" {
+		// The health path is on the host, not below a path of it.
+		healthURL = strings.TrimSuffix(target.url, target.path) + ann.HealthPath
+	}
+	var probe *state.ProbeSpec
+	if ann.Probe != "" {
+		probe = &state.ProbeSpec{Type: ann.Probe}
+	}
+	return state.Service{
+		Name:                        name,
+		DisplayName:                 display,
+		OriginalDisplayName:         display,
+		Namespace:                   namespace,
+		Group:                       cmp.Or(ann.Group, namespace),
+		Labels:                      serviceLabels,
+		OriginalLabels:              maps.Clone(serviceLabels),
+		URL:                         target.url,
+		Icon:                        ann.Icon,
+		OriginalIcon:                ann.Icon,
+		Description:                 ann.Description,
+		Source:                      state.SourceKubernetes,
+		Status:                      state.StatusUnknown,
+		HealthURL:                   healthURL,
+		OriginalHealthURL:           healthURL,
+		ExpectedStatusCodes:         ann.ExpectedStatusCodes,
+		OriginalExpectedStatusCodes: slices.Clone(ann.ExpectedStatusCodes),
+		Probe:                       probe,
+		OriginalProbe:               probe.Clone(),
+		DependsOn:                   ann.DependsOn,
+		OriginalDependsOn:           slices.Clone(ann.DependsOn),
+	}
+}
+
+// rebaseLabels replaces the discovered labels underlying current, which
+// were original, with discovered, keeping the labels an override set.
+func rebaseLabels(current, original, discovered map[string]string) map[string]string {
+	out := maps.Clone(discovered)
+	for k, v := range current {
+		if ov, ok := original[k]; ok && ov == v {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[k] = v
+	}
+	return out
 }
 
 // displayName extracts a human-friendly display name from a hostname
@@ -303,20 +768,18 @@ func extractServiceURL(ingress *networkingv1.Ingress) (string, string, bool) {
 		return "", "", false
 	}
 
-	scheme := "http"
+	return ingressScheme(ingress, host) + "://" + host, host, true
+}
+
+// ingressScheme returns "https" if the Ingress's TLS config covers host,
+// else "http".
+func ingressScheme(ingress *networkingv1.Ingress, host string) string {
 	for _, tls := range ingress.Spec.TLS {
-		for _, tlsHost := range tls.Hosts {
-			if tlsHost == host {
-				scheme = "https"
-				break
-			}
-		}
-		if scheme == "https" {
-			break
+		if slices.Contains(tls.Hosts, host) {
+			return "https"
 		}
 	}
-
-	return scheme + "://" + host, host, true
+	return "http"
 }
 
 // extractBackendServiceName returns (serviceName, namespace, ok) from an Ingress.
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -25,7 +27,8 @@ type fakeStateUpdater struct {
 	removed      []string // "namespace/name"
 	current      map[string]state.Service
 	k8sConnected bool
-	k8sCalls     []bool // history of SetK8sConnected calls
+	k8sCalls     []bool              // history of SetK8sConnected calls
+	objectErrors map[string][]string // "kind namespace/name" -> errors
 }
 
 func (f *fakeStateUpdater) Get(namespace, name string) (state.Service, bool) {
@@ -99,6 +102,26 @@ func (f *fakeStateUpdater) Update(namespace, name string, fn func(*state.Service
 	f.added = append(f.added, svc)
 }
 
+func (f *fakeStateUpdater) SetObjectErrors(kind, namespace, name string, errs []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.objectErrors == nil {
+		f.objectErrors = make(map[string][]string)
+	}
+	ref := kind + " " + namespace + "/" + name
+	if len(errs) == 0 {
+		delete(f.objectErrors, ref)
+		return
+	}
+	f.objectErrors[ref] = errs
+}
+
+func (f *fakeStateUpdater) getObjectErrors(ref string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objectErrors[ref]
+}
+
 func (f *fakeStateUpdater) SetConfigErrors(errs []string) {}
 func (f *fakeStateUpdater) ConfigErrors() []string        { return nil }
 
@@ -804,7 +827,7 @@ func TestWatcherGroupMatchesNamespace(t *testing.T) {
 
 func TestNewWatcherErrorSanitization(t *testing.T) {
 	// Call NewWatcher with a non-existent kubeconfig path
-	_, err := NewWatcher("/home/user/.kube/nonexistent-config", &fakeStateUpdater{}, slog.Default())
+	_, err := NewWatcher("/home/user/.kube/nonexistent-config", "", &fakeStateUpdater{}, slog.Default())
 	if err == nil {
 		t.Fatal("expected error for non-existent kubeconfig")
 	}
@@ -957,8 +980,9 @@ diagFound:
 	if got.OriginalDisplayName != "new" {
 		t.Fatalf("OriginalDisplayName = %q, want %q", got.OriginalDisplayName, "new")
 	}
-	if got.Group != "custom-group" {
-		t.Fatalf("Group = %q, want preserved custom-group", got.Group)
+	// The group follows discovery: the namespace without a group annotation.
+	if got.Group != "my-ns" {
+		t.Fatalf("Group = %q, want %q", got.Group, "my-ns")
 	}
 	if got.URL != "https://new.example.com" {
 		t.Fatalf("URL = %q, want %q", got.URL, "https://new.example.com")
@@ -1184,3 +1208,318 @@ func TestWatcherNoEndpointSliceWatchForIngressWithoutBackend(t *testing.T) {
 		}
 	}
 }
+
+func TestWatcherDependsOnAnnotation(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+
+	ingress := newTestIngress("jellyfin", "media", "jellyfin.example.com", true)
+	ingress.Annotations = map[string]string{DependsOnAnnotation: "custom/truenas, kube-system/traefik,,"}
+	w.onAdd(ingress)
+
+	got, ok := updater.Get("media", "jellyfin")
+	if !ok {
+		t.Fatal("expected service to be discovered")
+	}
+	want := []string{"custom/truenas", "kube-system/traefik"}
+	if strings.Join(got.DependsOn, ",") != strings.Join(want, ",") || strings.Join(got.OriginalDependsOn, ",") != strings.Join(want, ",") {
+		t.Fatalf("DependsOn = %v (original %v), want %v", got.DependsOn, got.OriginalDependsOn, want)
+	}
+
+	// An override's dependsOn survives an annotation change; the original follows it.
+	updater.Update("media", "jellyfin", func(svc *state.Service) {
+		svc.DependsOn = []string{"custom/nas2"}
+	})
+	ingress.Annotations[DependsOnAnnotation] = "custom/truenas"
+	w.onUpdate(nil, ingress)
+
+	got, _ = updater.Get("media", "jellyfin")
+	if strings.Join(got.DependsOn, ",") != "custom/nas2" {
+		t.Errorf("DependsOn = %v, want override preserved", got.DependsOn)
+	}
+	if strings.Join(got.OriginalDependsOn, ",") != "custom/truenas" {
+		t.Errorf("OriginalDependsOn = %v, want annotation value", got.OriginalDependsOn)
+	}
+}
+
+func TestWatcherLabels(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+	nsIndexer := w.namespaceFactories[0].Core().V1().Namespaces().Informer().GetIndexer()
+	ingressIndexer := w.ingressFactories[0].Networking().V1().Ingresses().Informer().GetIndexer()
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "media",
+		Labels: map[string]string{"owner": "media-team", "tier": "standard"},
+	}}
+	if err := nsIndexer.Add(ns); err != nil {
+		t.Fatal(err)
+	}
+	ingress := newTestIngress("jellyfin", "media", "jellyfin.example.com", true)
+	ingress.Labels = map[string]string{"tier": "critical", "exposure": "internal"}
+	ingress.Annotations = map[string]string{LabelsAnnotation: "exposure=public, owner=alice"}
+	if err := ingressIndexer.Add(ingress); err != nil {
+		t.Fatal(err)
+	}
+	w.onAdd(ingress)
+
+	got, _ := updater.Get("media", "jellyfin")
+	want := map[string]string{"owner": "alice", "tier": "critical", "exposure": "public"}
+	if !maps.Equal(got.Labels, want) || !maps.Equal(got.OriginalLabels, want) {
+		t.Fatalf("Labels = %v (original %v), want %v", got.Labels, got.OriginalLabels, want)
+	}
+
+	// A label added by an override survives rediscovery; discovered labels follow the Ingress.
+	updater.Update("media", "jellyfin", func(svc *state.Service) {
+		svc.Labels["oncall"] = "bob"
+	})
+	ingress.Annotations[LabelsAnnotation] = "not a label"
+	w.onUpdate(nil, ingress)
+	got, _ = updater.Get("media", "jellyfin")
+	want = map[string]string{"owner": "media-team", "tier": "critical", "exposure": "internal", "oncall": "bob"}
+	if !maps.Equal(got.Labels, want) {
+		t.Errorf("after update Labels = %v, want %v", got.Labels, want)
+	}
+
+	// Namespace label changes reach the services of its Ingresses.
+	ns = ns.DeepCopy()
+	ns.Labels = map[string]string{"owner": "platform", "env": "prod"}
+	if err := nsIndexer.Update(ns); err != nil {
+		t.Fatal(err)
+	}
+	w.onNamespace(ns)
+	got, _ = updater.Get("media", "jellyfin")
+	want = map[string]string{"owner": "platform", "env": "prod", "tier": "critical", "exposure": "internal", "oncall": "bob"}
+	if !maps.Equal(got.Labels, want) {
+		t.Errorf("after namespace change Labels = %v, want %v", got.Labels, want)
+	}
+	delete(want, "oncall")
+	if !maps.Equal(got.OriginalLabels, want) {
+		t.Errorf("after namespace change OriginalLabels = %v, want %v", got.OriginalLabels, want)
+	}
+}
+
+func TestWatcherServiceAnnotations(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+
+	ingress := newTestIngress("jellyfin", "media", "jellyfin.example.com", true)
+	ingress.Annotations = map[string]string{
+		DisplayNameAnnotation:    "Jellyfin",
+		GroupAnnotation:          "streaming",
+		IconAnnotation:           "jellyfin",
+		DescriptionAnnotation:    "Film collection",
+		HealthPathAnnotation:     "/health",
+		ExpectedStatusAnnotation: "200,302",
+	}
+	w.onAdd(ingress)
+
+	got, ok := updater.Get("media", "jellyfin")
+	if !ok {
+		t.Fatal("expected service to be discovered")
+	}
+	if got.DisplayName != "Jellyfin" || got.OriginalDisplayName != "Jellyfin" || got.Group != "streaming" ||
+		got.Icon != "jellyfin" || got.OriginalIcon != "jellyfin" || got.Description != "Film collection" {
+		t.Errorf("service = %+v, want annotated display name, group, icon, and description", got)
+	}
+	if got.HealthURL != "https://jellyfin.example.com/health" || got.OriginalHealthURL != got.HealthURL {
+		t.Errorf("HealthURL = %q (original %q), want the health path on the host", got.HealthURL, got.OriginalHealthURL)
+	}
+	if !slices.Equal(got.ExpectedStatusCodes, []int{200, 302}) || !slices.Equal(got.OriginalExpectedStatusCodes, []int{200, 302}) {
+		t.Errorf("ExpectedStatusCodes = %v (original %v), want [200 302]", got.ExpectedStatusCodes, got.OriginalExpectedStatusCodes)
+	}
+
+	// An override's values survive annotation changes; the originals follow them.
+	updater.Update("media", "jellyfin", func(svc *state.Service) {
+		svc.Icon = "custom-icon"
+	})
+	ingress.Annotations[IconAnnotation] = "film"
+	ingress.Annotations[DisplayNameAnnotation] = "Films"
+	delete(ingress.Annotations, GroupAnnotation)
+	delete(ingress.Annotations, HealthPathAnnotation)
+	w.onUpdate(nil, ingress)
+
+	got, _ = updater.Get("media", "jellyfin")
+	if got.Icon != "custom-icon" || got.OriginalIcon != "film" {
+		t.Errorf("Icon = %q (original %q), want override kept over the annotation", got.Icon, got.OriginalIcon)
+	}
+	if got.DisplayName != "Films" || got.HealthURL != "" || got.OriginalHealthURL != "" {
+		t.Errorf("service = %+v, want the changed annotations followed", got)
+	}
+	if got.Group != "media" {
+		t.Errorf("Group = %q, want the namespace once the group annotation is removed", got.Group)
+	}
+}
+
+func TestWatcherReportsAnnotationErrors(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+
+	ingress := newTestIngress("jellyfin", "media", "jellyfin.example.com", true)
+	ingress.Annotations = map[string]string{ExpectedStatusAnnotation: "ok", GroupAnnotation: "streaming"}
+	w.onAdd(ingress)
+
+	want := []string{`command-center.io/expected-status: invalid status code "ok"`}
+	if got := updater.getObjectErrors("Ingress media/jellyfin"); !slices.Equal(got, want) {
+		t.Errorf("errors = %q, want %q", got, want)
+	}
+	// The other annotations still apply.
+	if got, _ := updater.Get("media", "jellyfin"); got.Group != "streaming" || got.ExpectedStatusCodes != nil {
+		t.Errorf("service = %+v, want valid annotations applied and the invalid one unset", got)
+	}
+
+	ingress.Annotations[ExpectedStatusAnnotation] = "200"
+	w.onUpdate(nil, ingress)
+	if got := updater.getObjectErrors("Ingress media/jellyfin"); got != nil {
+		t.Errorf("errors = %q, want cleared once fixed", got)
+	}
+
+	ingress.Annotations[ExpectedStatusAnnotation] = "ok"
+	w.onUpdate(nil, ingress)
+	w.onDelete(ingress)
+	if got := updater.getObjectErrors("Ingress media/jellyfin"); got != nil {
+		t.Errorf("errors = %q, want cleared on delete", got)
+	}
+}
+
+func TestWatcherIgnoreAnnotation(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+
+	ingress := newTestIngress("internal", "media", "internal.example.com", true)
+	ingress.Annotations = map[string]string{IgnoreAnnotation: "true"}
+	w.onAdd(ingress)
+	if _, ok := updater.Get("media", "internal"); ok {
+		t.Fatal("ignored Ingress should not be discovered")
+	}
+
+	ingress.Annotations[IgnoreAnnotation] = "false"
+	w.onUpdate(nil, ingress)
+	if _, ok := updater.Get("media", "internal"); !ok {
+		t.Fatal("expected service once no longer ignored")
+	}
+
+	ingress.Annotations[IgnoreAnnotation] = "true"
+	w.onUpdate(nil, ingress)
+	if _, ok := updater.Get("media", "internal"); ok {
+		t.Error("service should be removed once ignored")
+	}
+}
+
+// newMultiRuleIngress routes app.example.com/api and app.example.com/ to
+// two backends, and admin.example.com, with TLS, to a third.
+func newMultiRuleIngress() *networkingv1.Ingress {
+	path := func(p, backend string) networkingv1.HTTPIngressPath {
+		return networkingv1.HTTPIngressPath{
+			Path: p,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: backend, Port: networkingv1.ServiceBackendPort{Number: 80}},
+			},
+		}
+	}
+	rule := func(host string, paths ...networkingv1.HTTPIngressPath) networkingv1.IngressRule {
+		return networkingv1.IngressRule{
+			Host:             host,
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths}},
+		}
+	}
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "apps"},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				rule("app.example.com", path("/api", "api"), path("/", "frontend")),
+				rule("admin.example.com", path("/", "admin")),
+				rule("", path("/", "default")),
+			},
+			TLS: []networkingv1.IngressTLS{{Hosts: []string{"admin.example.com"}}},
+		},
+	}
+}
+
+func TestExpandRules(t *testing.T) {
+	want := []routeTarget{
+		{name: "web@app.example.com~api", url: "http://app.example.com/api", host: "app.example.com", path: "/api", backend: "api", expanded: true},
+		{name: "web@app.example.com", url: "http://app.example.com", host: "app.example.com", backend: "frontend", expanded: true},
+		{name: "web@admin.example.com", url: "https://admin.example.com", host: "admin.example.com", backend: "admin", expanded: true},
+	}
+	if got := expandRules(newMultiRuleIngress()); !slices.Equal(got, want) {
+		t.Errorf("expandRules() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWatcherExpandRulesAnnotation(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default())
+
+	collapsed := newMultiRuleIngress()
+	w.onAdd(collapsed)
+	if svc, ok := updater.Get("apps", "web"); !ok || svc.URL != "http://app.example.com" {
+		t.Fatalf("expected one collapsed service, got %+v (found %v)", svc, ok)
+	}
+
+	expanded := newMultiRuleIngress()
+	expanded.Annotations = map[string]string{ExpandRulesAnnotation: "true", HealthPathAnnotation: "/healthz"}
+	w.onUpdate(collapsed, expanded)
+	if _, ok := updater.Get("apps", "web"); ok {
+		t.Error("collapsed service should be replaced once expanded")
+	}
+	api, ok := updater.Get("apps", "web@app.example.com~api")
+	if !ok {
+		t.Fatal("expected a service for app.example.com/api")
+	}
+	if api.URL != "http://app.example.com/api" || api.DisplayName != "app.example.com/api" || api.HealthURL != "http://app.example.com/healthz" {
+		t.Errorf("unexpected path service %+v", api)
+	}
+	if admin, ok := updater.Get("apps", "web@admin.example.com"); !ok || admin.URL != "https://admin.example.com" {
+		t.Errorf("unexpected host service %+v (found %v)", admin, ok)
+	}
+	w.endpointSliceWatcher.mu.Lock()
+	_, watched := w.endpointSliceWatcher.serviceToIngress["apps/api"]["web@app.example.com~api"]
+	w.endpointSliceWatcher.mu.Unlock()
+	if !watched {
+		t.Error("expected the path's backend to be watched for its own service")
+	}
+
+	w.onDelete(expanded)
+	for _, name := range []string{"web@app.example.com~api", "web@app.example.com", "web@admin.example.com"} {
+		if _, ok := updater.Get("apps", name); ok {
+			t.Errorf("service %s should be removed with its Ingress", name)
+		}
+	}
+}
+
+func TestWatcherExpandRulesScope(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	updater := &fakeStateUpdater{current: make(map[string]state.Service)}
+	w := NewWatcherWithClient(clientset, updater, slog.Default(), WithScope(Scope{ExpandRules: true}))
+
+	ingress := newMultiRuleIngress()
+	ingress.Annotations = map[string]string{DisplayNameAnnotation: "Web"}
+	w.onAdd(ingress)
+	for name, display := range map[string]string{
+		"web@app.example.com~api": "Web app.example.com/api",
+		"web@app.example.com":     "Web app.example.com",
+		"web@admin.example.com":   "Web admin.example.com",
+	} {
+		if svc, ok := updater.Get("apps", name); !ok || svc.DisplayName != display {
+			t.Errorf("service %s = %+v (found %v), want display name %q", name, svc, ok, display)
+		}
+	}
+
+	// The annotation opts an Ingress out.
+	collapsed := newMultiRuleIngress()
+	collapsed.Annotations = map[string]string{ExpandRulesAnnotation: "false"}
+	w.onUpdate(ingress, collapsed)
+	if svc, ok := updater.Get("apps", "web"); !ok || svc.URL != "http://app.example.com" {
+		t.Errorf("expected one collapsed service, got %+v (found %v)", svc, ok)
+	}
+	if _, ok := updater.Get("apps", "web@app.example.com~api"); ok {
+		t.Error("expanded services should be removed once opted out")
+	}
+}
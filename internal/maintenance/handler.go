@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// maxRequestBody caps the size of a create request.
+const maxRequestBody = 64 << 10
+
+type windowResponse struct {
+	Window Window `json:"window"`
+}
+
+type windowsResponse struct {
+	Windows []Status `json:"windows"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns an http.Handler for the maintenance endpoints:
+//
+//	GET    /api/maintenance       list windows
+//	POST   /api/maintenance       create a window
+//	DELETE /api/maintenance/{id}  cancel a window created through the API
+//
+// If logger is nil, a no-op logger is used.
+func NewHandler(m *Manager, logger *slog.Logger) http.Handler {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, windowsResponse{Windows: m.List()})
+		case http.MethodPost:
+			var win Window
+			dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&win); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body: " + err.Error()})
+				return
+			}
+			created, err := m.Create(win)
+			switch {
+			case errors.Is(err, ErrInvalid):
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			case errors.Is(err, ErrDuplicateID):
+				writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+			case err != nil:
+				logger.Error("failed to create maintenance window", "error", err)
+				writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to save maintenance window"})
+			default:
+				logger.Info("maintenance window created", "id", created.ID)
+				writeJSON(w, http.StatusCreated, windowResponse{Window: created})
+			}
+		case http.MethodDelete:
+			id := r.PathValue("id")
+			err := m.Cancel(id)
+			switch {
+			case errors.Is(err, ErrNotFound):
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			case errors.Is(err, ErrConfigWindow):
+				writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error()})
+			case err != nil:
+				logger.Error("failed to cancel maintenance window", "id", id, "error", err)
+				writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to save maintenance windows"})
+			default:
+				logger.Info("maintenance window cancelled", "id", id)
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,95 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+func newTestMux(t *testing.T) (*http.ServeMux, *Manager) {
+	t.Helper()
+	m, err := NewManager(state.NewStore(), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetConfigWindows([]Window{{ID: "nightly", Groups: []string{"storage"}, Schedule: "0 22 * * *", Duration: "1h"}})
+	h := NewHandler(m, nil)
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/maintenance", h)
+	mux.Handle("POST /api/maintenance", h)
+	mux.Handle("DELETE /api/maintenance/{id}", h)
+	return mux, m
+}
+
+func TestHandler_CreateListCancel(t *testing.T) {
+	mux, _ := newTestMux(t)
+
+	body := `{"id":"nas","reason":"disk swap","services":["custom/truenas"],"start":"2099-01-01T22:00:00Z","end":"2099-01-02T02:00:00Z"}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/maintenance", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	var created windowResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Window.ID != "nas" || created.Window.Source != SourceAPI {
+		t.Errorf("unexpected created window: %+v", created.Window)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/maintenance", nil))
+	var list windowsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Windows) != 2 || list.Windows[0].ID != "nightly" || list.Windows[1].ID != "nas" || list.Windows[1].Active {
+		t.Errorf("unexpected list: %+v", list.Windows)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/maintenance/nas", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("cancel: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{"malformed body", http.MethodPost, "/api/maintenance", `{`, http.StatusBadRequest, "invalid request body"},
+		{"unknown field", http.MethodPost, "/api/maintenance", `{"service":"x"}`, http.StatusBadRequest, "invalid request body"},
+		{"invalid window", http.MethodPost, "/api/maintenance", `{"groups":["storage"]}`, http.StatusBadRequest, "invalid maintenance window: schedule"},
+		{"duplicate id", http.MethodPost, "/api/maintenance", `{"id":"nightly","groups":["storage"],"schedule":"0 1 * * *","duration":"1h"}`, http.StatusConflict, "already exists"},
+		{"cancel config window", http.MethodDelete, "/api/maintenance/nightly", "", http.StatusConflict, "defined in config"},
+		{"cancel unknown", http.MethodDelete, "/api/maintenance/missing", "", http.StatusNotFound, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, _ := newTestMux(t)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(resp.Error, tt.wantError) {
+				t.Errorf("error = %q, want it to contain %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// evaluateInterval is how often Run re-evaluates windows. Schedules have
+// minute resolution, so windows open and close within a few seconds of
+// their scheduled times.
+const evaluateInterval = 5 * time.Second
+
+var (
+	// ErrNotFound is returned when no window has the requested ID.
+	ErrNotFound = errors.New("maintenance window not found")
+	// ErrConfigWindow is returned when cancelling a window defined in the
+	// config file; those are removed by editing the config.
+	ErrConfigWindow = errors.New("maintenance window is defined in config")
+	// ErrDuplicateID is returned when creating a window whose ID is taken.
+	ErrDuplicateID = errors.New("maintenance window id already exists")
+	// ErrInvalid wraps validation errors from Create.
+	ErrInvalid = errors.New("invalid maintenance window")
+)
+
+// StateSource provides the services to flag and the events announcing new
+// ones. Defined at the consumer following the same pattern as SSE broker.
+type StateSource interface {
+	All() []state.Service
+	Update(namespace, name string, fn func(*state.Service))
+	Subscribe() <-chan state.Event
+	Unsubscribe(ch <-chan state.Event)
+}
+
+// Status is a window as reported by the API.
+type Status struct {
+	Window
+	Active bool `json:"active"`
+}
+
+// Manager holds the maintenance windows from the config file and those
+// created through the API, and keeps each service's Maintenance flag in step
+// with them. API windows are persisted to a JSON file so they survive
+// restarts; one-off windows are dropped once they end.
+type Manager struct {
+	source StateSource
+	path   string
+	logger *slog.Logger
+	now    func() time.Time
+	wake   chan struct{}
+
+	mu     sync.Mutex
+	config []Window
+	api    []Window
+}
+
+// NewManager creates a manager that persists API windows to path and loads
+// any saved there previously. An empty path disables persistence. If logger
+// is nil, a no-op logger is used.
+func NewManager(source StateSource, path string, logger *slog.Logger) (*Manager, error) {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	m := &Manager{
+		source: source,
+		path:   path,
+		logger: logger,
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+	}
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Manager) load() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved []Window
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("parse %s: %w", m.path, err)
+	}
+	now := m.now()
+	for _, w := range saved {
+		if err := w.Validate(); err != nil {
+			m.logger.Warn("skipping invalid saved maintenance window", "id", w.ID, "error", err)
+			continue
+		}
+		if w.expiredAt(now) {
+			continue
+		}
+		w.Source = SourceAPI
+		m.api = append(m.api, w)
+	}
+	return nil
+}
+
+// saveLocked writes windows to the persistence file, replacing it
+// atomically. Must be called with m.mu held.
+func (m *Manager) saveLocked(windows []Window) error {
+	if m.path == "" {
+		return nil
+	}
+	if windows == nil {
+		windows = []Window{}
+	}
+	data, err := json.MarshalIndent(windows, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
+
+// SetConfigWindows replaces the windows defined in the config file, e.g.
+// after a config reload. Windows that fail validation are skipped, as are
+// those whose ID is taken by an API window, which would otherwise become
+// impossible to cancel.
+func (m *Manager) SetConfigWindows(windows []Window) {
+	m.mu.Lock()
+	valid := make([]Window, 0, len(windows))
+	for _, w := range windows {
+		if err := w.Validate(); err != nil {
+			m.logger.Warn("skipping invalid maintenance window", "id", w.ID, "error", err)
+			continue
+		}
+		if w.ID != "" && slices.ContainsFunc(m.api, func(a Window) bool { return a.ID == w.ID }) {
+			m.logger.Warn("skipping maintenance window whose id is taken by an API window", "id", w.ID)
+			continue
+		}
+		w.Source = SourceConfig
+		valid = append(valid, w)
+	}
+	m.config = valid
+	m.mu.Unlock()
+	m.signal()
+}
+
+// Create validates and adds an API window, generating an ID if it has none,
+// and persists it. It returns the stored window.
+func (m *Manager) Create(w Window) (Window, error) {
+	if err := w.Validate(); err != nil {
+		return Window{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if w.expiredAt(m.now()) {
+		return Window{}, fmt.Errorf("%w: end: must be in the future", ErrInvalid)
+	}
+	w.Source = SourceAPI
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if w.ID == "" {
+		w.ID = newID()
+	}
+	if m.findLocked(w.ID) != nil {
+		return Window{}, ErrDuplicateID
+	}
+	api := append(append([]Window(nil), m.api...), w)
+	if err := m.saveLocked(api); err != nil {
+		return Window{}, fmt.Errorf("save maintenance windows: %w", err)
+	}
+	m.api = api
+	m.signal()
+	return w, nil
+}
+
+// Cancel removes an API window, ending it immediately if it is active.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w := m.findLocked(id)
+	if w == nil {
+		return ErrNotFound
+	}
+	if w.Source == SourceConfig {
+		return ErrConfigWindow
+	}
+	api := make([]Window, 0, len(m.api))
+	for _, other := range m.api {
+		if other.ID != id {
+			api = append(api, other)
+		}
+	}
+	if err := m.saveLocked(api); err != nil {
+		return fmt.Errorf("save maintenance windows: %w", err)
+	}
+	m.api = api
+	m.signal()
+	return nil
+}
+
+// List returns all windows, config windows first, with whether each is
+// currently active.
+func (m *Manager) List() []Status {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Status, 0, len(m.config)+len(m.api))
+	for _, windows := range [][]Window{m.config, m.api} {
+		for _, w := range windows {
+			out = append(out, Status{Window: w, Active: w.ActiveAt(now)})
+		}
+	}
+	return out
+}
+
+func (m *Manager) findLocked(id string) *Window {
+	for _, windows := range [][]Window{m.config, m.api} {
+		for i := range windows {
+			if windows[i].ID == id {
+				return &windows[i]
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run blocks until ctx is cancelled, re-evaluating windows periodically and
+// whenever they change. Newly discovered services are flagged as soon as
+// they appear.
+func (m *Manager) Run(ctx context.Context) {
+	events := m.source.Subscribe()
+	defer m.source.Unsubscribe(events)
+	ticker := time.NewTicker(evaluateInterval)
+	defer ticker.Stop()
+
+	m.apply()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.apply()
+		case <-m.wake:
+			m.apply()
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			switch evt.Type {
+			case state.EventDiscovered:
+				m.applyTo([]state.Service{evt.Service}, m.activeWindows())
+			case state.EventResync:
+				m.apply()
+			}
+		}
+	}
+}
+
+// apply drops ended API windows and brings every service's Maintenance flag
+// in line with the windows active now.
+func (m *Manager) apply() {
+	m.pruneExpired()
+	m.applyTo(m.source.All(), m.activeWindows())
+}
+
+func (m *Manager) pruneExpired() {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	api := make([]Window, 0, len(m.api))
+	for _, w := range m.api {
+		if !w.expiredAt(now) {
+			api = append(api, w)
+		}
+	}
+	if len(api) == len(m.api) {
+		return
+	}
+	if err := m.saveLocked(api); err != nil {
+		m.logger.Warn("failed to save maintenance windows", "error", err)
+	}
+	m.api = api
+}
+
+func (m *Manager) activeWindows() []Window {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var active []Window
+	for _, windows := range [][]Window{m.config, m.api} {
+		for _, w := range windows {
+			if w.ActiveAt(now) {
+				active = append(active, w)
+			}
+		}
+	}
+	return active
+}
+
+func (m *Manager) applyTo(services []state.Service, active []Window) {
+	for _, svc := range services {
+		if svc.Site != "" {
+			continue // Its site reports its maintenance.
+		}
+		id := ""
+		for i := range active {
+			if active[i].Covers(svc) {
+				id = active[i].ID
+				break
+			}
+		}
+		if svc.Maintenance == (id != "") && svc.MaintenanceWindow == id {
+			continue
+		}
+		if id != "" && !svc.Maintenance {
+			m.logger.Info("service entered maintenance", "service", svc.Key(), "window", id)
+		} else if id == "" {
+			m.logger.Info("service left maintenance", "service", svc.Key())
+		}
+		m.source.Update(svc.ScopedNamespace(), svc.Name, func(s *state.Service) {
+			s.Maintenance = id != ""
+			s.MaintenanceWindow = id
+		})
+	}
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
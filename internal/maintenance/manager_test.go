@@ -0,0 +1,210 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+func newTestManager(t *testing.T, store *state.Store, path string, now time.Time) *Manager {
+	t.Helper()
+	m, err := NewManager(store, path, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	m.now = func() time.Time { return now }
+	return m
+}
+
+func TestManager_ApplyFlagsCoveredServices(t *testing.T) {
+	now := time.Date(2026, 3, 4, 22, 30, 0, 0, time.UTC)
+	store := state.NewStore()
+	store.AddOrUpdate(state.Service{Namespace: "media", Name: "jellyfin"})
+	store.AddOrUpdate(state.Service{Namespace: "custom", Name: "truenas", Group: "storage"})
+	store.AddOrUpdate(state.Service{Namespace: "custom", Name: "grafana"})
+	store.AddOrUpdate(state.Service{Site: "east", Namespace: "media", Name: "sonarr", Maintenance: true, MaintenanceWindow: "east-window"})
+
+	m := newTestManager(t, store, "", now)
+	m.SetConfigWindows([]Window{
+		{ID: "nightly", Groups: []string{"storage"}, Schedule: "0 22 * * *", Duration: "1h", Timezone: "UTC"},
+	})
+	created, err := m.Create(Window{
+		Services: []string{"media/*"},
+		Start:    timePtr(now.Add(-time.Minute)),
+		End:      timePtr(now.Add(time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID == "" || created.Source != SourceAPI {
+		t.Fatalf("unexpected created window: %+v", created)
+	}
+	m.apply()
+
+	for _, tc := range []struct {
+		ns, name, window string
+	}{
+		{"media", "jellyfin", created.ID},
+		{"custom", "truenas", "nightly"},
+		{"custom", "grafana", ""},
+		{"east/media", "sonarr", "east-window"}, // federated: kept as its site reports it
+	} {
+		svc, _ := store.Get(tc.ns, tc.name)
+		if svc.Maintenance != (tc.window != "") || svc.MaintenanceWindow != tc.window {
+			t.Errorf("%s/%s: maintenance=%v window=%q, want window %q", tc.ns, tc.name, svc.Maintenance, svc.MaintenanceWindow, tc.window)
+		}
+	}
+
+	if err := m.Cancel(created.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	m.apply()
+	if svc, _ := store.Get("media", "jellyfin"); svc.Maintenance || svc.MaintenanceWindow != "" {
+		t.Errorf("expected jellyfin out of maintenance after cancel, got %+v", svc)
+	}
+
+	if err := m.Cancel("nightly"); !errors.Is(err, ErrConfigWindow) {
+		t.Errorf("Cancel(config window) = %v, want ErrConfigWindow", err)
+	}
+	if err := m.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel(missing) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManager_CreateErrors(t *testing.T) {
+	now := time.Date(2026, 3, 4, 22, 30, 0, 0, time.UTC)
+	m := newTestManager(t, state.NewStore(), "", now)
+	m.SetConfigWindows([]Window{{ID: "nightly", Groups: []string{"storage"}, Schedule: "0 22 * * *", Duration: "1h"}})
+
+	if _, err := m.Create(Window{Groups: []string{"storage"}}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("invalid window: got %v, want ErrInvalid", err)
+	}
+	past := Window{Groups: []string{"storage"}, Start: timePtr(now.Add(-2 * time.Hour)), End: timePtr(now.Add(-time.Hour))}
+	if _, err := m.Create(past); !errors.Is(err, ErrInvalid) {
+		t.Errorf("ended window: got %v, want ErrInvalid", err)
+	}
+	dup := Window{ID: "nightly", Groups: []string{"storage"}, Schedule: "0 1 * * *", Duration: "1h"}
+	if _, err := m.Create(dup); !errors.Is(err, ErrDuplicateID) {
+		t.Errorf("duplicate ID: got %v, want ErrDuplicateID", err)
+	}
+}
+
+func TestManager_ConfigReloadSkipsAPIWindowID(t *testing.T) {
+	now := time.Date(2026, 3, 4, 22, 30, 0, 0, time.UTC)
+	m := newTestManager(t, state.NewStore(), "", now)
+	created, err := m.Create(Window{ID: "upgrade", Groups: []string{"storage"}, Start: timePtr(now), End: timePtr(now.Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// A reload adding a config window with the same ID leaves the API
+	// window in charge of it.
+	m.SetConfigWindows([]Window{
+		{ID: created.ID, Groups: []string{"media"}, Schedule: "0 22 * * *", Duration: "1h"},
+		{ID: "nightly", Groups: []string{"storage"}, Schedule: "0 22 * * *", Duration: "1h"},
+	})
+	list := m.List()
+	if len(list) != 2 || list[0].ID != "nightly" || list[1].ID != created.ID || list[1].Source != SourceAPI {
+		t.Fatalf("unexpected windows after reload: %+v", list)
+	}
+	if err := m.Cancel(created.ID); err != nil {
+		t.Errorf("Cancel(API window) = %v, want nil", err)
+	}
+}
+
+func TestManager_PersistsAPIWindows(t *testing.T) {
+	now := time.Now()
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+
+	m, err := NewManager(state.NewStore(), path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetConfigWindows([]Window{{ID: "nightly", Groups: []string{"storage"}, Schedule: "0 22 * * *", Duration: "1h"}})
+	for _, w := range []Window{
+		{ID: "upgrade", Groups: []string{"storage"}, Start: timePtr(now.Add(time.Hour)), End: timePtr(now.Add(2 * time.Hour))},
+		{ID: "weekly", Services: []string{"media/*"}, Schedule: "0 3 * * 0", Duration: "2h", Reason: "backups"},
+	} {
+		if _, err := m.Create(w); err != nil {
+			t.Fatalf("Create(%s): %v", w.ID, err)
+		}
+	}
+
+	// Config windows are never persisted.
+	reloaded, err := NewManager(state.NewStore(), path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := reloaded.List()
+	if len(list) != 2 || list[0].ID != "upgrade" || list[1].ID != "weekly" || list[1].Reason != "backups" || list[1].Source != SourceAPI {
+		t.Fatalf("unexpected windows after reload: %+v", list)
+	}
+
+	// Ended one-off windows are pruned from the file.
+	m.now = func() time.Time { return now.Add(3 * time.Hour) }
+	m.apply()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, `"weekly"`) || strings.Contains(got, `"upgrade"`) || strings.Contains(got, `"nightly"`) {
+		t.Errorf("unexpected persisted windows: %s", got)
+	}
+}
+
+func TestManager_LoadSkipsEndedWindows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	ended := `[{"id":"old","groups":["storage"],"start":"2020-01-01T00:00:00Z","end":"2020-01-01T01:00:00Z","source":"api"}]`
+	if err := os.WriteFile(path, []byte(ended), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(state.NewStore(), path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := m.List(); len(list) != 0 {
+		t.Errorf("expected ended window to be skipped, got %+v", list)
+	}
+}
+
+func TestManager_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager(state.NewStore(), path, nil); err == nil {
+		t.Fatal("expected error for corrupt maintenance file")
+	}
+}
+
+func TestManager_RunFlagsDiscoveredServices(t *testing.T) {
+	store := state.NewStore()
+	m, err := NewManager(store, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if _, err := m.Create(Window{ID: "upgrade", Services: []string{"media/*"}, Start: timePtr(now.Add(-time.Minute)), End: timePtr(now.Add(time.Hour))}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	store.AddOrUpdate(state.Service{Namespace: "media", Name: "sonarr"})
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if svc, _ := store.Get("media", "sonarr"); svc.Maintenance {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("newly discovered service was not flagged for maintenance")
+}
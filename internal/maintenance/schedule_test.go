@@ -0,0 +1,104 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+	}{
+		{""},
+		{"0 3 * *"},
+		{"0 3 * * * *"},
+		{"60 * * * *"},
+		{"* 24 * * *"},
+		{"* * 0 * *"},
+		{"* * * 13 *"},
+		{"* * * * 8"},
+		{"*/0 * * * *"},
+		{"5-1 * * * *"},
+		{"a * * * *"},
+	}
+	for _, tt := range tests {
+		if _, err := parseSchedule(tt.expr); err == nil {
+			t.Errorf("parseSchedule(%q): expected error", tt.expr)
+		}
+	}
+}
+
+func TestCronSchedule_Matches(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		ts, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		name string
+		expr string
+		at   string
+		want bool
+	}{
+		{"every minute", "* * * * *", "2026-03-04 05:06", true},
+		{"exact time", "30 2 * * *", "2026-03-04 02:30", true},
+		{"wrong minute", "30 2 * * *", "2026-03-04 02:31", false},
+		{"step", "*/15 * * * *", "2026-03-04 02:45", true},
+		{"step miss", "*/15 * * * *", "2026-03-04 02:50", false},
+		{"offset step", "5/20 * * * *", "2026-03-04 02:25", true},
+		{"range", "0 1-3 * * *", "2026-03-04 03:00", true},
+		{"range miss", "0 1-3 * * *", "2026-03-04 04:00", false},
+		{"list", "0 0 1,15 * *", "2026-03-15 00:00", true},
+		{"sunday as 0", "0 3 * * 0", "2026-03-08 03:00", true},
+		{"sunday as 7", "0 3 * * 7", "2026-03-08 03:00", true},
+		{"weekday miss", "0 3 * * 1-5", "2026-03-08 03:00", false},
+		{"month", "0 0 1 6 *", "2026-06-01 00:00", true},
+		// With both day fields restricted, either may match.
+		{"dom or dow by dom", "0 0 1 * 1", "2026-03-01 00:00", true},
+		{"dom or dow by dow", "0 0 1 * 1", "2026-03-02 00:00", true},
+		{"dom or dow neither", "0 0 1 * 1", "2026-03-03 00:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("parseSchedule(%q): %v", tt.expr, err)
+			}
+			if got := c.matches(at(tt.at)); got != tt.want {
+				t.Errorf("matches(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_LastStart(t *testing.T) {
+	c, err := parseSchedule("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 4, 4, 30, 20, 0, time.UTC)
+
+	start, ok := c.lastStart(now, 2*time.Hour, time.UTC)
+	if !ok || !start.Equal(time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("lastStart = %v, %v; want 03:00", start, ok)
+	}
+	if _, ok := c.lastStart(now, time.Hour, time.UTC); ok {
+		t.Error("expected no start within the last hour")
+	}
+
+	// 03:00 in New York is 08:00 UTC.
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	if _, ok := c.lastStart(now, 2*time.Hour, ny); ok {
+		t.Error("expected no New York start within the last two hours")
+	}
+	start, ok = c.lastStart(time.Date(2026, 3, 4, 8, 10, 0, 0, time.UTC), time.Hour, ny)
+	if !ok || !start.Equal(time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("lastStart in New York = %v, %v; want 08:00 UTC", start, ok)
+	}
+}
@@ -0,0 +1,150 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// maxDuration caps a recurring window's length. Finding the current
+// occurrence scans back minute by minute over one duration.
+const maxDuration = 7 * 24 * time.Hour
+
+// Window sources.
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+// Window is a scheduled maintenance window. A one-off window runs from Start
+// to End; a recurring window starts on each match of the cron Schedule, read
+// in Timezone (default: the server's local time), and lasts Duration.
+// Services holds service keys ("namespace/name") or glob patterns, Groups
+// holds group names, and Selector is a label selector; a service covered by
+// any of them is in maintenance while the window is active.
+type Window struct {
+	ID       string     `json:"id"`
+	Reason   string     `json:"reason,omitempty"`
+	Services []string   `json:"services,omitempty"`
+	Groups   []string   `json:"groups,omitempty"`
+	Selector string     `json:"selector,omitempty"`
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+	Schedule string     `json:"schedule,omitempty"`
+	Duration string     `json:"duration,omitempty"`
+	Timezone string     `json:"timezone,omitempty"`
+	Source   string     `json:"source"`
+
+	selector labels.Selector
+	cron     *cronSchedule
+	duration time.Duration
+	loc      *time.Location
+}
+
+// Validate checks the window and prepares it for use. Errors name the
+// offending field, e.g. "schedule: expected 5 fields ...".
+func (w *Window) Validate() error {
+	if len(w.Services) == 0 && len(w.Groups) == 0 && w.Selector == "" {
+		return errors.New("services: set services, groups, or selector")
+	}
+	for _, pattern := range w.Services {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("services: invalid pattern %q", pattern)
+		}
+	}
+
+	if w.Selector != "" {
+		selector, err := labels.Parse(w.Selector)
+		if err != nil {
+			return fmt.Errorf("selector: %w", err)
+		}
+		w.selector = selector
+	}
+
+	oneOff := w.Start != nil || w.End != nil
+	recurring := w.Schedule != "" || w.Duration != ""
+	switch {
+	case oneOff && recurring:
+		return errors.New("schedule: set either start and end, or schedule and duration")
+	case oneOff:
+		if w.Start == nil || w.End == nil {
+			return errors.New("end: start and end are both required")
+		}
+		if !w.End.After(*w.Start) {
+			return errors.New("end: must be after start")
+		}
+		if w.Timezone != "" {
+			return errors.New("timezone: only applies to a schedule")
+		}
+	case recurring:
+		cron, err := parseSchedule(w.Schedule)
+		if err != nil {
+			return fmt.Errorf("schedule: %w", err)
+		}
+		d, err := time.ParseDuration(w.Duration)
+		if err != nil {
+			return fmt.Errorf("duration: invalid duration %q", w.Duration)
+		}
+		if d <= 0 || d > maxDuration {
+			return fmt.Errorf("duration: must be positive and at most %s", maxDuration)
+		}
+		loc := time.Local
+		if w.Timezone != "" {
+			if loc, err = time.LoadLocation(w.Timezone); err != nil {
+				return fmt.Errorf("timezone: unknown time zone %q", w.Timezone)
+			}
+		}
+		w.cron, w.duration, w.loc = cron, d, loc
+	default:
+		return errors.New("schedule: set either start and end, or schedule and duration")
+	}
+	return nil
+}
+
+// ActiveAt reports whether the window is in effect at now.
+func (w *Window) ActiveAt(now time.Time) bool {
+	if w.cron == nil {
+		return w.Start != nil && w.End != nil && !now.Before(*w.Start) && now.Before(*w.End)
+	}
+	start, ok := w.cron.lastStart(now, w.duration, w.loc)
+	return ok && now.Before(start.Add(w.duration))
+}
+
+// expiredAt reports whether a one-off window has ended by now.
+func (w *Window) expiredAt(now time.Time) bool {
+	return w.cron == nil && w.End != nil && !now.Before(*w.End)
+}
+
+// Covers reports whether the window applies to svc.
+func (w *Window) Covers(svc state.Service) bool {
+	for _, group := range w.Groups {
+		if group == svc.Group {
+			return true
+		}
+	}
+	if w.selector != nil && w.selector.Matches(labels.Set(svc.Labels)) {
+		return true
+	}
+	// Patterns match "namespace/name", or the full key ("cluster/namespace/name"
+	// or "site/namespace/name") to target one cluster or site.
+	key, scopedKey := svc.Namespace+"/"+svc.Name, svc.Key()
+	for _, pattern := range w.Services {
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+		if scopedKey != key {
+			if ok, _ := path.Match(pattern, scopedKey); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package maintenance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func TestWindow_Validate(t *testing.T) {
+	start := time.Date(2026, 3, 4, 22, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	tests := []struct {
+		name    string
+		window  Window
+		wantErr string
+	}{
+		{"one-off", Window{Services: []string{"media/*"}, Start: &start, End: &end}, ""},
+		{"recurring", Window{Groups: []string{"storage"}, Schedule: "0 3 * * 0", Duration: "2h", Timezone: "UTC"}, ""},
+		{"selector only", Window{Selector: "tier=critical", Start: &start, End: &end}, ""},
+		{"bad selector", Window{Selector: "tier in (a", Start: &start, End: &end}, "selector: "},
+		{"no targets", Window{Start: &start, End: &end}, "services: set services, groups, or selector"},
+		{"bad pattern", Window{Services: []string{"media/["}, Start: &start, End: &end}, "services: invalid pattern"},
+		{"no timing", Window{Groups: []string{"storage"}}, "schedule: set either"},
+		{"both timings", Window{Groups: []string{"storage"}, Start: &start, End: &end, Schedule: "0 3 * * *", Duration: "1h"}, "schedule: set either"},
+		{"missing end", Window{Groups: []string{"storage"}, Start: &start}, "end: start and end are both required"},
+		{"end before start", Window{Groups: []string{"storage"}, Start: &end, End: &start}, "end: must be after start"},
+		{"timezone on one-off", Window{Groups: []string{"storage"}, Start: &start, End: &end, Timezone: "UTC"}, "timezone: only applies"},
+		{"bad schedule", Window{Groups: []string{"storage"}, Schedule: "0 3 * *", Duration: "1h"}, "schedule: expected 5 fields"},
+		{"missing duration", Window{Groups: []string{"storage"}, Schedule: "0 3 * * *"}, "duration: invalid duration"},
+		{"duration too long", Window{Groups: []string{"storage"}, Schedule: "0 3 * * *", Duration: "200h"}, "duration: must be positive"},
+		{"bad timezone", Window{Groups: []string{"storage"}, Schedule: "0 3 * * *", Duration: "1h", Timezone: "Mars/Olympus"}, "timezone: unknown time zone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.window.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWindow_ActiveAt(t *testing.T) {
+	start := time.Date(2026, 3, 4, 22, 0, 0, 0, time.UTC)
+	oneOff := Window{Groups: []string{"storage"}, Start: timePtr(start), End: timePtr(start.Add(2 * time.Hour))}
+	// Sundays 03:00-05:00 UTC; 2026-03-08 is a Sunday.
+	weekly := Window{Groups: []string{"storage"}, Schedule: "0 3 * * 0", Duration: "2h", Timezone: "UTC"}
+	for _, w := range []*Window{&oneOff, &weekly} {
+		if err := w.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		window *Window
+		at     time.Time
+		want   bool
+	}{
+		{"before one-off", &oneOff, start.Add(-time.Second), false},
+		{"one-off start", &oneOff, start, true},
+		{"one-off end is exclusive", &oneOff, start.Add(2 * time.Hour), false},
+		{"before occurrence", &weekly, time.Date(2026, 3, 8, 2, 59, 0, 0, time.UTC), false},
+		{"occurrence start", &weekly, time.Date(2026, 3, 8, 3, 0, 0, 0, time.UTC), true},
+		{"during occurrence", &weekly, time.Date(2026, 3, 8, 4, 59, 59, 0, time.UTC), true},
+		{"after occurrence", &weekly, time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC), false},
+		{"other day", &weekly, time.Date(2026, 3, 9, 4, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.ActiveAt(tt.at); got != tt.want {
+				t.Errorf("ActiveAt(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow_Covers(t *testing.T) {
+	w := Window{
+		Services: []string{"media/*", "custom/truenas", "lab/db/*"},
+		Groups:   []string{"storage"},
+		Selector: "tier=critical,exposure!=public",
+		Start:    timePtr(time.Now()),
+		End:      timePtr(time.Now().Add(time.Hour)),
+	}
+	if err := w.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		svc  state.Service
+		want bool
+	}{
+		{"glob", state.Service{Namespace: "media", Name: "jellyfin"}, true},
+		{"exact key", state.Service{Namespace: "custom", Name: "truenas"}, true},
+		{"group", state.Service{Namespace: "custom", Name: "minio", Group: "storage"}, true},
+		{"selector", state.Service{Namespace: "db", Name: "postgres", Labels: map[string]string{"tier": "critical"}}, true},
+		{"selector excludes", state.Service{Namespace: "db", Name: "api", Labels: map[string]string{"tier": "critical", "exposure": "public"}}, false},
+		{"glob in any cluster", state.Service{Cluster: "prod", Namespace: "media", Name: "jellyfin"}, true},
+		{"cluster key", state.Service{Cluster: "lab", Namespace: "db", Name: "redis"}, true},
+		{"other cluster", state.Service{Cluster: "prod", Namespace: "db", Name: "redis"}, false},
+		{"site key", state.Service{Site: "lab", Namespace: "db", Name: "redis"}, true},
+		{"unmatched", state.Service{Namespace: "custom", Name: "grafana", Group: "monitoring"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.Covers(tt.svc); got != tt.want {
+				t.Errorf("Covers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	all := Window{Services: []string{"*"}}
+	if !all.Covers(state.Service{Namespace: "any", Name: "thing"}) || !all.Covers(state.Service{Cluster: "lab", Namespace: "any", Name: "thing"}) {
+		t.Error(`"*" should cover every service`)
+	}
+}
@@ -0,0 +1,314 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rathix/command-center/internal/notify"
+	"github.com/rathix/command-center/internal/state"
+)
+
+// ServiceSource provides the services to report on.
+type ServiceSource interface {
+	All() []state.Service
+}
+
+// StoreStats reports events the state store could not deliver.
+type StoreStats interface {
+	DroppedEvents() uint64
+}
+
+// CheckerStats reports health check overruns.
+type CheckerStats interface {
+	Overruns() uint64
+}
+
+// ClientCounter reports connected SSE clients.
+type ClientCounter interface {
+	ClientCount() int
+}
+
+// DeliveryCounter reports notification delivery outcomes per adapter.
+type DeliveryCounter interface {
+	Stats() map[string]notify.DeliveryStats
+}
+
+// SessionCounter reports active terminal sessions.
+type SessionCounter interface {
+	SessionCount() int
+}
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithStore reports the store's dropped subscriber events.
+func WithStore(s StoreStats) Option {
+	return func(c *Collector) { c.store = s }
+}
+
+// WithChecker reports health check overruns.
+func WithChecker(s CheckerStats) Option {
+	return func(c *Collector) { c.checker = s }
+}
+
+// WithSSE reports connected SSE clients.
+func WithSSE(s ClientCounter) Option {
+	return func(c *Collector) { c.sse = s }
+}
+
+// WithNotifications reports notification delivery outcomes.
+func WithNotifications(s DeliveryCounter) Option {
+	return func(c *Collector) { c.notify = s }
+}
+
+// WithTerminal reports active terminal sessions.
+func WithTerminal(s SessionCounter) Option {
+	return func(c *Collector) { c.terminal = s }
+}
+
+// serviceStatuses are the values of the status label, one series each.
+var serviceStatuses = []state.HealthStatus{
+	state.StatusHealthy,
+	state.StatusDegraded,
+	state.StatusUnhealthy,
+	state.StatusUnknown,
+}
+
+// Collector gathers metrics for the /metrics endpoint. Service state and
+// internal counters are read at scrape time; response times and check cycle
+// durations are accumulated as they are observed, so Collector also
+// implements health.Metrics. Sources not configured through options are
+// left out of the output.
+type Collector struct {
+	services ServiceSource
+	store    StoreStats
+	checker  CheckerStats
+	sse      ClientCounter
+	notify   DeliveryCounter
+	terminal SessionCounter
+
+	mu            sync.Mutex
+	responseTimes map[string]*histogram // by service key
+	cycles        *histogram
+}
+
+// NewCollector creates a collector reporting on the services from source.
+func NewCollector(services ServiceSource, opts ...Option) *Collector {
+	c := &Collector{
+		services:      services,
+		responseTimes: make(map[string]*histogram),
+		cycles:        newHistogram(cycleBuckets),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ObserveCheck records a probe's response time. namespace is the service's
+// scoped namespace.
+func (c *Collector) ObserveCheck(namespace, name string, responseTime time.Duration) {
+	key := namespace + "/" + name
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.responseTimes[key]
+	if !ok {
+		h = newHistogram(responseTimeBuckets)
+		c.responseTimes[key] = h
+	}
+	h.observe(responseTime.Seconds())
+}
+
+// ObserveCycle records how long a health check pass took.
+func (c *Collector) ObserveCycle(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cycles.observe(d.Seconds())
+}
+
+type label struct {
+	name, value string
+}
+
+// Write renders all metrics in the OpenMetrics text format.
+func (c *Collector) Write() []byte {
+	services := c.services.All()
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].ScopedNamespace() != services[j].ScopedNamespace() {
+			return services[i].ScopedNamespace() < services[j].ScopedNamespace()
+		}
+		return services[i].Name < services[j].Name
+	})
+
+	// Take histogram snapshots, forgetting services that are gone.
+	present := make(map[string]struct{}, len(services))
+	for _, svc := range services {
+		present[svc.Key()] = struct{}{}
+	}
+	c.mu.Lock()
+	for key := range c.responseTimes {
+		if _, ok := present[key]; !ok {
+			delete(c.responseTimes, key)
+		}
+	}
+	responseTimes := make(map[string]histogram, len(c.responseTimes))
+	for key, h := range c.responseTimes {
+		responseTimes[key] = h.snapshot()
+	}
+	cycles := c.cycles.snapshot()
+	c.mu.Unlock()
+
+	var w expWriter
+
+	w.family("command_center_service_status", "gauge", "Composite health status of a service: 1 for the current status, 0 for the others.")
+	for _, svc := range services {
+		current := svc.CompositeStatus
+		if current == "" {
+			current = state.StatusUnknown
+		}
+		for _, status := range serviceStatuses {
+			v := 0.0
+			if status == current {
+				v = 1
+			}
+			w.sample("command_center_service_status", append(serviceLabels(svc), label{"status", string(status)}), v)
+		}
+	}
+
+	w.family("command_center_service_response_time_seconds", "histogram", "Health probe response time.")
+	for _, svc := range services {
+		if h, ok := responseTimes[svc.Key()]; ok {
+			w.histogram("command_center_service_response_time_seconds", serviceKeyLabels(svc), h)
+		}
+	}
+
+	w.family("command_center_service_endpoints_ready", "gauge", "Ready Kubernetes endpoints backing a service.")
+	for _, svc := range services {
+		if svc.ReadyEndpoints != nil {
+			w.sample("command_center_service_endpoints_ready", serviceLabels(svc), float64(*svc.ReadyEndpoints))
+		}
+	}
+	w.family("command_center_service_endpoints_total", "gauge", "Kubernetes endpoints backing a service.")
+	for _, svc := range services {
+		if svc.TotalEndpoints != nil {
+			w.sample("command_center_service_endpoints_total", serviceLabels(svc), float64(*svc.TotalEndpoints))
+		}
+	}
+
+	w.family("command_center_health_check_cycle_duration_seconds", "histogram", "Time for a health check pass to finish every check it started.")
+	w.histogram("command_center_health_check_cycle_duration_seconds", nil, cycles)
+
+	if c.checker != nil {
+		w.family("command_center_health_check_overruns", "counter", "Checks that fell due while the previous check of the same service was still running.")
+		w.sample("command_center_health_check_overruns_total", nil, float64(c.checker.Overruns()))
+	}
+	if c.store != nil {
+		w.family("command_center_store_dropped_events", "counter", "State events not delivered to a subscriber whose buffer was full.")
+		w.sample("command_center_store_dropped_events_total", nil, float64(c.store.DroppedEvents()))
+	}
+	if c.sse != nil {
+		w.family("command_center_sse_clients", "gauge", "Connected SSE clients.")
+		w.sample("command_center_sse_clients", nil, float64(c.sse.ClientCount()))
+	}
+	if c.notify != nil {
+		stats := c.notify.Stats()
+		adapters := make([]string, 0, len(stats))
+		for name := range stats {
+			adapters = append(adapters, name)
+		}
+		sort.Strings(adapters)
+		w.family("command_center_notifications", "counter", "Notification deliveries by adapter and final result.")
+		for _, name := range adapters {
+			s := stats[name]
+			w.sample("command_center_notifications_total", []label{{"adapter", name}, {"result", "success"}}, float64(s.Succeeded))
+			w.sample("command_center_notifications_total", []label{{"adapter", name}, {"result", "failure"}}, float64(s.Failed))
+		}
+	}
+	if c.terminal != nil {
+		w.family("command_center_terminal_sessions", "gauge", "Active terminal sessions.")
+		w.sample("command_center_terminal_sessions", nil, float64(c.terminal.SessionCount()))
+	}
+
+	w.buf.WriteString("# EOF\n")
+	return w.buf.Bytes()
+}
+
+// serviceKeyLabels identify a service: its namespace and name, plus its
+// cluster and site if it has them.
+func serviceKeyLabels(svc state.Service) []label {
+	labels := []label{{"namespace", svc.Namespace}, {"name", svc.Name}}
+	if svc.Cluster != "" {
+		labels = append(labels, label{"cluster", svc.Cluster})
+	}
+	if svc.Site != "" {
+		labels = append(labels, label{"site", svc.Site})
+	}
+	return labels
+}
+
+func serviceLabels(svc state.Service) []label {
+	return append(serviceKeyLabels(svc), label{"group", svc.Group}, label{"source", svc.Source})
+}
+
+// expWriter writes the OpenMetrics text format.
+type expWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *expWriter) family(name, typ, help string) {
+	w.buf.WriteString("# TYPE " + name + " " + typ + "\n")
+	w.buf.WriteString("# HELP " + name + " " + help + "\n")
+}
+
+func (w *expWriter) sample(name string, labels []label, v float64) {
+	w.buf.WriteString(name)
+	if len(labels) > 0 {
+		w.buf.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.buf.WriteString(l.name + `="` + escapeLabel(l.value) + `"`)
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteString(" " + formatFloat(v) + "\n")
+}
+
+func (w *expWriter) histogram(name string, labels []label, h histogram) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		w.sample(name+"_bucket", append(labels[:len(labels):len(labels)], label{"le", formatFloat(bound)}), float64(cumulative))
+	}
+	w.sample(name+"_bucket", append(labels[:len(labels):len(labels)], label{"le", "+Inf"}), float64(h.count))
+	w.sample(name+"_count", labels, float64(h.count))
+	w.sample(name+"_sum", labels, h.sum)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// formatFloat renders v canonically: integers keep a ".0" so bucket bounds
+// like 1 read "1.0", as OpenMetrics expects.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
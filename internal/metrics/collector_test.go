@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rathix/command-center/internal/notify"
+	"github.com/rathix/command-center/internal/state"
+)
+
+type fakeServices []state.Service
+
+func (f fakeServices) All() []state.Service { return f }
+
+type fakeStats struct{}
+
+func (fakeStats) DroppedEvents() uint64 { return 3 }
+func (fakeStats) Overruns() uint64      { return 2 }
+func (fakeStats) ClientCount() int      { return 4 }
+func (fakeStats) SessionCount() int     { return 1 }
+func (fakeStats) Stats() map[string]notify.DeliveryStats {
+	return map[string]notify.DeliveryStats{"webhook": {Succeeded: 7, Failed: 1}}
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestCollector_Write(t *testing.T) {
+	services := fakeServices{
+		{Name: "jellyfin", Namespace: "media", Group: "media", Source: state.SourceKubernetes,
+			CompositeStatus: state.StatusDegraded, ReadyEndpoints: intPtr(1), TotalEndpoints: intPtr(2)},
+		{Name: "truenas", Namespace: "custom", Group: "storage", Source: "config"},
+		{Name: "jellyfin", Namespace: "media", Cluster: "lab", Group: "media", Source: state.SourceKubernetes,
+			CompositeStatus: state.StatusHealthy},
+		{Name: "jellyfin", Namespace: "media", Site: "east", Group: "media", Source: state.SourceKubernetes,
+			CompositeStatus: state.StatusUnhealthy},
+	}
+	stats := fakeStats{}
+	c := NewCollector(services,
+		WithStore(stats), WithChecker(stats), WithSSE(stats), WithNotifications(stats), WithTerminal(stats))
+	c.ObserveCheck("media", "jellyfin", 30*time.Millisecond)
+	c.ObserveCheck("media", "jellyfin", 2*time.Second)
+	c.ObserveCheck("lab/media", "jellyfin", 40*time.Millisecond)
+	c.ObserveCycle(700 * time.Millisecond)
+
+	out := string(c.Write())
+	for _, want := range []string{
+		"# TYPE command_center_service_status gauge\n",
+		`command_center_service_status{namespace="media",name="jellyfin",group="media",source="kubernetes",status="degraded"} 1.0`,
+		`command_center_service_status{namespace="media",name="jellyfin",group="media",source="kubernetes",status="healthy"} 0.0`,
+		`command_center_service_status{namespace="custom",name="truenas",group="storage",source="config",status="unknown"} 1.0`,
+		"# TYPE command_center_service_response_time_seconds histogram\n",
+		`command_center_service_response_time_seconds_bucket{namespace="media",name="jellyfin",le="0.025"} 0.0`,
+		`command_center_service_response_time_seconds_bucket{namespace="media",name="jellyfin",le="0.05"} 1.0`,
+		`command_center_service_response_time_seconds_bucket{namespace="media",name="jellyfin",le="2.5"} 2.0`,
+		`command_center_service_response_time_seconds_bucket{namespace="media",name="jellyfin",le="+Inf"} 2.0`,
+		`command_center_service_response_time_seconds_count{namespace="media",name="jellyfin"} 2.0`,
+		`command_center_service_response_time_seconds_sum{namespace="media",name="jellyfin"} 2.03`,
+		`command_center_service_status{namespace="media",name="jellyfin",cluster="lab",group="media",source="kubernetes",status="healthy"} 1.0`,
+		`command_center_service_response_time_seconds_count{namespace="media",name="jellyfin",cluster="lab"} 1.0`,
+		`command_center_service_status{namespace="media",name="jellyfin",site="east",group="media",source="kubernetes",status="unhealthy"} 1.0`,
+		`command_center_service_endpoints_ready{namespace="media",name="jellyfin",group="media",source="kubernetes"} 1.0`,
+		`command_center_service_endpoints_total{namespace="media",name="jellyfin",group="media",source="kubernetes"} 2.0`,
+		`command_center_health_check_cycle_duration_seconds_bucket{le="1.0"} 1.0`,
+		`command_center_health_check_cycle_duration_seconds_count 1.0`,
+		"# TYPE command_center_health_check_overruns counter\n",
+		"command_center_health_check_overruns_total 2.0",
+		"command_center_store_dropped_events_total 3.0",
+		"command_center_sse_clients 4.0",
+		`command_center_notifications_total{adapter="webhook",result="success"} 7.0`,
+		`command_center_notifications_total{adapter="webhook",result="failure"} 1.0`,
+		"command_center_terminal_sessions 1.0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Error("output must end with # EOF")
+	}
+	// Services without endpoint data have no endpoint series.
+	if strings.Contains(out, `command_center_service_endpoints_ready{namespace="custom"`) {
+		t.Error("unexpected endpoint series for a service without endpoint data")
+	}
+}
+
+func TestCollector_OmitsUnconfiguredSources(t *testing.T) {
+	out := string(NewCollector(fakeServices{}).Write())
+	for _, name := range []string{"overruns", "dropped_events", "sse_clients", "notifications", "terminal_sessions"} {
+		if strings.Contains(out, name) {
+			t.Errorf("unexpected %s metric without its source", name)
+		}
+	}
+}
+
+func TestCollector_ForgetsRemovedServices(t *testing.T) {
+	services := fakeServices{{Name: "jellyfin", Namespace: "media"}}
+	c := NewCollector(services)
+	c.ObserveCheck("media", "jellyfin", time.Millisecond)
+	c.ObserveCheck("media", "sonarr", time.Millisecond)
+
+	out := string(c.Write())
+	if strings.Contains(out, `name="sonarr"`) {
+		t.Error("response times for a service not in the store should not be exported")
+	}
+	if _, ok := c.responseTimes["media/sonarr"]; ok {
+		t.Error("response times for a removed service should be forgotten")
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabel = %q", got)
+	}
+}
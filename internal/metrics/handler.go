@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+const contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Auth holds the credentials a scraper must present: a bearer token, or a
+// basic auth username and password. Secrets are resolved on every request,
+// so rotated values take effect without a restart.
+type Auth struct {
+	BearerToken *state.SecretRef
+	Username    string
+	Password    *state.SecretRef
+}
+
+// NewHandler serves the collector's metrics. If auth is nil the handler
+// performs no authentication of its own. If logger is nil, a no-op logger is
+// used.
+func NewHandler(c *Collector, auth *Auth, logger *slog.Logger) http.Handler {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth != nil {
+			ok, err := auth.check(r)
+			if err != nil {
+				logger.Error("metrics credentials unavailable", "error", err)
+				http.Error(w, "metrics credentials unavailable", http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				if auth.BearerToken != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				} else {
+					w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+				}
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(c.Write())
+	})
+}
+
+func (a *Auth) check(r *http.Request) (bool, error) {
+	if a.BearerToken != nil {
+		want, err := a.BearerToken.Resolve()
+		if err != nil {
+			return false, err
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && secureEqual(got, want), nil
+	}
+	if a.Password == nil {
+		return true, nil
+	}
+	want, err := a.Password.Resolve()
+	if err != nil {
+		return false, err
+	}
+	user, pass, ok := r.BasicAuth()
+	// Evaluate both comparisons so timing does not reveal which one failed.
+	userOK := secureEqual(user, a.Username)
+	passOK := secureEqual(pass, want)
+	return ok && userOK && passOK, nil
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+func TestHandler_Auth(t *testing.T) {
+	t.Setenv("METRICS_TEST_TOKEN", "s3cret")
+	bearer := &Auth{BearerToken: &state.SecretRef{Env: "METRICS_TEST_TOKEN"}}
+	basic := &Auth{Username: "prom", Password: &state.SecretRef{Value: "pw"}}
+	broken := &Auth{BearerToken: &state.SecretRef{Env: "METRICS_TEST_UNSET"}}
+
+	tests := []struct {
+		name       string
+		auth       *Auth
+		setup      func(r *http.Request)
+		wantStatus int
+	}{
+		{"no auth", nil, func(*http.Request) {}, http.StatusOK},
+		{"bearer ok", bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, http.StatusOK},
+		{"bearer wrong", bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"bearer missing", bearer, func(*http.Request) {}, http.StatusUnauthorized},
+		{"basic ok", basic, func(r *http.Request) { r.SetBasicAuth("prom", "pw") }, http.StatusOK},
+		{"basic wrong user", basic, func(r *http.Request) { r.SetBasicAuth("admin", "pw") }, http.StatusUnauthorized},
+		{"basic wrong password", basic, func(r *http.Request) { r.SetBasicAuth("prom", "nope") }, http.StatusUnauthorized},
+		{"unresolvable secret", broken, func(r *http.Request) { r.Header.Set("Authorization", "Bearer x") }, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(NewCollector(fakeServices{}), tt.auth, nil)
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			tt.setup(req)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			switch rec.Code {
+			case http.StatusOK:
+				if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				if !strings.HasSuffix(rec.Body.String(), "# EOF\n") {
+					t.Error("body is not a complete OpenMetrics exposition")
+				}
+			case http.StatusUnauthorized:
+				if rec.Header().Get("WWW-Authenticate") == "" {
+					t.Error("missing WWW-Authenticate header")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package metrics
+
+// Bucket upper bounds, in seconds.
+var (
+	responseTimeBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	cycleBuckets        = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+)
+
+// histogram counts observations into fixed buckets. It is not safe for
+// concurrent use; the Collector guards it.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// snapshot returns a copy safe to read after the Collector's lock is
+// released.
+func (h *histogram) snapshot() histogram {
+	cp := *h
+	cp.counts = append([]uint64(nil), h.counts...)
+	return cp
+}
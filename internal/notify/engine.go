@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/rathix/command-center/internal/state"
 )
@@ -11,6 +12,7 @@ import (
 // StateSource provides event subscription for the notification engine.
 // Defined at the consumer following the same pattern as SSE broker.
 type StateSource interface {
+	All() []state.Service
 	Subscribe() <-chan state.Event
 	Unsubscribe(ch <-chan state.Event)
 }
@@ -24,6 +26,7 @@ type Engine struct {
 	dispatcher  *RetryDispatcher
 	logger      *slog.Logger
 	prevState   map[string]state.HealthStatus
+	held        map[string]state.HealthStatus // status before a transition suppressed as blocked or in maintenance
 }
 
 // NewEngine creates a notification engine with the given source and adapters.
@@ -33,6 +36,7 @@ func NewEngine(source StateSource, adapters map[string]Adapter, opts ...Option)
 		adapters:  adapters,
 		logger:    slog.Default(),
 		prevState: make(map[string]state.HealthStatus),
+		held:      make(map[string]state.HealthStatus),
 	}
 	for _, opt := range opts {
 		opt(e)
@@ -68,7 +72,14 @@ func WithRetryDispatcher(d *RetryDispatcher) Option {
 }
 
 // Run blocks until context cancellation, processing state events and dispatching notifications.
+// The status of services already in the store, e.g. restored from a snapshot,
+// is taken as their previous state, so their first transition notifies.
 func (e *Engine) Run(ctx context.Context) {
+	// Seed before subscribing: a transition in between is then seen at the
+	// service's next update rather than replayed against a newer status.
+	for _, svc := range e.source.All() {
+		e.prevState[svc.Key()] = svc.CompositeStatus
+	}
 	ch := e.source.Subscribe()
 	defer e.source.Unsubscribe(ch)
 
@@ -95,7 +106,7 @@ func (e *Engine) Run(ctx context.Context) {
 func (e *Engine) handleEvent(ctx context.Context, evt state.Event) {
 	switch evt.Type {
 	case state.EventDiscovered:
-		key := serviceKey(evt.Service.Namespace, evt.Service.Name)
+		key := evt.Service.Key()
 		e.prevState[key] = evt.Service.CompositeStatus
 		e.logger.Debug("service discovered, stored initial state",
 			"service", key,
@@ -103,7 +114,7 @@ func (e *Engine) handleEvent(ctx context.Context, evt state.Event) {
 		)
 
 	case state.EventUpdated:
-		key := serviceKey(evt.Service.Namespace, evt.Service.Name)
+		key := evt.Service.Key()
 		prev, exists := e.prevState[key]
 		newStatus := evt.Service.CompositeStatus
 		e.prevState[key] = newStatus
@@ -118,6 +129,11 @@ func (e *Engine) handleEvent(ctx context.Context, evt state.Event) {
 		}
 
 		if prev == newStatus {
+			e.handleReleased(ctx, key, evt.Service)
+			return
+		}
+
+		if e.suppressHeld(key, prev, evt.Service) {
 			return
 		}
 
@@ -129,17 +145,102 @@ func (e *Engine) handleEvent(ctx context.Context, evt state.Event) {
 
 		notification := buildNotification(evt.Service, prev)
 
-		e.dispatchForTransition(ctx, key, newStatus, notification)
+		e.dispatchForTransition(ctx, evt.Service, notification)
 
 	case state.EventRemoved:
-		key := serviceKey(evt.Namespace, evt.Name)
+		key := evt.Key()
 		delete(e.prevState, key)
+		delete(e.held, key)
 		e.suppression.Reset(key)
 		e.logger.Debug("service removed, cleaned up state", "service", key)
+
+	case state.EventResync:
+		e.resync(ctx)
+	}
+}
+
+// resync reconciles prevState with the store after missed events, replaying
+// each service's current state as the event that would bring it there. Only
+// the net change since the last seen state is notified.
+func (e *Engine) resync(ctx context.Context) {
+	services := e.source.All()
+	e.logger.Warn("notification engine fell behind, resyncing", "services", len(services))
+	present := make(map[string]struct{}, len(services))
+	for _, svc := range services {
+		key := svc.Key()
+		present[key] = struct{}{}
+		typ := state.EventUpdated
+		if _, ok := e.prevState[key]; !ok {
+			typ = state.EventDiscovered
+		}
+		e.handleEvent(ctx, state.Event{Type: typ, Service: svc})
+	}
+	for key := range e.prevState {
+		if _, ok := present[key]; ok {
+			continue
+		}
+		namespace, name, _ := strings.Cut(key, "/")
+		e.handleEvent(ctx, state.Event{Type: state.EventRemoved, Namespace: namespace, Name: name})
 	}
 }
 
-func (e *Engine) dispatchForTransition(ctx context.Context, serviceKey string, newStatus state.HealthStatus, notification Notification) {
+// suppressHeld reports whether a transition should be held back: for a
+// service in a maintenance window, where the change is expected, for one
+// blocked by a failing dependency, in favor of the root cause's own
+// notification, or for a flapping one. The recovery of a service whose
+// outage was held back is suppressed as well.
+func (e *Engine) suppressHeld(key string, prev state.HealthStatus, svc state.Service) bool {
+	if svc.Maintenance || len(svc.BlockedBy) > 0 || svc.Flapping {
+		if _, ok := e.held[key]; !ok {
+			e.held[key] = prev
+		}
+		if svc.Flapping {
+			e.logger.Debug("notification suppressed for flapping service",
+				"service", key,
+				"to", svc.CompositeStatus,
+			)
+		} else if svc.Maintenance {
+			e.logger.Debug("notification suppressed for service in maintenance",
+				"service", key,
+				"to", svc.CompositeStatus,
+				"window", svc.MaintenanceWindow,
+			)
+		} else {
+			e.logger.Debug("notification suppressed for blocked service",
+				"service", key,
+				"to", svc.CompositeStatus,
+				"blockedBy", svc.BlockedBy,
+			)
+		}
+		return true
+	}
+	if _, ok := e.held[key]; ok && svc.CompositeStatus == state.StatusHealthy {
+		delete(e.held, key)
+		e.logger.Debug("recovery notification suppressed for previously held service", "service", key)
+		return true
+	}
+	delete(e.held, key)
+	return false
+}
+
+// handleReleased sends the notification held back while a service was
+// blocked, in maintenance or flapping once that ends and it is still failing
+// on its own.
+func (e *Engine) handleReleased(ctx context.Context, key string, svc state.Service) {
+	before, ok := e.held[key]
+	if !ok || svc.Maintenance || len(svc.BlockedBy) > 0 || svc.Flapping {
+		return
+	}
+	delete(e.held, key)
+	if svc.CompositeStatus == state.StatusHealthy || svc.CompositeStatus == before {
+		return
+	}
+	e.logger.Debug("held service still failing after release", "service", key)
+	e.dispatchForTransition(ctx, svc, buildNotification(svc, before))
+}
+
+func (e *Engine) dispatchForTransition(ctx context.Context, svc state.Service, notification Notification) {
+	key, newStatus := svc.Key(), svc.CompositeStatus
 	if e.matcher == nil {
 		// No rules configured: dispatch to all adapters for unhealthy/degraded
 		if newStatus == state.StatusUnhealthy || newStatus == state.StatusDegraded {
@@ -153,11 +254,11 @@ func (e *Engine) dispatchForTransition(ctx context.Context, serviceKey string, n
 	// With rule matcher: evaluate each matching rule through suppression
 	rules := e.matcher.Rules()
 	for ruleIdx, rule := range rules {
-		if !ruleMatchesEvent(rule, serviceKey, newStatus) {
+		if !e.matcher.matches(ruleIdx, svc, newStatus) {
 			continue
 		}
 
-		decision := e.suppression.Evaluate(serviceKey, ruleIdx, rule, newStatus)
+		decision := e.suppression.Evaluate(svc, ruleIdx, rule, newStatus)
 		switch decision.Action {
 		case Allow, Escalate:
 			if decision.Action == Escalate {
@@ -166,7 +267,7 @@ func (e *Engine) dispatchForTransition(ctx context.Context, serviceKey string, n
 			e.dispatchToChannels(ctx, decision.Channels, notification)
 		case Suppress:
 			e.logger.Debug("notification suppressed",
-				"service", serviceKey,
+				"service", key,
 				"rule", ruleIdx,
 			)
 		}
@@ -174,7 +275,7 @@ func (e *Engine) dispatchForTransition(ctx context.Context, serviceKey string, n
 
 	// Recovery resets suppression state
 	if newStatus == state.StatusHealthy {
-		e.suppression.Reset(serviceKey)
+		e.suppression.Reset(key)
 	}
 }
 
@@ -194,15 +295,13 @@ func (e *Engine) dispatchToChannels(ctx context.Context, channels []string, n No
 	}
 }
 
-func serviceKey(namespace, name string) string {
-	return namespace + "/" + name
-}
-
 // buildNotification constructs a Notification with diagnostic context from the service.
 func buildNotification(svc state.Service, prevStatus state.HealthStatus) Notification {
 	n := Notification{
 		ServiceName: svc.Name,
 		Namespace:   svc.Namespace,
+		Cluster:     svc.Cluster,
+		Site:        svc.Site,
 		PrevState:   prevStatus,
 		NewState:    svc.CompositeStatus,
 		Timestamp:   svc.LastChecked.UTC(),
@@ -224,6 +323,9 @@ func buildNotification(svc state.Service, prevStatus state.HealthStatus) Notific
 				*svc.ReadyEndpoints, *svc.TotalEndpoints))
 		}
 	}
+	if svc.LatencyBreach != "" {
+		n.Signals = append(n.Signals, "latency:"+svc.LatencyBreach)
+	}
 	if svc.ErrorSnippet != nil {
 		n.Signals = append(n.Signals, "error:"+*svc.ErrorSnippet)
 	}
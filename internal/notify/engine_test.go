@@ -47,8 +47,10 @@ func (f *fakeAdapter) sentNotifications() []Notification {
 }
 
 type fakeStateSource struct {
-	ch   chan state.Event
-	done chan struct{}
+	ch       chan state.Event
+	done     chan struct{}
+	mu       sync.Mutex
+	services []state.Service
 }
 
 func newFakeStateSource() *fakeStateSource {
@@ -58,6 +60,12 @@ func newFakeStateSource() *fakeStateSource {
 	}
 }
 
+func (f *fakeStateSource) All() []state.Service {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.services
+}
+
 func (f *fakeStateSource) Subscribe() <-chan state.Event { return f.ch }
 func (f *fakeStateSource) Unsubscribe(_ <-chan state.Event) {
 	close(f.done)
@@ -165,6 +173,199 @@ func TestEngine_NoTransitionNoNotification(t *testing.T) {
 	}
 }
 
+func TestEngine_FlappingSuppressesNotification(t *testing.T) {
+	src := newFakeStateSource()
+	adapter := newFakeAdapter("hook")
+	adapters := map[string]Adapter{"hook": adapter}
+
+	now := time.Now()
+	dispatcher := NewRetryDispatcher(WithBaseDelay(0), WithMaxAttempts(1))
+	engine := NewEngine(src, adapters, WithRetryDispatcher(dispatcher))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Run(ctx)
+
+	src.ch <- state.Event{
+		Type: state.EventDiscovered,
+		Service: state.Service{
+			Name: "api", Namespace: "default",
+			CompositeStatus: state.StatusHealthy,
+			Status:          state.StatusHealthy,
+			LastChecked:     &now,
+		},
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// Transition while flapping
+	src.ch <- state.Event{
+		Type: state.EventUpdated,
+		Service: state.Service{
+			Name: "api", Namespace: "default",
+			CompositeStatus: state.StatusUnhealthy,
+			Status:          state.StatusUnhealthy,
+			Flapping:        true,
+			LastChecked:     &now,
+		},
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-src.done
+
+	if len(adapter.sentNotifications()) != 0 {
+		t.Fatalf("expected no notifications while flapping, got %d", len(adapter.sentNotifications()))
+	}
+}
+
+func TestEngine_FlappingReleasesHeldNotification(t *testing.T) {
+	src := newFakeStateSource()
+	adapter := newFakeAdapter("hook")
+	adapters := map[string]Adapter{"hook": adapter}
+
+	now := time.Now()
+	dispatcher := NewRetryDispatcher(WithBaseDelay(0), WithMaxAttempts(1))
+	engine := NewEngine(src, adapters, WithRetryDispatcher(dispatcher))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Run(ctx)
+
+	svc := func(status state.HealthStatus, flapping bool) state.Service {
+		return state.Service{
+			Name: "api", Namespace: "default",
+			CompositeStatus: status,
+			Status:          status,
+			Flapping:        flapping,
+			LastChecked:     &now,
+		}
+	}
+	src.ch <- state.Event{Type: state.EventDiscovered, Service: svc(state.StatusHealthy, false)}
+	src.ch <- state.Event{Type: state.EventUpdated, Service: svc(state.StatusUnhealthy, true)}
+	time.Sleep(50 * time.Millisecond)
+	if n := len(adapter.sentNotifications()); n != 0 {
+		t.Fatalf("expected no notifications while flapping, got %d", n)
+	}
+
+	// Flapping clears with the service still down: the held outage is sent.
+	src.ch <- state.Event{Type: state.EventUpdated, Service: svc(state.StatusUnhealthy, false)}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-src.done
+
+	sent := adapter.sentNotifications()
+	if len(sent) != 1 || sent[0].NewState != state.StatusUnhealthy || sent[0].PrevState != state.StatusHealthy {
+		t.Fatalf("expected the held healthy->unhealthy notification, got %+v", sent)
+	}
+}
+
+func TestEngine_BlockedDependentsSuppressed(t *testing.T) {
+	src := newFakeStateSource()
+	adapter := newFakeAdapter("hook")
+	adapters := map[string]Adapter{"hook": adapter}
+
+	now := time.Now()
+	dispatcher := NewRetryDispatcher(WithBaseDelay(0), WithMaxAttempts(1))
+	engine := NewEngine(src, adapters, WithRetryDispatcher(dispatcher))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Run(ctx)
+
+	svc := func(name string, status state.HealthStatus, blockedBy ...string) state.Service {
+		return state.Service{
+			Name: name, Namespace: "media",
+			CompositeStatus: status,
+			Status:          status,
+			BlockedBy:       blockedBy,
+			LastChecked:     &now,
+		}
+	}
+	send := func(evtType state.EventType, s state.Service) {
+		src.ch <- state.Event{Type: evtType, Service: s}
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	send(state.EventDiscovered, svc("truenas", state.StatusHealthy))
+	send(state.EventDiscovered, svc("jellyfin", state.StatusHealthy))
+	send(state.EventDiscovered, svc("sonarr", state.StatusHealthy))
+
+	// The root cause notifies; its dependents do not.
+	send(state.EventUpdated, svc("truenas", state.StatusUnhealthy))
+	send(state.EventUpdated, svc("jellyfin", state.StatusUnhealthy, "media/truenas"))
+	send(state.EventUpdated, svc("sonarr", state.StatusUnhealthy, "media/truenas"))
+	if got := len(adapter.sentNotifications()); got != 1 {
+		t.Fatalf("expected 1 root-cause notification, got %d", got)
+	}
+
+	// The root recovers: jellyfin recovers quietly, while sonarr is still
+	// failing on its own and now notifies.
+	send(state.EventUpdated, svc("truenas", state.StatusHealthy))
+	send(state.EventUpdated, svc("jellyfin", state.StatusHealthy))
+	send(state.EventUpdated, svc("sonarr", state.StatusUnhealthy))
+	cancel()
+	<-src.done
+
+	sent := adapter.sentNotifications()
+	if len(sent) != 2 {
+		t.Fatalf("expected root-cause and sonarr notifications, got %d", len(sent))
+	}
+	if sent[0].ServiceName != "truenas" || sent[1].ServiceName != "sonarr" || sent[1].PrevState != state.StatusHealthy {
+		t.Errorf("unexpected notifications: %+v", sent)
+	}
+}
+
+func TestEngine_MaintenanceSuppressed(t *testing.T) {
+	src := newFakeStateSource()
+	adapter := newFakeAdapter("hook")
+	adapters := map[string]Adapter{"hook": adapter}
+
+	now := time.Now()
+	dispatcher := NewRetryDispatcher(WithBaseDelay(0), WithMaxAttempts(1))
+	engine := NewEngine(src, adapters, WithRetryDispatcher(dispatcher))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Run(ctx)
+
+	svc := func(name string, status state.HealthStatus, maintenance bool) state.Service {
+		return state.Service{
+			Name: name, Namespace: "media",
+			CompositeStatus: status,
+			Status:          status,
+			Maintenance:     maintenance,
+			LastChecked:     &now,
+		}
+	}
+	send := func(evtType state.EventType, s state.Service) {
+		src.ch <- state.Event{Type: evtType, Service: s}
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	send(state.EventDiscovered, svc("jellyfin", state.StatusHealthy, false))
+	send(state.EventDiscovered, svc("sonarr", state.StatusHealthy, false))
+
+	// Outages and recoveries inside the window are expected.
+	send(state.EventUpdated, svc("jellyfin", state.StatusHealthy, true))
+	send(state.EventUpdated, svc("sonarr", state.StatusHealthy, true))
+	send(state.EventUpdated, svc("jellyfin", state.StatusUnhealthy, true))
+	send(state.EventUpdated, svc("sonarr", state.StatusUnhealthy, true))
+	send(state.EventUpdated, svc("jellyfin", state.StatusHealthy, true))
+	if got := len(adapter.sentNotifications()); got != 0 {
+		t.Fatalf("expected no notifications during maintenance, got %d", got)
+	}
+
+	// The window ends: jellyfin is back and stays quiet, while sonarr is
+	// still down and now notifies.
+	send(state.EventUpdated, svc("jellyfin", state.StatusHealthy, false))
+	send(state.EventUpdated, svc("sonarr", state.StatusUnhealthy, false))
+	cancel()
+	<-src.done
+
+	sent := adapter.sentNotifications()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 notification after maintenance, got %d", len(sent))
+	}
+	if sent[0].ServiceName != "sonarr" || sent[0].PrevState != state.StatusHealthy || sent[0].NewState != state.StatusUnhealthy {
+		t.Errorf("unexpected notification: %+v", sent[0])
+	}
+}
+
 func TestEngine_DiscoveredDoesNotNotify(t *testing.T) {
 	src := newFakeStateSource()
 	adapter := newFakeAdapter("hook")
@@ -496,6 +697,33 @@ func TestBuildNotification_EndpointSignals(t *testing.T) {
 	}
 }
 
+func TestBuildNotification_LatencySignal(t *testing.T) {
+	now := time.Now()
+	errSnip := "latency: p95=9200ms above 8000ms"
+
+	svc := state.Service{
+		Name:            "nextcloud",
+		Namespace:       "apps",
+		Status:          state.StatusUnhealthy,
+		CompositeStatus: state.StatusUnhealthy,
+		LatencyBreach:   "p95=9200ms",
+		ErrorSnippet:    &errSnip,
+		LastChecked:     &now,
+	}
+
+	n := buildNotification(svc, state.StatusHealthy)
+
+	expected := []string{"http:unhealthy", "latency:p95=9200ms", "error:" + errSnip}
+	if len(n.Signals) != len(expected) {
+		t.Fatalf("expected %d signals, got %d: %v", len(expected), len(n.Signals), n.Signals)
+	}
+	for i, sig := range expected {
+		if n.Signals[i] != sig {
+			t.Errorf("signal[%d]: expected %q, got %q", i, sig, n.Signals[i])
+		}
+	}
+}
+
 func TestBuildNotification_PodDiagnostics(t *testing.T) {
 	now := time.Now()
 	reason := "CrashLoopBackOff"
@@ -744,3 +972,85 @@ func TestEngine_DegradedWithSignals(t *testing.T) {
 		t.Errorf("expected endpoint signal, got %v", sent[0].Signals)
 	}
 }
+
+func TestEngine_SeedsPrevStateFromSource(t *testing.T) {
+	now := time.Now()
+	src := newFakeStateSource()
+	// Restored from a snapshot before the engine started: no discovered events.
+	src.services = []state.Service{
+		{Name: "api", Namespace: "default", CompositeStatus: state.StatusHealthy, Stale: true},
+		{Name: "db", Namespace: "default", CompositeStatus: state.StatusUnhealthy, Stale: true},
+	}
+	adapter := newFakeAdapter("hook")
+	dispatcher := NewRetryDispatcher(WithBaseDelay(0), WithMaxAttempts(1))
+	engine := NewEngine(src, map[string]Adapter{"hook": adapter}, WithRetryDispatcher(dispatcher))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Run(ctx)
+
+	// api went down; db is still down, which was already known before the restart.
+	for _, name := range []string{"api", "db"} {
+		src.ch <- state.Event{
+			Type:    state.EventUpdated,
+			Service: state.Service{Name: name, Namespace: "default", CompositeStatus: state.StatusUnhealthy, LastChecked: &now},
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-src.done
+
+	sent := adapter.sentNotifications()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(sent))
+	}
+	if sent[0].ServiceName != "api" || sent[0].PrevState != state.StatusHealthy {
+		t.Errorf("expected api healthy -> unhealthy, got %s %v -> %v", sent[0].ServiceName, sent[0].PrevState, sent[0].NewState)
+	}
+}
+
+func TestEngine_ResyncNotifiesNetChanges(t *testing.T) {
+	now := time.Now()
+	src := newFakeStateSource()
+	adapter := newFakeAdapter("hook")
+	dispatcher := NewRetryDispatcher(WithBaseDelay(0), WithMaxAttempts(1))
+	engine := NewEngine(src, map[string]Adapter{"hook": adapter}, WithRetryDispatcher(dispatcher))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Run(ctx)
+
+	for _, name := range []string{"api", "web", "gone"} {
+		src.ch <- state.Event{
+			Type:    state.EventDiscovered,
+			Service: state.Service{Name: name, Namespace: "default", CompositeStatus: state.StatusHealthy, LastChecked: &now},
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// The engine missed api going down, "gone" being removed, and "new" appearing.
+	src.mu.Lock()
+	src.services = []state.Service{
+		{Name: "api", Namespace: "default", CompositeStatus: state.StatusUnhealthy, LastChecked: &now},
+		{Name: "web", Namespace: "default", CompositeStatus: state.StatusHealthy, LastChecked: &now},
+		{Name: "new", Namespace: "default", CompositeStatus: state.StatusUnhealthy, LastChecked: &now},
+	}
+	src.mu.Unlock()
+	src.ch <- state.Event{Type: state.EventResync}
+	time.Sleep(50 * time.Millisecond)
+
+	// "gone" is forgotten: reappearing unhealthy is a discovery, not a transition.
+	src.ch <- state.Event{
+		Type:    state.EventUpdated,
+		Service: state.Service{Name: "gone", Namespace: "default", CompositeStatus: state.StatusUnhealthy, LastChecked: &now},
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-src.done
+
+	sent := adapter.sentNotifications()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 notification, got %d: %+v", len(sent), sent)
+	}
+	if sent[0].ServiceName != "api" || sent[0].PrevState != state.StatusHealthy || sent[0].NewState != state.StatusUnhealthy {
+		t.Errorf("expected api healthy -> unhealthy, got %s %v -> %v", sent[0].ServiceName, sent[0].PrevState, sent[0].NewState)
+	}
+}
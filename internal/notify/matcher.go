@@ -2,20 +2,36 @@ package notify
 
 import (
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/rathix/command-center/internal/config"
 	"github.com/rathix/command-center/internal/state"
+
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // RuleMatcher evaluates notification rules against service transitions.
 type RuleMatcher struct {
-	rules []config.NotificationRule
+	rules     []config.NotificationRule
+	selectors []labels.Selector // parsed rule selectors; nil for rules without one
 }
 
-// NewRuleMatcher creates a rule matcher from notification rules.
+// NewRuleMatcher creates a rule matcher from notification rules. A rule
+// whose selector does not parse matches no services.
 func NewRuleMatcher(rules []config.NotificationRule) *RuleMatcher {
-	return &RuleMatcher{rules: rules}
+	selectors := make([]labels.Selector, len(rules))
+	for i, rule := range rules {
+		if rule.Selector == "" {
+			continue
+		}
+		sel, err := labels.Parse(rule.Selector)
+		if err != nil {
+			sel = labels.Nothing()
+		}
+		selectors[i] = sel
+	}
+	return &RuleMatcher{rules: rules, selectors: selectors}
 }
 
 // Rules returns the configured rules.
@@ -25,12 +41,12 @@ func (m *RuleMatcher) Rules() []config.NotificationRule {
 
 // Match returns deduplicated adapter names that should receive a notification
 // for the given service transition.
-func (m *RuleMatcher) Match(serviceKey string, newState state.HealthStatus) []string {
+func (m *RuleMatcher) Match(svc state.Service, newState state.HealthStatus) []string {
 	seen := make(map[string]struct{})
 	var result []string
 
-	for _, rule := range m.rules {
-		if !ruleMatchesEvent(rule, serviceKey, newState) {
+	for i, rule := range m.rules {
+		if !m.matches(i, svc, newState) {
 			continue
 		}
 		for _, ch := range rule.Channels {
@@ -43,17 +59,35 @@ func (m *RuleMatcher) Match(serviceKey string, newState state.HealthStatus) []st
 	return result
 }
 
-// ruleMatchesEvent checks if a rule matches a given service key and new state.
-func ruleMatchesEvent(rule config.NotificationRule, svcKey string, newState state.HealthStatus) bool {
+// matches checks if rule i matches a given service and new state.
+func (m *RuleMatcher) matches(i int, svc state.Service, newState state.HealthStatus) bool {
+	rule, selector := m.rules[i], m.selectors[i]
+	if len(rule.Services) == 0 && selector == nil {
+		return false
+	}
+
 	// Check service pattern match
-	matched := false
-	for _, pattern := range rule.Services {
-		if matchGlob(pattern, svcKey) {
-			matched = true
-			break
+	if len(rule.Services) > 0 {
+		svcKey, scopedKey := svc.Namespace+"/"+svc.Name, svc.Key()
+		matched := false
+		for _, pattern := range rule.Services {
+			if matchGlob(pattern, svcKey) || (scopedKey != svcKey && matchGlob(pattern, scopedKey)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
 		}
 	}
-	if !matched {
+
+	// Check cluster filter
+	if len(rule.Clusters) > 0 && !slices.Contains(rule.Clusters, svc.Cluster) {
+		return false
+	}
+
+	// Check label selector
+	if selector != nil && !selector.Matches(labels.Set(svc.Labels)) {
 		return false
 	}
 
@@ -70,10 +104,10 @@ func ruleMatchesEvent(rule config.NotificationRule, svcKey string, newState stat
 }
 
 // matchGlob checks if a service key matches a glob pattern.
-// The special pattern "*" is expanded to "*/*" for convenience.
+// The special pattern "*" matches every key.
 func matchGlob(pattern, serviceKey string) bool {
 	if pattern == "*" {
-		pattern = "*/*"
+		return true
 	}
 	matched, err := path.Match(pattern, serviceKey)
 	if err != nil {
@@ -1,6 +1,8 @@
 package notify
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/rathix/command-center/internal/config"
@@ -17,7 +19,7 @@ func TestRuleMatcher_WildcardMatchesAll(t *testing.T) {
 	}
 	m := NewRuleMatcher(rules)
 
-	result := m.Match("default/myservice", state.StatusUnhealthy)
+	result := m.Match(serviceFromKey("default/myservice"), state.StatusUnhealthy)
 	if len(result) != 1 || result[0] != "webhook" {
 		t.Errorf("expected [webhook], got %v", result)
 	}
@@ -34,13 +36,13 @@ func TestRuleMatcher_NamespaceGlob(t *testing.T) {
 	m := NewRuleMatcher(rules)
 
 	// Should match
-	result := m.Match("default/api", state.StatusUnhealthy)
+	result := m.Match(serviceFromKey("default/api"), state.StatusUnhealthy)
 	if len(result) != 1 {
 		t.Errorf("expected match for default/api, got %v", result)
 	}
 
 	// Should not match
-	result = m.Match("kube-system/dns", state.StatusUnhealthy)
+	result = m.Match(serviceFromKey("kube-system/dns"), state.StatusUnhealthy)
 	if len(result) != 0 {
 		t.Errorf("expected no match for kube-system/dns, got %v", result)
 	}
@@ -57,13 +59,13 @@ func TestRuleMatcher_TransitionFilter(t *testing.T) {
 	m := NewRuleMatcher(rules)
 
 	// Should match unhealthy
-	result := m.Match("default/api", state.StatusUnhealthy)
+	result := m.Match(serviceFromKey("default/api"), state.StatusUnhealthy)
 	if len(result) != 1 {
 		t.Errorf("expected match for unhealthy, got %v", result)
 	}
 
 	// Should not match degraded
-	result = m.Match("default/api", state.StatusDegraded)
+	result = m.Match(serviceFromKey("default/api"), state.StatusDegraded)
 	if len(result) != 0 {
 		t.Errorf("expected no match for degraded, got %v", result)
 	}
@@ -79,7 +81,7 @@ func TestRuleMatcher_EmptyTransitionsMatchesAll(t *testing.T) {
 	m := NewRuleMatcher(rules)
 
 	for _, status := range []state.HealthStatus{state.StatusHealthy, state.StatusDegraded, state.StatusUnhealthy} {
-		result := m.Match("default/api", status)
+		result := m.Match(serviceFromKey("default/api"), status)
 		if len(result) != 1 {
 			t.Errorf("expected match for %v with empty transitions, got %v", status, result)
 		}
@@ -101,7 +103,7 @@ func TestRuleMatcher_MultipleRulesDedup(t *testing.T) {
 	}
 	m := NewRuleMatcher(rules)
 
-	result := m.Match("default/api", state.StatusUnhealthy)
+	result := m.Match(serviceFromKey("default/api"), state.StatusUnhealthy)
 	if len(result) != 1 {
 		t.Errorf("expected deduped to 1, got %v", result)
 	}
@@ -122,7 +124,7 @@ func TestRuleMatcher_MultipleRulesIndependent(t *testing.T) {
 	}
 	m := NewRuleMatcher(rules)
 
-	result := m.Match("default/api", state.StatusUnhealthy)
+	result := m.Match(serviceFromKey("default/api"), state.StatusUnhealthy)
 	if len(result) != 2 {
 		t.Errorf("expected 2 channels, got %v", result)
 	}
@@ -146,7 +148,7 @@ func TestRuleMatcher_NoMatch(t *testing.T) {
 	}
 	m := NewRuleMatcher(rules)
 
-	result := m.Match("default/api", state.StatusUnhealthy)
+	result := m.Match(serviceFromKey("default/api"), state.StatusUnhealthy)
 	if len(result) != 0 {
 		t.Errorf("expected no match, got %v", result)
 	}
@@ -165,6 +167,10 @@ func TestRuleMatcher_ComplexGlob(t *testing.T) {
 		{"any namespace frontend no match", "*/frontend", "prod/backend", false},
 		{"exact match", "default/api-gateway", "default/api-gateway", true},
 		{"exact no match", "default/api-gateway", "default/other", false},
+		{"namespace/name in any cluster", "media/*", "lab/media/jellyfin", true},
+		{"cluster key", "lab/media/*", "lab/media/jellyfin", true},
+		{"cluster key other cluster", "lab/media/*", "prod/media/jellyfin", false},
+		{"star matches cluster services", "*", "lab/media/jellyfin", true},
 	}
 
 	for _, tt := range tests {
@@ -176,7 +182,7 @@ func TestRuleMatcher_ComplexGlob(t *testing.T) {
 				},
 			}
 			m := NewRuleMatcher(rules)
-			result := m.Match(tt.serviceKey, state.StatusUnhealthy)
+			result := m.Match(serviceFromKey(tt.serviceKey), state.StatusUnhealthy)
 			got := len(result) > 0
 			if got != tt.want {
 				t.Errorf("pattern %q vs %q: got %v, want %v", tt.pattern, tt.serviceKey, got, tt.want)
@@ -185,6 +191,45 @@ func TestRuleMatcher_ComplexGlob(t *testing.T) {
 	}
 }
 
+func TestRuleMatcher_Clusters(t *testing.T) {
+	m := NewRuleMatcher([]config.NotificationRule{
+		{Services: []string{"*"}, Clusters: []string{"prod"}, Channels: []string{"pager"}},
+		{Services: []string{"*"}, Channels: []string{"chat"}},
+	})
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"prod/media/jellyfin", []string{"pager", "chat"}},
+		{"lab/media/jellyfin", []string{"chat"}},
+		{"media/jellyfin", []string{"chat"}},
+	}
+	for _, tt := range tests {
+		if got := m.Match(serviceFromKey(tt.key), state.StatusUnhealthy); !slices.Equal(got, tt.want) {
+			t.Errorf("Match(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRuleMatcher_SiteServices(t *testing.T) {
+	svc := state.Service{Site: "east", Namespace: "media", Name: "jellyfin"}
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"media/jellyfin", true},
+		{"east/media/*", true},
+		{"west/media/*", false},
+		{"*", true},
+	}
+	for _, tt := range tests {
+		m := NewRuleMatcher([]config.NotificationRule{{Services: []string{tt.pattern}, Channels: []string{"hook"}}})
+		if got := len(m.Match(svc, state.StatusUnhealthy)) > 0; got != tt.want {
+			t.Errorf("pattern %q: got %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
 func TestRuleMatcher_RecoveryTransition(t *testing.T) {
 	rules := []config.NotificationRule{
 		{
@@ -195,8 +240,45 @@ func TestRuleMatcher_RecoveryTransition(t *testing.T) {
 	}
 	m := NewRuleMatcher(rules)
 
-	result := m.Match("default/api", state.StatusHealthy)
+	result := m.Match(serviceFromKey("default/api"), state.StatusHealthy)
 	if len(result) != 1 {
 		t.Errorf("expected match for recovery, got %v", result)
 	}
 }
+
+func TestRuleMatcher_Selector(t *testing.T) {
+	critical := state.Service{Namespace: "default", Name: "api", Labels: map[string]string{"tier": "critical", "owner": "alice"}}
+	other := state.Service{Namespace: "default", Name: "web", Labels: map[string]string{"tier": "batch"}}
+	unlabeled := state.Service{Namespace: "prod", Name: "db"}
+
+	tests := []struct {
+		name string
+		rule config.NotificationRule
+		want []bool // critical, other, unlabeled
+	}{
+		{"equality", config.NotificationRule{Selector: "tier=critical"}, []bool{true, false, false}},
+		{"set based", config.NotificationRule{Selector: "tier in (critical,batch)"}, []bool{true, true, false}},
+		{"not exists", config.NotificationRule{Selector: "!tier"}, []bool{false, false, true}},
+		{"with services", config.NotificationRule{Services: []string{"default/*"}, Selector: "tier!=critical"}, []bool{false, true, false}},
+		{"invalid selector matches nothing", config.NotificationRule{Services: []string{"*"}, Selector: "tier in (a"}, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Channels = []string{"webhook"}
+			m := NewRuleMatcher([]config.NotificationRule{tt.rule})
+			for i, svc := range []state.Service{critical, other, unlabeled} {
+				if got := len(m.Match(svc, state.StatusUnhealthy)) > 0; got != tt.want[i] {
+					t.Errorf("%s/%s: matched = %v, want %v", svc.Namespace, svc.Name, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func serviceFromKey(key string) state.Service {
+	if parts := strings.Split(key, "/"); len(parts) == 3 {
+		return state.Service{Cluster: parts[0], Namespace: parts[1], Name: parts[2]}
+	}
+	namespace, name, _ := strings.Cut(key, "/")
+	return state.Service{Namespace: namespace, Name: name}
+}
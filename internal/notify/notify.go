@@ -18,6 +18,8 @@ type Adapter interface {
 type Notification struct {
 	ServiceName string             `json:"serviceName"`
 	Namespace   string             `json:"namespace"`
+	Cluster     string             `json:"cluster,omitempty"`
+	Site        string             `json:"site,omitempty"`
 	PrevState   state.HealthStatus `json:"prevState"`
 	NewState    state.HealthStatus `json:"newState"`
 	Timestamp   time.Time          `json:"timestamp"`
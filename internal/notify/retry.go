@@ -3,18 +3,30 @@ package notify
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 )
 
 // RetryOption configures the RetryDispatcher.
 type RetryOption func(*RetryDispatcher)
 
+// DeliveryStats counts the final outcome of notifications sent to one
+// adapter. A notification that exhausts its retries or is dropped counts as
+// failed.
+type DeliveryStats struct {
+	Succeeded uint64
+	Failed    uint64
+}
+
 // RetryDispatcher wraps adapter dispatch with exponential backoff retry.
 type RetryDispatcher struct {
 	maxAttempts   int
 	baseDelay     time.Duration
 	sem           chan struct{}
 	logger        *slog.Logger
+
+	mu    sync.Mutex
+	stats map[string]DeliveryStats
 }
 
 // NewRetryDispatcher creates a retry dispatcher with default settings.
@@ -24,6 +36,7 @@ func NewRetryDispatcher(opts ...RetryOption) *RetryDispatcher {
 		baseDelay:   1 * time.Second,
 		sem:         make(chan struct{}, 32),
 		logger:      slog.Default(),
+		stats:       make(map[string]DeliveryStats),
 	}
 	for _, opt := range opts {
 		opt(d)
@@ -74,13 +87,38 @@ func (d *RetryDispatcher) Dispatch(ctx context.Context, adapter Adapter, n Notif
 			"adapter", adapter.Name(),
 			"service", n.ServiceName,
 		)
+		d.record(adapter.Name(), false)
+	}
+}
+
+// Stats returns delivery outcomes per adapter name.
+func (d *RetryDispatcher) Stats() map[string]DeliveryStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make(map[string]DeliveryStats, len(d.stats))
+	for name, s := range d.stats {
+		out[name] = s
+	}
+	return out
+}
+
+func (d *RetryDispatcher) record(adapter string, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := d.stats[adapter]
+	if ok {
+		s.Succeeded++
+	} else {
+		s.Failed++
 	}
+	d.stats[adapter] = s
 }
 
 func (d *RetryDispatcher) dispatch(ctx context.Context, adapter Adapter, n Notification) {
 	for attempt := 0; attempt < d.maxAttempts; attempt++ {
 		err := adapter.Send(ctx, n)
 		if err == nil {
+			d.record(adapter.Name(), true)
 			return
 		}
 		d.logger.Warn("notification delivery failed",
@@ -93,6 +131,7 @@ func (d *RetryDispatcher) dispatch(ctx context.Context, adapter Adapter, n Notif
 			delay := d.baseDelay * time.Duration(1<<uint(attempt))
 			select {
 			case <-ctx.Done():
+				d.record(adapter.Name(), false)
 				return
 			case <-time.After(delay):
 			}
@@ -103,4 +142,5 @@ func (d *RetryDispatcher) dispatch(ctx context.Context, adapter Adapter, n Notif
 		"service", n.ServiceName,
 		"attempts", d.maxAttempts,
 	)
+	d.record(adapter.Name(), false)
 }
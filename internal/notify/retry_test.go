@@ -135,3 +135,27 @@ func TestRetryDispatcher_SemaphoreBackpressure(t *testing.T) {
 		t.Errorf("expected 2 successful sends (third dropped), got %d", len(sent))
 	}
 }
+
+func TestRetryDispatcher_Stats(t *testing.T) {
+	ok := newFakeAdapter("ok")
+	failing := newFakeAdapter("failing")
+	failing.errFn = func() error { return fmt.Errorf("permanent error") }
+
+	d := NewRetryDispatcher(WithBaseDelay(1*time.Millisecond), WithMaxAttempts(2))
+
+	ctx := context.Background()
+	d.Dispatch(ctx, ok, Notification{ServiceName: "api"})
+	d.Dispatch(ctx, ok, Notification{ServiceName: "web"})
+	d.Dispatch(ctx, failing, Notification{ServiceName: "api"})
+
+	time.Sleep(100 * time.Millisecond)
+
+	stats := d.Stats()
+	if got := stats["ok"]; got.Succeeded != 2 || got.Failed != 0 {
+		t.Errorf("ok stats = %+v, want 2 succeeded", got)
+	}
+	// Retries count once, as a single failed delivery.
+	if got := stats["failing"]; got.Succeeded != 0 || got.Failed != 1 {
+		t.Errorf("failing stats = %+v, want 1 failed", got)
+	}
+}
@@ -38,10 +38,15 @@ type ReminderAction struct {
 }
 
 // ServiceRuleState tracks suppression/escalation state for one (service, rule) pair.
+// Site, Cluster, Namespace and Name identify the service for reminders.
 type ServiceRuleState struct {
 	LastNotifiedAt time.Time
 	UnhealthySince time.Time
 	Escalated      bool
+	Site           string
+	Cluster        string
+	Namespace      string
+	Name           string
 }
 
 // SuppressionOption configures the SuppressionEngine.
@@ -86,9 +91,9 @@ func stateKey(serviceKey string, ruleIdx int) string {
 	return fmt.Sprintf("%s:%d", serviceKey, ruleIdx)
 }
 
-// Evaluate decides whether a notification should be sent, suppressed, or escalated.
+// Evaluate decides whether a notification for svc should be sent, suppressed, or escalated.
 func (se *SuppressionEngine) Evaluate(
-	serviceKey string,
+	svc state.Service,
 	ruleIdx int,
 	rule config.NotificationRule,
 	newState state.HealthStatus,
@@ -97,7 +102,7 @@ func (se *SuppressionEngine) Evaluate(
 	defer se.mu.Unlock()
 
 	now := se.clock()
-	key := stateKey(serviceKey, ruleIdx)
+	key := stateKey(svc.Key(), ruleIdx)
 
 	// Recovery always allowed; state is reset separately
 	if newState == state.StatusHealthy {
@@ -114,6 +119,10 @@ func (se *SuppressionEngine) Evaluate(
 		se.states[key] = &ServiceRuleState{
 			LastNotifiedAt: now,
 			UnhealthySince: now,
+			Site:           svc.Site,
+			Cluster:        svc.Cluster,
+			Namespace:      svc.Namespace,
+			Name:           svc.Name,
 		}
 		return Decision{Action: Allow, Channels: rule.Channels}
 	}
@@ -206,19 +215,15 @@ func (se *SuppressionEngine) CheckReminders(
 				channels = append(append([]string{}, rule.Channels...), rule.EscalationChannels...)
 			}
 
-			nsParts := strings.SplitN(serviceKey, "/", 2)
-			ns, name := "", serviceKey
-			if len(nsParts) == 2 {
-				ns, name = nsParts[0], nsParts[1]
-			}
-
 			reminders = append(reminders, ReminderAction{
 				ServiceKey: serviceKey,
 				RuleIdx:    ruleIdx,
 				Channels:   channels,
 				Notification: Notification{
-					ServiceName: name,
-					Namespace:   ns,
+					ServiceName: st.Name,
+					Namespace:   st.Namespace,
+					Cluster:     st.Cluster,
+					Site:        st.Site,
 					PrevState:   currentStatus,
 					NewState:    currentStatus,
 					Timestamp:   now,
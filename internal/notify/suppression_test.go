@@ -8,6 +8,8 @@ import (
 	"github.com/rathix/command-center/internal/state"
 )
 
+var apiService = state.Service{Name: "api", Namespace: "default"}
+
 func TestSuppression_FirstNotificationAllowed(t *testing.T) {
 	now := time.Now()
 	se := NewSuppressionEngine(WithClock(func() time.Time { return now }))
@@ -17,7 +19,7 @@ func TestSuppression_FirstNotificationAllowed(t *testing.T) {
 		Channels:            []string{"webhook"},
 	}
 
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Allow {
 		t.Errorf("first notification should be allowed, got %v", d.Action)
 	}
@@ -33,14 +35,14 @@ func TestSuppression_WithinIntervalSuppressed(t *testing.T) {
 	}
 
 	// First: allowed
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Allow {
 		t.Fatalf("first should be allowed")
 	}
 
 	// Second within 15m: suppressed
 	now = now.Add(5 * time.Minute)
-	d = se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d = se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Suppress {
 		t.Errorf("second within interval should be suppressed, got %v", d.Action)
 	}
@@ -56,11 +58,11 @@ func TestSuppression_AfterIntervalAllowed(t *testing.T) {
 	}
 
 	// First
-	se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 
 	// After 16m: allowed
 	now = now.Add(16 * time.Minute)
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Allow {
 		t.Errorf("after interval should be allowed, got %v", d.Action)
 	}
@@ -76,18 +78,18 @@ func TestSuppression_MinimumGranularity(t *testing.T) {
 	}
 
 	// First: allowed
-	se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 
 	// After 45s: should still be suppressed (clamped to 1m)
 	now = now.Add(45 * time.Second)
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Suppress {
 		t.Errorf("should be suppressed at 45s (clamped to 1m minimum), got %v", d.Action)
 	}
 
 	// After 1m+: allowed
 	now = now.Add(20 * time.Second) // total 65s
-	d = se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d = se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Allow {
 		t.Errorf("should be allowed after 1m minimum, got %v", d.Action)
 	}
@@ -105,11 +107,11 @@ func TestSuppression_EscalationAfterDuration(t *testing.T) {
 	}
 
 	// First: allowed
-	se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 
 	// After 31m: escalate
 	now = now.Add(31 * time.Minute)
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Escalate {
 		t.Errorf("expected escalation after 30m, got %v", d.Action)
 	}
@@ -135,18 +137,18 @@ func TestSuppression_EscalationOnlyOnce(t *testing.T) {
 	}
 
 	// First
-	se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 
 	// After 31m: escalate
 	now = now.Add(31 * time.Minute)
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Escalate {
 		t.Fatalf("expected first escalation")
 	}
 
 	// After another 16m: regular allow, not escalate again
 	now = now.Add(16 * time.Minute)
-	d = se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d = se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action == Escalate {
 		t.Errorf("escalation should fire only once, got %v", d.Action)
 	}
@@ -162,14 +164,14 @@ func TestSuppression_RecoveryResetsTimers(t *testing.T) {
 	}
 
 	// First unhealthy
-	se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 
 	// Recovery resets
 	se.Reset("default/api")
 
 	// Next unhealthy should be treated as first
 	now = now.Add(1 * time.Minute)
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Allow {
 		t.Errorf("after reset, should be allowed, got %v", d.Action)
 	}
@@ -184,7 +186,7 @@ func TestSuppression_RecoveryNotification(t *testing.T) {
 	}
 
 	// Recovery is always allowed
-	d := se.Evaluate("default/api", 0, rule, state.StatusHealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusHealthy)
 	if d.Action != Allow {
 		t.Errorf("recovery should always be allowed, got %v", d.Action)
 	}
@@ -204,7 +206,7 @@ func TestSuppression_CheckReminders(t *testing.T) {
 	}
 
 	// Initial evaluation
-	se.Evaluate("default/api", 0, rules[0], state.StatusUnhealthy)
+	se.Evaluate(apiService, 0, rules[0], state.StatusUnhealthy)
 
 	currentStates := map[string]state.HealthStatus{
 		"default/api": state.StatusUnhealthy,
@@ -228,6 +230,30 @@ func TestSuppression_CheckReminders(t *testing.T) {
 	}
 }
 
+func TestSuppression_ReminderCarriesServiceIdentity(t *testing.T) {
+	now := time.Now()
+	se := NewSuppressionEngine(WithClock(func() time.Time { return now }))
+
+	rules := []config.NotificationRule{
+		{Channels: []string{"webhook"}, SuppressionInterval: "15m"},
+	}
+	svc := state.Service{Site: "lab", Cluster: "edge", Namespace: "media", Name: "jellyfin"}
+	se.Evaluate(svc, 0, rules[0], state.StatusUnhealthy)
+
+	now = now.Add(16 * time.Minute)
+	reminders := se.CheckReminders(rules, map[string]state.HealthStatus{svc.Key(): state.StatusUnhealthy})
+	if len(reminders) != 1 {
+		t.Fatalf("expected 1 reminder, got %d", len(reminders))
+	}
+	n := reminders[0].Notification
+	if n.Site != "lab" || n.Cluster != "edge" || n.Namespace != "media" || n.ServiceName != "jellyfin" {
+		t.Errorf("unexpected reminder identity %+v", n)
+	}
+	if reminders[0].ServiceKey != "lab/edge/media/jellyfin" {
+		t.Errorf("expected lab/edge/media/jellyfin, got %s", reminders[0].ServiceKey)
+	}
+}
+
 func TestSuppression_NoSuppressionIfNotConfigured(t *testing.T) {
 	now := time.Now()
 	se := NewSuppressionEngine(WithClock(func() time.Time { return now }))
@@ -238,13 +264,13 @@ func TestSuppression_NoSuppressionIfNotConfigured(t *testing.T) {
 	}
 
 	// First
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Allow {
 		t.Errorf("first should be allowed")
 	}
 
 	// Immediate second with no suppression: also allowed
-	d = se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d = se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action != Allow {
 		t.Errorf("without suppression, all events should be allowed, got %v", d.Action)
 	}
@@ -261,11 +287,11 @@ func TestSuppression_NoEscalationIfNotConfigured(t *testing.T) {
 	}
 
 	// First
-	se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 
 	// After 1h: still regular allow, no escalation
 	now = now.Add(1 * time.Hour)
-	d := se.Evaluate("default/api", 0, rule, state.StatusUnhealthy)
+	d := se.Evaluate(apiService, 0, rule, state.StatusUnhealthy)
 	if d.Action == Escalate {
 		t.Errorf("without escalateAfter, should not escalate")
 	}
@@ -0,0 +1,98 @@
+package serviceapi
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+// ServiceReader provides read access to the service store.
+type ServiceReader interface {
+	All() []state.Service
+	Get(namespace, name string) (state.Service, bool)
+}
+
+type serviceResponse struct {
+	Service any `json:"service"`
+}
+
+type servicesResponse struct {
+	Services []any `json:"services"`
+	Count    int   `json:"count"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns an http.Handler for the service endpoints:
+//
+//	GET /api/services                                      list services
+//	GET /api/services/{namespace}/{name}                   one service
+//	GET /api/services/{cluster}/{namespace}/{name}         one service of a named cluster or a site
+//	GET /api/services/{site}/{cluster}/{namespace}/{name}  one service of a named cluster of a site
+//
+// The list accepts the filters status, compositeStatus, group, namespace,
+// cluster, site, and source (comma-separated or repeated values match any of them), a
+// Kubernetes-style label selector, q for a case-insensitive text search, and
+// sort (comma-separated fields, "-" for descending). Both accept fields to
+// return only some service fields. If logger is nil, a no-op logger is used.
+func NewHandler(reader ServiceReader, logger *slog.Logger) http.Handler {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace, name := r.PathValue("namespace"), r.PathValue("name")
+		if namespace != "" && name != "" {
+			fields, err := parseFields(r.URL.Query())
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+				return
+			}
+			scoped := state.ScopedNamespace(r.PathValue("cluster"), namespace)
+			if site := r.PathValue("site"); site != "" {
+				scoped = site + "/" + scoped
+			}
+			svc, ok := reader.Get(scoped, name)
+			if !ok {
+				writeJSON(w, http.StatusNotFound, errorResponse{Error: "service not found"})
+				return
+			}
+			out, err := selectFields(svc, fields)
+			if err != nil {
+				logger.Error("failed to encode service", "namespace", namespace, "name", name, "error", err)
+				writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to encode service"})
+				return
+			}
+			writeJSON(w, http.StatusOK, serviceResponse{Service: out})
+			return
+		}
+
+		q, err := parseQuery(r.URL.Query())
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		services := q.apply(reader.All())
+		out := make([]any, 0, len(services))
+		for _, svc := range services {
+			v, err := selectFields(svc, q.fields)
+			if err != nil {
+				logger.Error("failed to encode service", "namespace", svc.Namespace, "name", svc.Name, "error", err)
+				writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "failed to encode services"})
+				return
+			}
+			out = append(out, v)
+		}
+		writeJSON(w, http.StatusOK, servicesResponse{Services: out, Count: len(out)})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,225 @@
+package serviceapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rathix/command-center/internal/state"
+)
+
+type fakeReader []state.Service
+
+func (f fakeReader) All() []state.Service { return f }
+
+func (f fakeReader) Get(namespace, name string) (state.Service, bool) {
+	for _, svc := range f {
+		if svc.ScopedNamespace() == namespace && svc.Name == name {
+			return svc, true
+		}
+	}
+	return state.Service{}, false
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func testServices() fakeReader {
+	return fakeReader{
+		{Name: "sonarr", DisplayName: "Sonarr", Namespace: "media", Group: "media", Source: state.SourceKubernetes,
+			Labels: map[string]string{"tier": "standard", "exposure": "public"},
+			URL:    "https://sonarr.home", Status: state.StatusUnhealthy, CompositeStatus: state.StatusUnhealthy, ResponseTimeMs: int64Ptr(900)},
+		{Name: "jellyfin", DisplayName: "Jellyfin", Namespace: "media", Group: "media", Source: state.SourceKubernetes,
+			URL: "https://jellyfin.home", Status: state.StatusHealthy, CompositeStatus: state.StatusDegraded, ResponseTimeMs: int64Ptr(120)},
+		{Name: "truenas", DisplayName: "TrueNAS", Namespace: "custom", Group: "storage", Source: state.SourceConfig,
+			Labels: map[string]string{"tier": "critical"},
+			URL:    "https://nas.home", Status: state.StatusUnknown, CompositeStatus: state.StatusUnknown},
+		{Name: "jellyfin", DisplayName: "Jellyfin", Namespace: "media", Cluster: "lab", Group: "media", Source: state.SourceKubernetes,
+			URL: "https://jellyfin.lab", Status: state.StatusUnhealthy, CompositeStatus: state.StatusUnhealthy},
+		{Name: "jellyfin", DisplayName: "Jellyfin", Namespace: "media", Cluster: "lab", Site: "east", Group: "media", Source: state.SourceKubernetes,
+			URL: "https://jellyfin.lab.east", Status: state.StatusHealthy, CompositeStatus: state.StatusHealthy},
+	}
+}
+
+func newTestMux(reader ServiceReader) *http.ServeMux {
+	h := NewHandler(reader, nil)
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/services", h)
+	mux.Handle("GET /api/services/{namespace}/{name}", h)
+	mux.Handle("GET /api/services/{cluster}/{namespace}/{name}", h)
+	mux.Handle("GET /api/services/{site}/{cluster}/{namespace}/{name}", h)
+	return mux
+}
+
+func TestHandler_List(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string // service names, in order
+	}{
+		{name: "default order by key", query: "", want: []string{"truenas", "jellyfin", "sonarr", "lab/jellyfin", "east/lab/jellyfin"}},
+		{name: "status filter", query: "?status=healthy", want: []string{"jellyfin", "east/lab/jellyfin"}},
+		{name: "status values match any", query: "?status=healthy,unknown", want: []string{"truenas", "jellyfin", "east/lab/jellyfin"}},
+		{name: "repeated values match any", query: "?compositeStatus=degraded&compositeStatus=unhealthy", want: []string{"jellyfin", "sonarr", "lab/jellyfin"}},
+		{name: "filters combine", query: "?group=media&compositeStatus=unhealthy", want: []string{"sonarr", "lab/jellyfin"}},
+		{name: "namespace and source", query: "?namespace=custom&source=config", want: []string{"truenas"}},
+		{name: "cluster filter", query: "?cluster=lab", want: []string{"lab/jellyfin", "east/lab/jellyfin"}},
+		{name: "site filter", query: "?site=east", want: []string{"east/lab/jellyfin"}},
+		{name: "text search", query: "?q=NAS", want: []string{"truenas"}},
+		{name: "text search matches url", query: "?q=jellyfin.home", want: []string{"jellyfin"}},
+		{name: "sort descending", query: "?sort=-name", want: []string{"truenas", "sonarr", "jellyfin", "lab/jellyfin", "east/lab/jellyfin"}},
+		{name: "sort by severity", query: "?sort=-compositeStatus", want: []string{"sonarr", "lab/jellyfin", "jellyfin", "truenas", "east/lab/jellyfin"}},
+		{name: "missing values last", query: "?sort=-responseTimeMs", want: []string{"sonarr", "jellyfin", "truenas", "lab/jellyfin", "east/lab/jellyfin"}},
+		{name: "multiple sort keys", query: "?sort=group,responseTimeMs", want: []string{"jellyfin", "sonarr", "lab/jellyfin", "east/lab/jellyfin", "truenas"}},
+		{name: "label selector", query: "?selector=tier%3Dcritical", want: []string{"truenas"}},
+		{name: "set based selector", query: "?selector=tier+in+(critical,standard),exposure!%3Dpublic", want: []string{"truenas"}},
+		{name: "label exists", query: "?selector=tier", want: []string{"truenas", "sonarr"}},
+		{name: "label does not exist", query: "?selector=!tier", want: []string{"jellyfin", "lab/jellyfin", "east/lab/jellyfin"}},
+		{name: "no matches", query: "?group=none", want: []string{}},
+	}
+	mux := newTestMux(testServices())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+			var resp struct {
+				Services []state.Service `json:"services"`
+				Count    int             `json:"count"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, svc := range resp.Services {
+				name := svc.Name
+				if svc.Cluster != "" {
+					name = svc.Cluster + "/" + name
+				}
+				if svc.Site != "" {
+					name = svc.Site + "/" + name
+				}
+				got = append(got, name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("services = %v, want %v", got, tt.want)
+			}
+			if resp.Count != len(tt.want) {
+				t.Errorf("count = %d, want %d", resp.Count, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestHandler_Fields(t *testing.T) {
+	mux := newTestMux(testServices())
+	for _, path := range []string{
+		"/api/services?namespace=custom&fields=name,status",
+		"/api/services/custom/truenas?fields=name&fields=status",
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body %s", path, rec.Code, rec.Body)
+		}
+		var resp struct {
+			Service  map[string]any   `json:"service"`
+			Services []map[string]any `json:"services"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		svc := resp.Service
+		if svc == nil && len(resp.Services) == 1 {
+			svc = resp.Services[0]
+		}
+		if len(svc) != 2 || svc["name"] != "truenas" || svc["status"] != "unknown" {
+			t.Errorf("%s: got %v, want only name and status", path, svc)
+		}
+	}
+}
+
+func TestHandler_Get(t *testing.T) {
+	mux := newTestMux(testServices())
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services/media/jellyfin", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp struct {
+		Service state.Service `json:"service"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Service.Name != "jellyfin" || resp.Service.CompositeStatus != state.StatusDegraded || resp.Service.URL != "https://jellyfin.home" {
+		t.Errorf("unexpected service: %+v", resp.Service)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services/lab/media/jellyfin", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cluster service status = %d", rec.Code)
+	}
+	resp.Service = state.Service{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Service.Cluster != "lab" || resp.Service.URL != "https://jellyfin.lab" {
+		t.Errorf("unexpected cluster service: %+v", resp.Service)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services/east/lab/media/jellyfin", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("site service status = %d", rec.Code)
+	}
+	resp.Service = state.Service{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Service.Site != "east" || resp.Service.URL != "https://jellyfin.lab.east" {
+		t.Errorf("unexpected site service: %+v", resp.Service)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantError  string
+	}{
+		{"/api/services/media/missing", http.StatusNotFound, "service not found"},
+		{"/api/services/prod/media/jellyfin", http.StatusNotFound, "service not found"},
+		{"/api/services?status=broken", http.StatusBadRequest, `invalid status "broken": must be healthy, degraded, unhealthy, or unknown`},
+		{"/api/services?compositeStatus=up", http.StatusBadRequest, `invalid compositeStatus "up": must be healthy, degraded, unhealthy, or unknown`},
+		{"/api/services?selector=tier+in+(a", http.StatusBadRequest, "invalid selector: "},
+		{"/api/services?sort=-url", http.StatusBadRequest, `invalid sort field "url"`},
+		{"/api/services?fields=name,password", http.StatusBadRequest, `invalid field "password"`},
+		{"/api/services/media/jellyfin?fields=probeSecret", http.StatusBadRequest, `invalid field "probeSecret"`},
+	}
+	mux := newTestMux(testServices())
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(resp.Error, tt.wantError) {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
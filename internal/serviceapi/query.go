@@ -0,0 +1,281 @@
+package serviceapi
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rathix/command-center/internal/state"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// query is a parsed list request. Filters with several values match any of
+// them; different filters must all match.
+type query struct {
+	status          map[state.HealthStatus]struct{}
+	compositeStatus map[state.HealthStatus]struct{}
+	group           map[string]struct{}
+	namespace       map[string]struct{}
+	cluster         map[string]struct{}
+	site            map[string]struct{}
+	source          map[string]struct{}
+	selector        labels.Selector // nil matches every service
+	search          string
+	sort            []sortKey
+	fields          []string
+}
+
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// defaultSort orders services by key.
+var defaultSort = []sortKey{{field: "site"}, {field: "cluster"}, {field: "namespace"}, {field: "name"}}
+
+// statusRank orders statuses for sorting, best first.
+var statusRank = map[state.HealthStatus]int{
+	state.StatusHealthy:   0,
+	state.StatusUnknown:   1,
+	state.StatusDegraded:  2,
+	state.StatusUnhealthy: 3,
+}
+
+// sortField compares services by one field. Services for which missing
+// reports true (no value yet) sort last in either direction.
+type sortField struct {
+	compare func(a, b state.Service) int
+	missing func(s state.Service) bool
+}
+
+var sortFields = map[string]sortField{
+	"name":            {compare: func(a, b state.Service) int { return strings.Compare(a.Name, b.Name) }},
+	"displayName":     {compare: func(a, b state.Service) int { return strings.Compare(a.DisplayName, b.DisplayName) }},
+	"namespace":       {compare: func(a, b state.Service) int { return strings.Compare(a.Namespace, b.Namespace) }},
+	"cluster":         {compare: func(a, b state.Service) int { return strings.Compare(a.Cluster, b.Cluster) }},
+	"site":            {compare: func(a, b state.Service) int { return strings.Compare(a.Site, b.Site) }},
+	"group":           {compare: func(a, b state.Service) int { return strings.Compare(a.Group, b.Group) }},
+	"source":          {compare: func(a, b state.Service) int { return strings.Compare(a.Source, b.Source) }},
+	"status":          {compare: func(a, b state.Service) int { return statusRank[a.Status] - statusRank[b.Status] }},
+	"compositeStatus": {compare: func(a, b state.Service) int { return statusRank[a.CompositeStatus] - statusRank[b.CompositeStatus] }},
+	"responseTimeMs": {
+		compare: func(a, b state.Service) int { return cmp.Compare(*a.ResponseTimeMs, *b.ResponseTimeMs) },
+		missing: func(s state.Service) bool { return s.ResponseTimeMs == nil },
+	},
+	"lastChecked": {
+		compare: func(a, b state.Service) int { return a.LastChecked.Compare(*b.LastChecked) },
+		missing: func(s state.Service) bool { return s.LastChecked == nil },
+	},
+	"lastStateChange": {
+		compare: func(a, b state.Service) int { return a.LastStateChange.Compare(*b.LastStateChange) },
+		missing: func(s state.Service) bool { return s.LastStateChange == nil },
+	},
+}
+
+// serviceFields are the JSON field names of state.Service, for validating
+// field selections.
+var serviceFields = func() map[string]struct{} {
+	fields := make(map[string]struct{})
+	t := reflect.TypeOf(state.Service{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = struct{}{}
+		}
+	}
+	return fields
+}()
+
+// parseQuery reads the filter, sort, and fields parameters of a request.
+func parseQuery(v url.Values) (query, error) {
+	var q query
+	var err error
+	if q.status, err = statusSet(v, "status"); err != nil {
+		return q, err
+	}
+	if q.compositeStatus, err = statusSet(v, "compositeStatus"); err != nil {
+		return q, err
+	}
+	q.group = stringSet(v, "group")
+	q.namespace = stringSet(v, "namespace")
+	q.cluster = stringSet(v, "cluster")
+	q.site = stringSet(v, "site")
+	q.source = stringSet(v, "source")
+	q.search = strings.ToLower(strings.TrimSpace(v.Get("q")))
+	if raw := strings.TrimSpace(v.Get("selector")); raw != "" {
+		if q.selector, err = labels.Parse(raw); err != nil {
+			return q, fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+
+	q.sort = defaultSort
+	if keys := listParam(v, "sort"); len(keys) > 0 {
+		q.sort = nil
+		for _, k := range keys {
+			key := sortKey{field: k}
+			if rest, ok := strings.CutPrefix(k, "-"); ok {
+				key = sortKey{field: rest, desc: true}
+			}
+			if _, ok := sortFields[key.field]; !ok {
+				return q, fmt.Errorf("invalid sort field %q", key.field)
+			}
+			q.sort = append(q.sort, key)
+		}
+	}
+
+	fields, err := parseFields(v)
+	if err != nil {
+		return q, err
+	}
+	q.fields = fields
+	return q, nil
+}
+
+// parseFields reads the fields parameter; nil means all fields.
+func parseFields(v url.Values) ([]string, error) {
+	fields := listParam(v, "fields")
+	for _, f := range fields {
+		if _, ok := serviceFields[f]; !ok {
+			return nil, fmt.Errorf("invalid field %q", f)
+		}
+	}
+	return fields, nil
+}
+
+// listParam returns the comma-separated values of a parameter, which may
+// also be repeated.
+func listParam(v url.Values, name string) []string {
+	var out []string
+	for _, raw := range v[name] {
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func stringSet(v url.Values, name string) map[string]struct{} {
+	values := listParam(v, name)
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(values))
+	for _, s := range values {
+		set[s] = struct{}{}
+	}
+	return set
+}
+
+func statusSet(v url.Values, name string) (map[state.HealthStatus]struct{}, error) {
+	values := listParam(v, name)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	set := make(map[state.HealthStatus]struct{}, len(values))
+	for _, s := range values {
+		status := state.HealthStatus(s)
+		if _, ok := statusRank[status]; !ok {
+			return nil, fmt.Errorf("invalid %s %q: must be healthy, degraded, unhealthy, or unknown", name, s)
+		}
+		set[status] = struct{}{}
+	}
+	return set, nil
+}
+
+// apply filters and sorts services.
+func (q query) apply(services []state.Service) []state.Service {
+	out := make([]state.Service, 0, len(services))
+	for _, svc := range services {
+		if q.matches(svc) {
+			out = append(out, svc)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		for _, k := range q.sort {
+			if c := sortFields[k.field].order(out[i], out[j], k.desc); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return out
+}
+
+func (q query) matches(svc state.Service) bool {
+	if !inSet(q.status, svc.Status) || !inSet(q.compositeStatus, svc.CompositeStatus) ||
+		!inSet(q.group, svc.Group) || !inSet(q.namespace, svc.Namespace) || !inSet(q.cluster, svc.Cluster) ||
+		!inSet(q.site, svc.Site) || !inSet(q.source, svc.Source) {
+		return false
+	}
+	if q.selector != nil && !q.selector.Matches(labels.Set(svc.Labels)) {
+		return false
+	}
+	if q.search == "" {
+		return true
+	}
+	for _, s := range []string{svc.Name, svc.DisplayName, svc.Namespace, svc.Cluster, svc.Site, svc.Group, svc.URL} {
+		if strings.Contains(strings.ToLower(s), q.search) {
+			return true
+		}
+	}
+	return false
+}
+
+// inSet reports whether v is in set; an empty set matches everything.
+func inSet[T comparable](set map[T]struct{}, v T) bool {
+	if len(set) == 0 {
+		return true
+	}
+	_, ok := set[v]
+	return ok
+}
+
+// selectFields returns the JSON of svc limited to fields, or all of it if
+// fields is empty.
+func selectFields(svc state.Service, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return svc, nil
+	}
+	data, err := json.Marshal(svc)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	out := make(map[string]json.RawMessage, len(fields))
+	for _, f := range fields {
+		if v, ok := all[f]; ok {
+			out[f] = v
+		}
+	}
+	return out, nil
+}
+
+// order compares a and b in the requested direction, missing values last.
+func (f sortField) order(a, b state.Service, desc bool) int {
+	if f.missing != nil {
+		am, bm := f.missing(a), f.missing(b)
+		switch {
+		case am && bm:
+			return 0
+		case am:
+			return 1
+		case bm:
+			return -1
+		}
+	}
+	c := f.compare(a, b)
+	if desc {
+		c = -c
+	}
+	return c
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/rathix/command-center/internal/state"
+
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // StateSource is the interface the broker uses to read current state and subscribe to changes.
@@ -17,6 +20,7 @@ type StateSource interface {
 	Subscribe() <-chan state.Event
 	K8sConnected() bool
 	LastK8sEvent() time.Time
+	Clusters() []state.ClusterStatus
 	ConfigErrors() []string
 }
 
@@ -27,13 +31,20 @@ type sseEvent struct {
 	data []byte
 }
 
+// client tracks one connected SSE client.
+type client struct {
+	behind   bool                // missed an event; is sent the full state next
+	selector labels.Selector     // nil for every service
+	visible  map[string]struct{} // keys of the services a filtered client was sent
+}
+
 // Broker manages SSE client connections and broadcasts state events.
 type Broker struct {
 	source              StateSource
 	logger              *slog.Logger
 	appVersion          string
 	healthCheckInterval time.Duration
-	clients             map[chan sseEvent]struct{}
+	clients             map[chan sseEvent]*client
 	keepaliveInterval   time.Duration
 	keyboardConfig      *KeyboardConfig
 	mu                  sync.Mutex
@@ -54,7 +65,7 @@ func newBrokerWithKeepalive(source StateSource, logger *slog.Logger, appVersion
 		logger:              logger,
 		appVersion:          appVersion,
 		healthCheckInterval: healthCheckInterval,
-		clients:             make(map[chan sseEvent]struct{}),
+		clients:             make(map[chan sseEvent]*client),
 		keepaliveInterval:   keepaliveInterval,
 	}
 }
@@ -79,25 +90,28 @@ func (b *Broker) Run(ctx context.Context) {
 
 			var data []byte
 			var err error
+			var svcEvt *state.Event
+			full := false
 
 			switch evt.Type {
-			case state.EventDiscovered:
-				data, err = formatSSEEvent("discovered", discoveredEventPayloadFromService(evt.Service))
-			case state.EventUpdated:
-				data, err = formatSSEEvent("update", discoveredEventPayloadFromService(evt.Service))
-			case state.EventRemoved:
-				data, err = formatSSEEvent("removed", RemovedEventPayload{
-					Name:      evt.Name,
-					Namespace: evt.Namespace,
-				})
+			case state.EventDiscovered, state.EventUpdated, state.EventRemoved:
+				svcEvt = &evt
+				data, err = formatServiceEvent(evt)
 			case state.EventK8sStatus:
 				k8sLastEvent := b.source.LastK8sEvent().UTC().Format(time.RFC3339)
 				data, err = formatSSEEvent("k8sStatus", K8sStatusPayload{
 					K8sConnected: b.source.K8sConnected(),
 					K8sLastEvent: k8sLastEvent,
+					Clusters:     clusterPayloads(b.source.Clusters()),
 				})
 			case state.EventConfigErrors:
 				data, err = b.buildStateEvent()
+				full = true
+			case state.EventResync:
+				// Missed store events: send clients the full state again.
+				b.logger.Warn("SSE broker fell behind the store, resending state")
+				data, err = b.buildStateEvent()
+				full = true
 			default:
 				b.logger.Debug("unknown state event type", "type", evt.Type)
 				continue
@@ -108,7 +122,14 @@ func (b *Broker) Run(ctx context.Context) {
 				continue
 			}
 
-			b.broadcast(sseEvent{data: data})
+			switch {
+			case svcEvt != nil:
+				b.broadcastService(sseEvent{data: data}, *svcEvt)
+			case full:
+				b.broadcastState(sseEvent{data: data})
+			default:
+				b.broadcast(sseEvent{data: data})
+			}
 			b.logger.Debug("SSE event broadcast", "type", evt.Type)
 		}
 	}
@@ -124,24 +145,145 @@ func (b *Broker) closeAllClients() {
 }
 
 // broadcast sends an event to all connected clients using non-blocking sends.
+// A client too slow to take an event misses it; the next time it has room it
+// is sent the full state instead of the next event, so it cannot drift out of
+// sync.
 func (b *Broker) broadcast(evt sseEvent) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	for ch := range b.clients {
+	b.broadcastLocked(func(*client) (sseEvent, func(), bool) {
+		return evt, nil, true
+	})
+}
+
+// broadcastState broadcasts a full state event. Filtered clients are sent
+// their own state event instead.
+func (b *Broker) broadcastState(evt sseEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.broadcastLocked(func(c *client) (sseEvent, func(), bool) {
+		if c.selector == nil {
+			return evt, nil, true
+		}
+		return b.clientStateLocked(c)
+	})
+}
+
+// broadcastService broadcasts a service event. Filtered clients are only
+// sent events for services matching their selector: a service that starts
+// matching is sent as discovered, and one that stops matching as removed.
+func (b *Broker) broadcastService(evt sseEvent, svcEvt state.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.broadcastLocked(func(c *client) (sseEvent, func(), bool) {
+		if c.selector == nil {
+			return evt, nil, true
+		}
+		return b.filterServiceEvent(c, evt, svcEvt)
+	})
+}
+
+// broadcastLocked sends each client the event next returns for it, or
+// nothing if next returns false. The returned func, if any, runs once the
+// event is delivered.
+func (b *Broker) broadcastLocked(next func(c *client) (sseEvent, func(), bool)) {
+	var resync *sseEvent
+	for ch, c := range b.clients {
+		var evt sseEvent
+		var delivered func()
+		switch {
+		case c.behind && c.selector != nil:
+			var ok bool
+			if evt, delivered, ok = b.clientStateLocked(c); !ok {
+				continue
+			}
+		case c.behind:
+			if resync == nil {
+				data, err := b.buildStateEventLocked()
+				if err != nil {
+					b.logger.Debug("failed to format state event", "error", err)
+					continue
+				}
+				resync = &sseEvent{data: data}
+			}
+			evt = *resync
+		default:
+			var ok bool
+			if evt, delivered, ok = next(c); !ok {
+				continue
+			}
+		}
 		select {
 		case ch <- evt:
+			c.behind = false
+			if delivered != nil {
+				delivered()
+			}
 		default:
-			// Client too slow, skip this event
+			c.behind = true
+		}
+	}
+}
+
+// clientStateLocked returns the state event for a filtered client, which
+// resets the services it has been sent once delivered.
+func (b *Broker) clientStateLocked(c *client) (sseEvent, func(), bool) {
+	data, visible, err := b.filteredStateEventLocked(c.selector)
+	if err != nil {
+		b.logger.Debug("failed to format state event", "error", err)
+		return sseEvent{}, nil, false
+	}
+	return sseEvent{data: data}, func() { c.visible = visible }, true
+}
+
+// filterServiceEvent adapts a service event to a filtered client.
+func (b *Broker) filterServiceEvent(c *client, evt sseEvent, svcEvt state.Event) (sseEvent, func(), bool) {
+	key := svcEvt.Key()
+	_, shown := c.visible[key]
+	show := func() { c.visible[key] = struct{}{} }
+	hide := func() { delete(c.visible, key) }
+
+	switch {
+	case svcEvt.Type == state.EventRemoved:
+		if !shown {
+			return sseEvent{}, nil, false
+		}
+		return evt, hide, true
+	case c.selector.Matches(labels.Set(svcEvt.Service.Labels)):
+		if shown || svcEvt.Type == state.EventDiscovered {
+			return evt, show, true
 		}
+		svcEvt.Type = state.EventDiscovered
+	case shown:
+		svc := svcEvt.Service
+		svcEvt = state.Event{Type: state.EventRemoved, Site: svc.Site, Cluster: svc.Cluster, Namespace: svc.Namespace, Name: svc.Name}
+	default:
+		return sseEvent{}, nil, false
+	}
+
+	data, err := formatServiceEvent(svcEvt)
+	if err != nil {
+		b.logger.Debug("failed to format SSE event", "error", err)
+		return sseEvent{}, nil, false
+	}
+	if svcEvt.Type == state.EventRemoved {
+		return sseEvent{data: data}, hide, true
 	}
+	return sseEvent{data: data}, show, true
 }
 
-// addClient registers a new client channel.
-func (b *Broker) addClient(ch chan sseEvent) {
+// addClient registers a new client channel. A non-nil selector limits the
+// client to the services matching it.
+func (b *Broker) addClient(ch chan sseEvent, selector labels.Selector) *client {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.clients[ch] = struct{}{}
+	c := &client{selector: selector}
+	if selector != nil {
+		c.visible = make(map[string]struct{})
+	}
+	b.clients[ch] = c
 	b.logger.Info("SSE client connected", "clients", len(b.clients))
+	return c
 }
 
 // removeClient unregisters and closes a client channel.
@@ -152,7 +294,16 @@ func (b *Broker) removeClient(ch chan sseEvent) {
 	b.logger.Info("SSE client disconnected", "clients", len(b.clients))
 }
 
+// ClientCount returns the number of connected SSE clients.
+func (b *Broker) ClientCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
 // ServeHTTP handles SSE connections: sets headers, sends initial state, and streams events.
+// The selector query parameter, a Kubernetes-style label selector, limits
+// the stream to matching services.
 func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -160,17 +311,26 @@ func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var selector labels.Selector
+	if raw := strings.TrimSpace(r.URL.Query().Get("selector")); raw != "" {
+		var err error
+		if selector, err = labels.Parse(raw); err != nil {
+			http.Error(w, "invalid selector: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
 	// Register client before sending the initial snapshot so no updates are missed.
 	clientCh := make(chan sseEvent, 64)
-	b.addClient(clientCh)
+	c := b.addClient(clientCh, selector)
 	defer b.removeClient(clientCh)
 
 	// Send initial state event with all current services.
-	initialData, err := b.buildStateEvent()
+	initialData, err := b.initialStateEvent(c)
 	if err != nil {
 		b.logger.Debug("failed to format initial state event", "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -224,7 +384,50 @@ func (b *Broker) SetKeyboardConfig(cfg *KeyboardConfig) {
 }
 
 func (b *Broker) buildStateEvent() ([]byte, error) {
-	services := b.source.All()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buildStateEventLocked()
+}
+
+// initialStateEvent returns the state event a new client starts with.
+func (b *Broker) initialStateEvent(c *client) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c.selector == nil {
+		return b.buildStateEventLocked()
+	}
+	data, visible, err := b.filteredStateEventLocked(c.selector)
+	if err != nil {
+		return nil, err
+	}
+	c.visible = visible
+	return data, nil
+}
+
+// buildStateEventLocked is buildStateEvent for callers holding b.mu.
+func (b *Broker) buildStateEventLocked() ([]byte, error) {
+	return b.stateEventLocked(b.source.All())
+}
+
+// filteredStateEventLocked builds a state event with only the services
+// matching selector, and returns their keys.
+func (b *Broker) filteredStateEventLocked(selector labels.Selector) ([]byte, map[string]struct{}, error) {
+	var services []state.Service
+	visible := make(map[string]struct{})
+	for _, svc := range b.source.All() {
+		if selector.Matches(labels.Set(svc.Labels)) {
+			services = append(services, svc)
+			visible[svc.Key()] = struct{}{}
+		}
+	}
+	if services == nil {
+		services = []state.Service{}
+	}
+	data, err := b.stateEventLocked(services)
+	return data, visible, err
+}
+
+func (b *Broker) stateEventLocked(services []state.Service) ([]byte, error) {
 	var k8sLastEvent *time.Time
 	if t := b.source.LastK8sEvent(); !t.IsZero() {
 		k8sLastEvent = &t
@@ -234,6 +437,7 @@ func (b *Broker) buildStateEvent() ([]byte, error) {
 		Services:              services,
 		K8sConnected:          b.source.K8sConnected(),
 		K8sLastEvent:          k8sLastEvent,
+		Clusters:              clusterPayloads(b.source.Clusters()),
 		HealthCheckIntervalMs: int(b.healthCheckInterval.Milliseconds()),
 		ConfigErrors:          b.source.ConfigErrors(),
 		Keyboard:              b.keyboardConfig,
@@ -15,6 +15,8 @@ import (
 	"log/slog"
 
 	"github.com/rathix/command-center/internal/state"
+
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // mockStateSource implements StateSource for testing.
@@ -23,6 +25,7 @@ type mockStateSource struct {
 	eventCh      chan state.Event
 	k8sConnected bool
 	lastK8sEvent time.Time
+	clusters     []state.ClusterStatus
 	configErrors []string
 }
 
@@ -49,6 +52,10 @@ func (m *mockStateSource) LastK8sEvent() time.Time {
 	return m.lastK8sEvent
 }
 
+func (m *mockStateSource) Clusters() []state.ClusterStatus {
+	return m.clusters
+}
+
 func (m *mockStateSource) ConfigErrors() []string {
 	return m.configErrors
 }
@@ -763,6 +770,10 @@ func TestBrokerK8sStatusEventBroadcast(t *testing.T) {
 	source := newMockSource(nil)
 	source.k8sConnected = true
 	source.lastK8sEvent = time.Date(2026, 2, 20, 14, 30, 0, 0, time.UTC)
+	source.clusters = []state.ClusterStatus{
+		{Name: "lab", Connected: false},
+		{Name: "prod", Connected: true, LastEvent: source.lastK8sEvent},
+	}
 	broker := NewBroker(source, discardLogger(), "v1.0.0", 30*time.Second)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -816,6 +827,10 @@ func TestBrokerK8sStatusEventBroadcast(t *testing.T) {
 	if payload.K8sLastEvent == "" {
 		t.Error("expected k8sLastEvent to be set in k8sStatus event")
 	}
+	if len(payload.Clusters) != 2 || payload.Clusters[0].Name != "lab" || payload.Clusters[0].Connected || payload.Clusters[0].LastEvent != nil ||
+		!payload.Clusters[1].Connected || payload.Clusters[1].LastEvent == nil || !payload.Clusters[1].LastEvent.Equal(source.lastK8sEvent) {
+		t.Errorf("clusters = %+v, want lab disconnected and prod connected", payload.Clusters)
+	}
 }
 
 func TestBrokerConfigErrorsEventBroadcastsStateSnapshot(t *testing.T) {
@@ -989,3 +1004,124 @@ func TestRemovedEventPayloadCamelCaseJSON(t *testing.T) {
 		t.Errorf("expected 'namespace' field in JSON: %s", jsonStr)
 	}
 }
+
+func TestBrokerResyncEventBroadcastsState(t *testing.T) {
+	source := newMockSource([]state.Service{{Name: "web", Namespace: "default", Status: "healthy"}})
+	broker := NewBroker(source, discardLogger(), "v1.0.0", 30*time.Second)
+	client := make(chan sseEvent, 1)
+	broker.addClient(client, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go broker.Run(ctx)
+
+	source.eventCh <- state.Event{Type: state.EventResync}
+	select {
+	case evt := <-client:
+		if !strings.HasPrefix(string(evt.data), "event: state\n") || !strings.Contains(string(evt.data), `"web"`) {
+			t.Errorf("expected full state event, got %q", evt.data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for state event")
+	}
+}
+
+func TestBrokerSlowClientGetsStateAfterMissedEvent(t *testing.T) {
+	source := newMockSource([]state.Service{{Name: "web", Namespace: "default", Status: "healthy"}})
+	broker := NewBroker(source, discardLogger(), "v1.0.0", 30*time.Second)
+	client := make(chan sseEvent, 1)
+	broker.addClient(client, nil)
+
+	broker.broadcast(sseEvent{data: []byte("first")})
+	broker.broadcast(sseEvent{data: []byte("missed")})
+	if got := string((<-client).data); got != "first" {
+		t.Fatalf("expected first event, got %q", got)
+	}
+
+	broker.broadcast(sseEvent{data: []byte("third")})
+	got := string((<-client).data)
+	if !strings.HasPrefix(got, "event: state\n") {
+		t.Fatalf("expected state event in place of the next event, got %q", got)
+	}
+
+	broker.broadcast(sseEvent{data: []byte("fourth")})
+	if got := string((<-client).data); got != "fourth" {
+		t.Errorf("expected normal delivery after resync, got %q", got)
+	}
+}
+
+func TestBrokerSelectorFiltersServiceEvents(t *testing.T) {
+	critical := map[string]string{"tier": "critical"}
+	source := newMockSource([]state.Service{
+		{Name: "db", Namespace: "default", Labels: critical},
+		{Name: "web", Namespace: "default"},
+	})
+	broker := NewBroker(source, discardLogger(), "v1.0.0", 30*time.Second)
+	client := make(chan sseEvent, 8)
+	c := broker.addClient(client, labels.SelectorFromSet(critical))
+
+	initial, err := broker.initialStateEvent(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(initial), `"db"`) || strings.Contains(string(initial), `"web"`) {
+		t.Fatalf("initial state should only hold matching services, got %q", initial)
+	}
+
+	send := func(evt state.Event) {
+		data, err := formatServiceEvent(evt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		broker.broadcastService(sseEvent{data: data}, evt)
+	}
+	expect := func(prefix, name string) {
+		t.Helper()
+		select {
+		case evt := <-client:
+			got := string(evt.data)
+			if !strings.HasPrefix(got, "event: "+prefix+"\n") || !strings.Contains(got, `"name":"`+name+`"`) {
+				t.Errorf("expected %s event for %s, got %q", prefix, name, got)
+			}
+		default:
+			t.Errorf("expected %s event for %s, got none", prefix, name)
+		}
+	}
+	expectNone := func() {
+		t.Helper()
+		select {
+		case evt := <-client:
+			t.Errorf("expected no event, got %q", evt.data)
+		default:
+		}
+	}
+
+	send(state.Event{Type: state.EventUpdated, Service: state.Service{Name: "web", Namespace: "default"}})
+	expectNone()
+	send(state.Event{Type: state.EventUpdated, Service: state.Service{Name: "db", Namespace: "default", Labels: critical}})
+	expect("update", "db")
+
+	// Losing the label removes the service from the client's view.
+	send(state.Event{Type: state.EventUpdated, Service: state.Service{Name: "db", Namespace: "default"}})
+	expect("removed", "db")
+	send(state.Event{Type: state.EventRemoved, Namespace: "default", Name: "db"})
+	expectNone()
+
+	// Gaining it adds the service back.
+	send(state.Event{Type: state.EventUpdated, Service: state.Service{Name: "web", Namespace: "default", Labels: critical}})
+	expect("discovered", "web")
+	send(state.Event{Type: state.EventRemoved, Namespace: "default", Name: "web"})
+	expect("removed", "web")
+}
+
+func TestBrokerInvalidSelector(t *testing.T) {
+	broker := NewBroker(newMockSource(nil), discardLogger(), "v1.0.0", 30*time.Second)
+	rec := httptest.NewRecorder()
+	broker.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/events?selector=tier+in+(a", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+	if broker.ClientCount() != 0 {
+		t.Error("client with an invalid selector should not be registered")
+	}
+}
@@ -21,15 +21,39 @@ type StateEventPayload struct {
 	Services              []state.Service  `json:"services"`
 	K8sConnected          bool             `json:"k8sConnected"`
 	K8sLastEvent          *time.Time       `json:"k8sLastEvent"`
+	Clusters              []ClusterStatusPayload `json:"clusters"`
 	HealthCheckIntervalMs int              `json:"healthCheckIntervalMs"`
 	ConfigErrors          []string         `json:"configErrors"`
 	Keyboard              *KeyboardConfig  `json:"keyboard,omitempty"`
 }
 
 // K8sStatusPayload is the JSON payload for "k8sStatus" events.
+// K8sConnected is true when every cluster is connected.
 type K8sStatusPayload struct {
-	K8sConnected bool   `json:"k8sConnected"`
-	K8sLastEvent string `json:"k8sLastEvent"`
+	K8sConnected bool                   `json:"k8sConnected"`
+	K8sLastEvent string                 `json:"k8sLastEvent"`
+	Clusters     []ClusterStatusPayload `json:"clusters"`
+}
+
+// ClusterStatusPayload is the connectivity of one cluster. The cluster of a
+// single-cluster setup has an empty name.
+type ClusterStatusPayload struct {
+	Name      string     `json:"name"`
+	Connected bool       `json:"connected"`
+	LastEvent *time.Time `json:"lastEvent"`
+}
+
+func clusterPayloads(clusters []state.ClusterStatus) []ClusterStatusPayload {
+	out := make([]ClusterStatusPayload, 0, len(clusters))
+	for _, c := range clusters {
+		p := ClusterStatusPayload{Name: c.Name, Connected: c.Connected}
+		if !c.LastEvent.IsZero() {
+			t := c.LastEvent.UTC()
+			p.LastEvent = &t
+		}
+		out = append(out, p)
+	}
+	return out
 }
 
 // DiscoveredEventPayload is the JSON payload for "discovered" and "update" events.
@@ -37,9 +61,13 @@ type DiscoveredEventPayload struct {
 	Name            string             `json:"name"`
 	DisplayName     string             `json:"displayName"`
 	Namespace       string             `json:"namespace"`
+	Cluster         string             `json:"cluster,omitempty"`
+	Site            string             `json:"site,omitempty"`
 	Group           string             `json:"group"`
+	Labels          map[string]string  `json:"labels,omitempty"`
 	URL             string             `json:"url"`
 	Icon            string             `json:"icon,omitempty"`
+	Description     string             `json:"description,omitempty"`
 	Source          string             `json:"source"`
 	Status          state.HealthStatus   `json:"status"`
 	CompositeStatus state.HealthStatus   `json:"compositeStatus"`
@@ -49,6 +77,17 @@ type DiscoveredEventPayload struct {
 	LastChecked     *time.Time         `json:"lastChecked"`
 	LastStateChange *time.Time         `json:"lastStateChange"`
 	ErrorSnippet    *string            `json:"errorSnippet"`
+	PendingStatus   state.HealthStatus   `json:"pendingStatus,omitempty"`
+	PendingCount    int                  `json:"pendingCount,omitempty"`
+	PendingThreshold int                 `json:"pendingThreshold,omitempty"`
+	Flapping        bool                 `json:"flapping"`
+	TLSCert         *state.TLSCertInfo   `json:"tlsCert,omitempty"`
+	LatencyBreach   string               `json:"latencyBreach,omitempty"`
+	DependsOn       []string             `json:"dependsOn,omitempty"`
+	BlockedBy       []string             `json:"blockedBy,omitempty"`
+	Maintenance     bool                 `json:"maintenance"`
+	MaintenanceWindow string             `json:"maintenanceWindow,omitempty"`
+	Stale           bool                 `json:"stale,omitempty"`
 	ReadyEndpoints  *int                 `json:"readyEndpoints"`
 	TotalEndpoints  *int                 `json:"totalEndpoints"`
 	PodDiagnostic   *state.PodDiagnostic `json:"podDiagnostic"`
@@ -59,6 +98,8 @@ type DiscoveredEventPayload struct {
 type RemovedEventPayload struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster,omitempty"`
+	Site      string `json:"site,omitempty"`
 }
 
 func discoveredEventPayloadFromService(svc state.Service) DiscoveredEventPayload {
@@ -66,9 +107,13 @@ func discoveredEventPayloadFromService(svc state.Service) DiscoveredEventPayload
 		Name:            svc.Name,
 		DisplayName:     svc.DisplayName,
 		Namespace:       svc.Namespace,
+		Cluster:         svc.Cluster,
+		Site:            svc.Site,
 		Group:           svc.Group,
+		Labels:          svc.Labels,
 		URL:             svc.URL,
 		Icon:            svc.Icon,
+		Description:     svc.Description,
 		Source:          svc.Source,
 		Status:          svc.Status,
 		CompositeStatus: svc.CompositeStatus,
@@ -78,6 +123,17 @@ func discoveredEventPayloadFromService(svc state.Service) DiscoveredEventPayload
 		LastChecked:     svc.LastChecked,
 		LastStateChange: svc.LastStateChange,
 		ErrorSnippet:    svc.ErrorSnippet,
+		PendingStatus:   svc.PendingStatus,
+		PendingCount:    svc.PendingCount,
+		PendingThreshold: svc.PendingThreshold,
+		Flapping:        svc.Flapping,
+		TLSCert:         svc.TLSCert,
+		LatencyBreach:   svc.LatencyBreach,
+		DependsOn:       svc.DependsOn,
+		BlockedBy:       svc.BlockedBy,
+		Maintenance:     svc.Maintenance,
+		MaintenanceWindow: svc.MaintenanceWindow,
+		Stale:           svc.Stale,
 		ReadyEndpoints:  svc.ReadyEndpoints,
 		TotalEndpoints:  svc.TotalEndpoints,
 		PodDiagnostic:   svc.PodDiagnostic,
@@ -85,6 +141,23 @@ func discoveredEventPayloadFromService(svc state.Service) DiscoveredEventPayload
 	}
 }
 
+// formatServiceEvent formats a discovered, updated, or removed store event.
+func formatServiceEvent(evt state.Event) ([]byte, error) {
+	switch evt.Type {
+	case state.EventDiscovered:
+		return formatSSEEvent("discovered", discoveredEventPayloadFromService(evt.Service))
+	case state.EventUpdated:
+		return formatSSEEvent("update", discoveredEventPayloadFromService(evt.Service))
+	default:
+		return formatSSEEvent("removed", RemovedEventPayload{
+			Name:      evt.Name,
+			Namespace: evt.Namespace,
+			Cluster:   evt.Cluster,
+			Site:      evt.Site,
+		})
+	}
+}
+
 // formatSSEEvent formats an SSE event with the given type and JSON-encoded data.
 func formatSSEEvent(eventType string, data interface{}) ([]byte, error) {
 	jsonData, err := json.Marshal(data)
@@ -94,19 +94,29 @@ func TestDiscoveredEventPayloadFromServiceAllFields(t *testing.T) {
 	total := 5
 
 	svc := state.Service{
-		Name:            "web",
-		DisplayName:     "Web App",
-		Namespace:       "production",
-		URL:             "https://web.example.com",
-		Status:          state.StatusHealthy,
-		ReadyEndpoints:  &ready,
-		TotalEndpoints:  &total,
-		AuthGuarded:     true,
-		HTTPCode:        &code,
-		ResponseTimeMs:  &respTime,
-		LastChecked:     &now,
-		LastStateChange: &now,
-		ErrorSnippet:    &errSnippet,
+		Name:              "web",
+		DisplayName:       "Web App",
+		Namespace:         "production",
+		URL:               "https://web.example.com",
+		Description:       "Customer-facing storefront",
+		Status:            state.StatusHealthy,
+		ReadyEndpoints:    &ready,
+		TotalEndpoints:    &total,
+		AuthGuarded:       true,
+		HTTPCode:          &code,
+		ResponseTimeMs:    &respTime,
+		LastChecked:       &now,
+		LastStateChange:   &now,
+		ErrorSnippet:      &errSnippet,
+		PendingStatus:     state.StatusUnhealthy,
+		PendingCount:      2,
+		PendingThreshold:  3,
+		Flapping:          true,
+		LatencyBreach:     "p95=9200ms",
+		DependsOn:         []string{"custom/truenas"},
+		BlockedBy:         []string{"custom/truenas"},
+		Maintenance:       true,
+		MaintenanceWindow: "nightly",
 	}
 
 	payload := discoveredEventPayloadFromService(svc)
@@ -123,6 +133,9 @@ func TestDiscoveredEventPayloadFromServiceAllFields(t *testing.T) {
 	if payload.URL != "https://web.example.com" {
 		t.Errorf("URL = %q, want %q", payload.URL, "https://web.example.com")
 	}
+	if payload.Description != "Customer-facing storefront" {
+		t.Errorf("Description = %q, want %q", payload.Description, "Customer-facing storefront")
+	}
 	if payload.Status != state.StatusHealthy {
 		t.Errorf("Status = %q, want %q", payload.Status, state.StatusHealthy)
 	}
@@ -144,6 +157,21 @@ func TestDiscoveredEventPayloadFromServiceAllFields(t *testing.T) {
 	if payload.ErrorSnippet == nil || *payload.ErrorSnippet != "timeout" {
 		t.Errorf("ErrorSnippet = %v, want %q", payload.ErrorSnippet, "timeout")
 	}
+	if payload.PendingStatus != state.StatusUnhealthy || payload.PendingCount != 2 || payload.PendingThreshold != 3 {
+		t.Errorf("pending = %q %d/%d, want unhealthy 2/3", payload.PendingStatus, payload.PendingCount, payload.PendingThreshold)
+	}
+	if !payload.Flapping {
+		t.Error("Flapping = false, want true")
+	}
+	if payload.LatencyBreach != "p95=9200ms" {
+		t.Errorf("LatencyBreach = %q, want %q", payload.LatencyBreach, "p95=9200ms")
+	}
+	if len(payload.BlockedBy) != 1 || payload.BlockedBy[0] != "custom/truenas" || len(payload.DependsOn) != 1 {
+		t.Errorf("dependencies = %v blocked by %v, want custom/truenas", payload.DependsOn, payload.BlockedBy)
+	}
+	if !payload.Maintenance || payload.MaintenanceWindow != "nightly" {
+		t.Errorf("maintenance = %v window %q, want true nightly", payload.Maintenance, payload.MaintenanceWindow)
+	}
 }
 
 func TestDiscoveredEventPayloadFromServiceNilOptionalFields(t *testing.T) {
@@ -184,3 +212,28 @@ func TestDiscoveredEventPayloadFromServiceNilOptionalFields(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatServiceEventCarriesClusterAndSite(t *testing.T) {
+	for _, evt := range []state.Event{
+		{Type: state.EventUpdated, Service: state.Service{Name: "web", Namespace: "media", Cluster: "lab", Site: "east"}},
+		{Type: state.EventRemoved, Site: "east", Cluster: "lab", Namespace: "media", Name: "web"},
+	} {
+		data, err := formatServiceEvent(evt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{`"namespace":"media"`, `"cluster":"lab"`, `"site":"east"`} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("event %v payload %s lacks %s", evt.Type, data, want)
+			}
+		}
+	}
+
+	data, err := formatServiceEvent(state.Event{Type: state.EventRemoved, Namespace: "media", Name: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "cluster") || strings.Contains(string(data), "site") {
+		t.Errorf("local unclustered payload should omit cluster and site: %s", data)
+	}
+}
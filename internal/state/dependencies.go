@@ -0,0 +1,93 @@
+package state
+
+import (
+	"slices"
+	"strings"
+)
+
+// DependencyKey resolves a dependsOn entry of svc to a service key. Entries
+// are service keys ("namespace/name", or "cluster/namespace/name" for a
+// service in a named cluster); a bare name refers to a service in svc's
+// namespace and cluster. The entries of a federated service are keys within
+// its site.
+func DependencyKey(svc Service, dep string) string {
+	if !strings.Contains(dep, "/") {
+		return serviceKey(svc.ScopedNamespace(), dep)
+	}
+	if svc.Site != "" {
+		return svc.Site + "/" + dep
+	}
+	return dep
+}
+
+// failing reports whether a service counts as down for dependency purposes.
+func failing(svc Service) bool {
+	return svc.CompositeStatus == StatusUnhealthy || svc.CompositeStatus == StatusDegraded
+}
+
+// blockedByLocked returns the sorted keys of the root causes blocking svc:
+// while svc is failing, the failing services reachable through failing
+// dependencies that have no failing dependencies of their own. A healthy
+// dependency ends the walk, since whatever it depends on evidently did not
+// take it down. Must be called with s.mu held.
+func (s *Store) blockedByLocked(svc Service) []string {
+	if len(svc.DependsOn) == 0 || !failing(svc) {
+		return nil
+	}
+	visited := map[string]bool{svc.Key(): true}
+	roots := make(map[string]struct{})
+
+	var visit func(key string, cur Service)
+	visit = func(key string, cur Service) {
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		blocked := false
+		for _, d := range cur.DependsOn {
+			depKey := DependencyKey(cur, d)
+			if dep, ok := s.services[depKey]; ok && failing(dep) {
+				blocked = true
+				visit(depKey, dep)
+			}
+		}
+		if !blocked {
+			roots[key] = struct{}{}
+		}
+	}
+	for _, d := range svc.DependsOn {
+		depKey := DependencyKey(svc, d)
+		if dep, ok := s.services[depKey]; ok && failing(dep) {
+			visit(depKey, dep)
+		}
+	}
+
+	if len(roots) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(roots))
+	for key := range roots {
+		out = append(out, key)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// refreshBlockedLocked recomputes BlockedBy for every service with
+// dependencies other than changedKey (which the caller just updated) and
+// publishes an update for each service whose value changed. Must be called
+// with s.mu held.
+func (s *Store) refreshBlockedLocked(changedKey string) {
+	for key, svc := range s.services {
+		if key == changedKey || len(svc.DependsOn) == 0 {
+			continue
+		}
+		blockedBy := s.blockedByLocked(svc)
+		if slices.Equal(blockedBy, svc.BlockedBy) {
+			continue
+		}
+		svc.BlockedBy = blockedBy
+		s.services[key] = svc
+		s.publishLocked(Event{Type: EventUpdated, Service: svc.DeepCopy()})
+	}
+}
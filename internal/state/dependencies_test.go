@@ -0,0 +1,145 @@
+package state
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBlockedBy(t *testing.T) {
+	down := func(ns, name string, deps ...string) Service {
+		return Service{Name: name, Namespace: ns, Status: StatusUnhealthy, CompositeStatus: StatusUnhealthy, DependsOn: deps}
+	}
+	up := func(ns, name string, deps ...string) Service {
+		return Service{Name: name, Namespace: ns, Status: StatusHealthy, CompositeStatus: StatusHealthy, DependsOn: deps}
+	}
+	inCluster := func(cluster string, svc Service) Service {
+		svc.Cluster = cluster
+		return svc
+	}
+
+	tests := []struct {
+		name     string
+		services []Service
+		key      string
+		want     []string
+	}{
+		{
+			name:     "failing dependency is the root",
+			services: []Service{down("custom", "truenas"), down("media", "jellyfin", "custom/truenas")},
+			key:      "media/jellyfin",
+			want:     []string{"custom/truenas"},
+		},
+		{
+			name:     "bare name resolves in the same namespace",
+			services: []Service{down("media", "db"), down("media", "app", "db")},
+			key:      "media/app",
+			want:     []string{"media/db"},
+		},
+		{
+			name: "bare name resolves in the same cluster",
+			services: []Service{
+				down("media", "db"),
+				inCluster("lab", down("media", "db")),
+				inCluster("lab", down("media", "app", "db")),
+			},
+			key:  "lab/media/app",
+			want: []string{"lab/media/db"},
+		},
+		{
+			name:     "cluster key",
+			services: []Service{inCluster("lab", down("media", "db")), down("media", "app", "lab/media/db")},
+			key:      "media/app",
+			want:     []string{"lab/media/db"},
+		},
+		{
+			name: "transitive root",
+			services: []Service{
+				down("custom", "truenas"),
+				down("kube-system", "traefik", "custom/truenas"),
+				down("media", "jellyfin", "kube-system/traefik"),
+			},
+			key:  "media/jellyfin",
+			want: []string{"custom/truenas"},
+		},
+		{
+			name: "healthy dependency ends the walk",
+			services: []Service{
+				down("custom", "truenas"),
+				up("kube-system", "traefik", "custom/truenas"),
+				down("media", "jellyfin", "kube-system/traefik"),
+			},
+			key: "media/jellyfin",
+		},
+		{
+			name:     "healthy service is not blocked",
+			services: []Service{down("custom", "truenas"), up("media", "jellyfin", "custom/truenas")},
+			key:      "media/jellyfin",
+		},
+		{
+			name: "several roots",
+			services: []Service{
+				down("custom", "truenas"),
+				down("kube-system", "traefik"),
+				down("media", "jellyfin", "custom/truenas", "kube-system/traefik", "custom/missing"),
+			},
+			key:  "media/jellyfin",
+			want: []string{"custom/truenas", "kube-system/traefik"},
+		},
+		{
+			name:     "cycle has no root",
+			services: []Service{down("ns", "a", "b"), down("ns", "b", "a"), down("ns", "c", "a")},
+			key:      "ns/c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			for _, svc := range tt.services {
+				s.AddOrUpdate(svc)
+			}
+			i := strings.LastIndex(tt.key, "/")
+			svc, _ := s.Get(tt.key[:i], tt.key[i+1:])
+			if !slices.Equal(svc.BlockedBy, tt.want) {
+				t.Errorf("BlockedBy = %v, want %v", svc.BlockedBy, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlockedBy_RefreshedWhenDependencyChanges(t *testing.T) {
+	s := NewStore()
+	s.AddOrUpdate(Service{Name: "truenas", Namespace: "custom", Status: StatusHealthy, CompositeStatus: StatusHealthy})
+	s.AddOrUpdate(Service{Name: "jellyfin", Namespace: "media", Status: StatusUnhealthy, CompositeStatus: StatusUnhealthy, DependsOn: []string{"custom/truenas"}})
+
+	ch := s.Subscribe()
+	defer s.Unsubscribe(ch)
+
+	s.Update("custom", "truenas", func(svc *Service) {
+		svc.Status = StatusUnhealthy
+		svc.CompositeStatus = StatusUnhealthy
+	})
+	<-ch // truenas update
+	evt := <-ch
+	if evt.Service.Name != "jellyfin" || !slices.Equal(evt.Service.BlockedBy, []string{"custom/truenas"}) {
+		t.Fatalf("expected jellyfin update blocked by truenas, got %s %v", evt.Service.Name, evt.Service.BlockedBy)
+	}
+
+	s.Update("custom", "truenas", func(svc *Service) {
+		svc.Status = StatusHealthy
+		svc.CompositeStatus = StatusHealthy
+	})
+	<-ch
+	evt = <-ch
+	if evt.Service.Name != "jellyfin" || evt.Service.BlockedBy != nil {
+		t.Fatalf("expected jellyfin unblocked, got %s %v", evt.Service.Name, evt.Service.BlockedBy)
+	}
+
+	s.Remove("media", "jellyfin")
+	<-ch
+	select {
+	case evt := <-ch:
+		t.Errorf("unexpected event after removal: %+v", evt)
+	default:
+	}
+}
@@ -0,0 +1,78 @@
+package state
+
+import "strings"
+
+// inSite reports whether svc is federated from site, directly or through a
+// site the remote federates itself.
+func inSite(svc Service, site string) bool {
+	return svc.Site == site || strings.HasPrefix(svc.Site, site+"/")
+}
+
+// ReplaceSite makes the federated services of site, including those of its
+// nested sites, exactly services, as reported in a full state snapshot of
+// the remote instance: services not in it are removed, the others added or
+// replaced. Each service's Site must already be set. It returns the number
+// of services removed.
+func (s *Store) ReplaceSite(site string, services []Service) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keep := make(map[string]struct{}, len(services))
+	for _, svc := range services {
+		keep[svc.Key()] = struct{}{}
+	}
+	removed := 0
+	for key, svc := range s.services {
+		if !inSite(svc, site) {
+			continue
+		}
+		if _, ok := keep[key]; ok {
+			continue
+		}
+		delete(s.services, key)
+		s.publishLocked(removedEvent(svc))
+		removed++
+	}
+
+	// Dependencies may arrive in any order, so compute BlockedBy once all
+	// services are present.
+	events := make([]EventType, len(services))
+	for i, svc := range services {
+		key := svc.Key()
+		events[i] = EventDiscovered
+		if _, exists := s.services[key]; exists {
+			events[i] = EventUpdated
+		}
+		s.services[key] = svc.DeepCopy()
+	}
+	for i, svc := range services {
+		key := svc.Key()
+		svc = s.services[key]
+		svc.BlockedBy = s.blockedByLocked(svc)
+		s.services[key] = svc
+		s.publishLocked(Event{Type: events[i], Service: svc.DeepCopy()})
+	}
+	s.refreshBlockedLocked("")
+	return removed
+}
+
+// MarkSiteStale marks every federated service of site and its nested sites
+// stale, for when the link to it drops. The services keep their last
+// reported state until the site is reachable again. It returns the number
+// of services marked.
+func (s *Store) MarkSiteStale(site string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	marked := 0
+	for key, svc := range s.services {
+		if !inSite(svc, site) || svc.Stale {
+			continue
+		}
+		svc.Stale = true
+		s.services[key] = svc
+		s.publishLocked(Event{Type: EventUpdated, Service: svc.DeepCopy()})
+		marked++
+	}
+	return marked
+}
@@ -0,0 +1,79 @@
+package state
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStoreReplaceSite(t *testing.T) {
+	store := NewStore()
+	store.AddOrUpdate(Service{Name: "api", Namespace: "default", Source: SourceKubernetes, Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "old", Namespace: "default", Site: "east", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "api", Namespace: "default", Site: "west", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "api", Namespace: "default", Site: "eastern", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "gone", Namespace: "default", Site: "east/lab", Status: StatusHealthy})
+	events := store.Subscribe()
+
+	removed := store.ReplaceSite("east", []Service{
+		{Name: "api", Namespace: "default", Site: "east", Status: StatusUnhealthy, CompositeStatus: StatusUnhealthy,
+			DependsOn: []string{"custom/db"}},
+		{Name: "db", Namespace: "custom", Site: "east", Status: StatusUnhealthy, CompositeStatus: StatusUnhealthy},
+	})
+	if removed != 2 {
+		t.Errorf("ReplaceSite() = %d, want 2", removed)
+	}
+	for _, svc := range []struct{ ns, name string }{{"east/default", "old"}, {"east/lab/default", "gone"}} {
+		if _, ok := store.Get(svc.ns, svc.name); ok {
+			t.Errorf("%s/%s missing from the snapshot should be removed", svc.ns, svc.name)
+		}
+	}
+	api, ok := store.Get("east/default", "api")
+	if !ok {
+		t.Fatal("expected east/default/api")
+	}
+	// Dependencies of a federated service resolve within its site.
+	if !slices.Equal(api.BlockedBy, []string{"east/custom/db"}) {
+		t.Errorf("BlockedBy = %v, want [east/custom/db]", api.BlockedBy)
+	}
+	for _, ns := range []string{"default", "west/default", "eastern/default"} {
+		if _, ok := store.Get(ns, "api"); !ok {
+			t.Errorf("%s/api of another site should be untouched", ns)
+		}
+	}
+
+	var got []string
+	for len(events) > 0 {
+		evt := <-events
+		got = append(got, evt.Key())
+	}
+	// Removals come first, in map order.
+	slices.Sort(got[:2])
+	want := []string{"east/default/old", "east/lab/default/gone", "east/default/api", "east/custom/db"}
+	if !slices.Equal(got, want) {
+		t.Errorf("event keys = %v, want %v", got, want)
+	}
+}
+
+func TestStoreMarkSiteStale(t *testing.T) {
+	store := NewStore()
+	store.AddOrUpdate(Service{Name: "api", Namespace: "default", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "api", Namespace: "default", Site: "east", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "db", Namespace: "custom", Site: "east", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "db", Namespace: "custom", Site: "east/lab", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "db", Namespace: "custom", Site: "eastern", Status: StatusHealthy})
+
+	if n := store.MarkSiteStale("east"); n != 3 {
+		t.Errorf("MarkSiteStale() = %d, want 3", n)
+	}
+	if n := store.MarkSiteStale("east"); n != 0 {
+		t.Errorf("second MarkSiteStale() = %d, want 0", n)
+	}
+	for _, svc := range store.All() {
+		if svc.Stale != (svc.Site == "east" || svc.Site == "east/lab") {
+			t.Errorf("%s: stale = %v", svc.Key(), svc.Stale)
+		}
+		if svc.Status != StatusHealthy {
+			t.Errorf("%s: status = %q, want last reported status kept", svc.Key(), svc.Status)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package state
+
+// Restore adds services loaded from a snapshot, typically before discovery
+// starts, so their last known health is visible at once. Each is marked
+// Stale until its next health check and Restored until its source
+// rediscovers it via AddOrUpdate. Services already in the store are skipped.
+// It returns the number of services restored.
+func (s *Store) Restore(services []Service) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var added []string
+	for _, svc := range services {
+		key := svc.Key()
+		if _, exists := s.services[key]; exists {
+			continue
+		}
+		svc = svc.DeepCopy()
+		svc.Stale = true
+		svc.Restored = true
+		s.services[key] = svc
+		added = append(added, key)
+	}
+	// Dependencies may have been restored in any order, so compute BlockedBy
+	// once all services are present.
+	for _, key := range added {
+		svc := s.services[key]
+		svc.BlockedBy = s.blockedByLocked(svc)
+		s.services[key] = svc
+		s.publishLocked(Event{Type: EventDiscovered, Service: svc.DeepCopy()})
+	}
+	return len(added)
+}
+
+// PruneRestored removes the local services from source that were restored
+// from a snapshot but not rediscovered, i.e. that source no longer reports
+// them. Call it once the source has completed its initial discovery. It
+// returns the number of services removed. Federated services are replaced
+// by ReplaceSite instead.
+func (s *Store) PruneRestored(source string) int {
+	return s.pruneRestored(func(svc Service) bool { return svc.Source == source && svc.Site == "" })
+}
+
+// PruneRestoredCluster is PruneRestored for the Kubernetes services of one
+// cluster, for when clusters complete their initial discovery separately.
+func (s *Store) PruneRestoredCluster(cluster string) int {
+	return s.pruneRestored(func(svc Service) bool {
+		return svc.Source == SourceKubernetes && svc.Cluster == cluster && svc.Site == ""
+	})
+}
+
+func (s *Store) pruneRestored(match func(Service) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, svc := range s.services {
+		if !svc.Restored || !match(svc) {
+			continue
+		}
+		delete(s.services, key)
+		s.publishLocked(removedEvent(svc))
+		s.refreshBlockedLocked(key)
+		removed++
+	}
+	return removed
+}
+
+// carryObserved copies the health state observed for prev, a restored
+// service, onto svc, its rediscovered replacement. Configuration comes from
+// svc; the snapshot does not hold secrets or check settings.
+func carryObserved(svc *Service, prev Service) {
+	svc.Status = prev.Status
+	svc.CompositeStatus = prev.CompositeStatus
+	svc.HTTPCode = prev.HTTPCode
+	svc.ResponseTimeMs = prev.ResponseTimeMs
+	svc.LastChecked = prev.LastChecked
+	svc.LastStateChange = prev.LastStateChange
+	svc.ErrorSnippet = prev.ErrorSnippet
+	svc.AuthGuarded = prev.AuthGuarded
+	svc.PodDiagnostic = prev.PodDiagnostic
+	svc.PendingStatus = prev.PendingStatus
+	svc.PendingCount = prev.PendingCount
+	svc.PendingThreshold = prev.PendingThreshold
+	svc.Flapping = prev.Flapping
+	svc.TLSCert = prev.TLSCert
+	svc.LatencyBreach = prev.LatencyBreach
+	svc.Maintenance = prev.Maintenance
+	svc.MaintenanceWindow = prev.MaintenanceWindow
+	svc.ReadyEndpoints = prev.ReadyEndpoints
+	svc.TotalEndpoints = prev.TotalEndpoints
+	svc.GitOpsStatus = prev.GitOpsStatus
+	svc.Stale = prev.Stale
+	svc.Restored = false
+}
@@ -0,0 +1,138 @@
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func restoredServices() []Service {
+	checked := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	code := 503
+	ready, total := 0, 2
+	return []Service{
+		{
+			Name: "api", Namespace: "default", Source: SourceKubernetes,
+			URL: "https://api.example.com", Status: StatusUnhealthy, CompositeStatus: StatusUnhealthy,
+			HTTPCode: &code, LastChecked: &checked, LastStateChange: &checked,
+			ReadyEndpoints: &ready, TotalEndpoints: &total,
+			DependsOn: []string{"custom/db"},
+		},
+		{
+			Name: "db", Namespace: "custom", Source: SourceConfig,
+			Status: StatusUnhealthy, CompositeStatus: StatusUnhealthy, LastChecked: &checked,
+		},
+		{
+			Name: "old", Namespace: "default", Source: SourceKubernetes,
+			Status: StatusHealthy, CompositeStatus: StatusHealthy, LastChecked: &checked,
+		},
+	}
+}
+
+func TestStoreRestore(t *testing.T) {
+	store := NewStore()
+	store.AddOrUpdate(Service{Name: "db", Namespace: "custom", Source: SourceConfig, Status: StatusHealthy})
+	events := store.Subscribe()
+
+	if n := store.Restore(restoredServices()); n != 2 {
+		t.Fatalf("Restore() = %d, want 2 (existing service skipped)", n)
+	}
+
+	api, ok := store.Get("default", "api")
+	if !ok {
+		t.Fatal("expected api restored")
+	}
+	if !api.Stale || !api.Restored {
+		t.Errorf("restored service should be stale and restored, got stale=%v restored=%v", api.Stale, api.Restored)
+	}
+	if api.CompositeStatus != StatusUnhealthy || api.HTTPCode == nil || *api.HTTPCode != 503 {
+		t.Errorf("restored health state lost: %+v", api)
+	}
+	// db is healthy in the store, so api is not blocked.
+	if len(api.BlockedBy) != 0 {
+		t.Errorf("BlockedBy = %v, want none", api.BlockedBy)
+	}
+	if db, _ := store.Get("custom", "db"); db.Stale || db.Status != StatusHealthy {
+		t.Errorf("existing service should not be replaced: %+v", db)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case evt := <-events:
+			if evt.Type != EventDiscovered {
+				t.Errorf("expected EventDiscovered, got %v", evt.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for discovered event")
+		}
+	}
+}
+
+func TestStoreRestore_BlockedByAcrossRestoredServices(t *testing.T) {
+	store := NewStore()
+	store.Restore(restoredServices())
+
+	api, _ := store.Get("default", "api")
+	if len(api.BlockedBy) != 1 || api.BlockedBy[0] != "custom/db" {
+		t.Errorf("BlockedBy = %v, want [custom/db]", api.BlockedBy)
+	}
+}
+
+func TestStoreAddOrUpdate_RediscoveryKeepsObservedState(t *testing.T) {
+	store := NewStore()
+	store.Restore(restoredServices())
+
+	// The watcher rediscovers the service with fresh metadata and no health.
+	store.AddOrUpdate(Service{
+		Name: "api", Namespace: "default", Source: SourceKubernetes,
+		URL: "https://api.example.net", Status: StatusUnknown,
+	})
+
+	api, _ := store.Get("default", "api")
+	if api.Restored {
+		t.Error("rediscovered service should no longer be marked restored")
+	}
+	if !api.Stale {
+		t.Error("rediscovered service should stay stale until checked")
+	}
+	if api.URL != "https://api.example.net" {
+		t.Errorf("URL = %q, want the rediscovered URL", api.URL)
+	}
+	if api.Status != StatusUnhealthy || api.LastChecked == nil || api.ReadyEndpoints == nil || *api.ReadyEndpoints != 0 {
+		t.Errorf("observed state not carried over: %+v", api)
+	}
+
+	// Later updates replace the service as usual.
+	store.AddOrUpdate(Service{Name: "api", Namespace: "default", Source: SourceKubernetes, Status: StatusUnknown})
+	if api, _ := store.Get("default", "api"); api.Status != StatusUnknown || api.Stale {
+		t.Errorf("expected a plain replace after rediscovery, got %+v", api)
+	}
+}
+
+func TestStorePruneRestored(t *testing.T) {
+	store := NewStore()
+	store.Restore(restoredServices())
+	store.AddOrUpdate(Service{Name: "api", Namespace: "default", Source: SourceKubernetes})
+	events := store.Subscribe()
+
+	if n := store.PruneRestored(SourceKubernetes); n != 1 {
+		t.Fatalf("PruneRestored(kubernetes) = %d, want 1", n)
+	}
+	if _, ok := store.Get("default", "old"); ok {
+		t.Error("service not rediscovered should be pruned")
+	}
+	if _, ok := store.Get("default", "api"); !ok {
+		t.Error("rediscovered service should be kept")
+	}
+	if _, ok := store.Get("custom", "db"); !ok {
+		t.Error("services from other sources should be kept")
+	}
+
+	select {
+	case evt := <-events:
+		if evt.Type != EventRemoved || evt.Name != "old" {
+			t.Errorf("expected EventRemoved for old, got %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for removed event")
+	}
+}
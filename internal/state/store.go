@@ -1,6 +1,11 @@
 package state
 
 import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -42,8 +47,201 @@ type GitOpsStatus struct {
 // PodDiagnostic contains pod-level diagnostic information for K8s services.
 // Nil for non-K8s services or when pod status is unavailable.
 type PodDiagnostic struct {
-	Reason       *string `json:"reason"`
-	RestartCount int     `json:"restartCount"`
+	Reason       *string     `json:"reason"`
+	RestartCount int         `json:"restartCount"`
+	Pods         []PodDetail `json:"pods,omitempty"` // Not-ready pods, by name
+}
+
+// PodDetail is what `kubectl describe` would show of a not-ready pod: the
+// node it was scheduled on, its containers, and its recent Warning Events.
+type PodDetail struct {
+	Name       string            `json:"name"`
+	Node       string            `json:"node,omitempty"` // Empty while unscheduled
+	Containers []ContainerDetail `json:"containers,omitempty"`
+	Events     []PodEvent        `json:"events,omitempty"` // Newest first
+}
+
+// ContainerDetail is the state of one container, with its last termination:
+// the current one if it is terminated, else the previous one.
+type ContainerDetail struct {
+	Name               string `json:"name"`
+	Init               bool   `json:"init,omitempty"`
+	Ready              bool   `json:"ready"`
+	State              string `json:"state"` // "running", or the waiting or terminated reason
+	RestartCount       int    `json:"restartCount"`
+	ExitCode           *int   `json:"exitCode,omitempty"`
+	TerminationReason  string `json:"terminationReason,omitempty"`
+	TerminationMessage string `json:"terminationMessage,omitempty"`
+}
+
+// PodEvent is a Warning Event of a pod, e.g. FailedScheduling, FailedMount,
+// BackOff or Unhealthy.
+type PodEvent struct {
+	Reason   string     `json:"reason"`
+	Message  string     `json:"message"`
+	Count    int        `json:"count"`
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+}
+
+// Clone returns a deep copy of the diagnostic; nil for nil.
+func (d *PodDiagnostic) Clone() *PodDiagnostic {
+	if d == nil {
+		return nil
+	}
+	cp := *d
+	if d.Reason != nil {
+		val := *d.Reason
+		cp.Reason = &val
+	}
+	if d.Pods != nil {
+		cp.Pods = make([]PodDetail, len(d.Pods))
+		for i, pod := range d.Pods {
+			pod.Containers = slices.Clone(pod.Containers)
+			for j, c := range pod.Containers {
+				if c.ExitCode != nil {
+					code := *c.ExitCode
+					pod.Containers[j].ExitCode = &code
+				}
+			}
+			pod.Events = slices.Clone(pod.Events)
+			for j, e := range pod.Events {
+				if e.LastSeen != nil {
+					seen := *e.LastSeen
+					pod.Events[j].LastSeen = &seen
+				}
+			}
+			cp.Pods[i] = pod
+		}
+	}
+	return &cp
+}
+
+// Probe type constants. An empty probe type is treated as ProbeHTTP.
+const (
+	ProbeHTTP = "http"
+	ProbeTCP  = "tcp"
+	ProbeTLS  = "tls"
+	ProbeDNS  = "dns"
+	ProbeGRPC = "grpc"
+)
+
+// ProbeSpec selects the health probe used for a service and carries its
+// type-specific parameters. Nil means the default HTTP GET probe.
+// Request body, headers, and credentials are never serialized.
+type ProbeSpec struct {
+	Type             string               `json:"type"`
+	Address          string               `json:"address,omitempty"`
+	Query            string               `json:"query,omitempty"`
+	RecordType       string               `json:"recordType,omitempty"`
+	Expect           []string             `json:"expect,omitempty"`
+	GRPCService      string               `json:"grpcService,omitempty"`
+	Method           string               `json:"method,omitempty"`
+	Host             string               `json:"host,omitempty"`
+	Body             string               `json:"-"`
+	Headers          map[string]SecretRef `json:"-"`
+	BearerToken      *SecretRef           `json:"-"`
+	BasicAuth        *BasicAuth           `json:"-"`
+	CAFile           string               `json:"caFile,omitempty"`
+	CertFile         string               `json:"certFile,omitempty"`
+	KeyFile          string               `json:"keyFile,omitempty"`
+	ServerName       string               `json:"serverName,omitempty"`
+	DisableRedirects bool                 `json:"disableRedirects,omitempty"`
+	RedirectsHealthy bool                 `json:"redirectsHealthy,omitempty"`
+}
+
+// Clone returns a deep copy of the probe; nil for nil.
+func (p *ProbeSpec) Clone() *ProbeSpec {
+	if p == nil {
+		return nil
+	}
+	cp := *p
+	if p.Expect != nil {
+		cp.Expect = make([]string, len(p.Expect))
+		copy(cp.Expect, p.Expect)
+	}
+	if p.Headers != nil {
+		cp.Headers = make(map[string]SecretRef, len(p.Headers))
+		for k, v := range p.Headers {
+			cp.Headers[k] = v
+		}
+	}
+	if p.BearerToken != nil {
+		bt := *p.BearerToken
+		cp.BearerToken = &bt
+	}
+	if p.BasicAuth != nil {
+		ba := *p.BasicAuth
+		cp.BasicAuth = &ba
+	}
+	return &cp
+}
+
+// LatencySLO holds a service's response time thresholds in milliseconds;
+// zero disables a threshold. With P95 they apply to the 95th percentile of
+// the last Window response times rather than the latest one.
+type LatencySLO struct {
+	DegradedAboveMs  int64
+	UnhealthyAboveMs int64
+	P95              bool
+	Window           int
+}
+
+// SecretRef locates a secret: an inline Value, an environment variable, or a
+// file. It is resolved each time it is used.
+type SecretRef struct {
+	Value string
+	Env   string
+	File  string
+}
+
+// Resolve returns the secret's value, reading the environment or file each
+// time so rotated secrets take effect immediately.
+func (r SecretRef) Resolve() (string, error) {
+	switch {
+	case r.Env != "":
+		v, ok := os.LookupEnv(r.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", r.Env)
+		}
+		return v, nil
+	case r.File != "":
+		data, err := os.ReadFile(r.File)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return r.Value, nil
+	}
+}
+
+// BasicAuth holds HTTP basic auth credentials for a probe.
+type BasicAuth struct {
+	Username string
+	Password SecretRef
+}
+
+// BodyAssertion checks the body of a successful HTTP probe response. Exactly
+// one of Contains, Regex, JSONPath, or MaxBodyBytes is set. OnFailure is the
+// status applied when the assertion fails (degraded or unhealthy).
+type BodyAssertion struct {
+	Contains     string       `json:"contains,omitempty"`
+	Regex        string       `json:"regex,omitempty"`
+	JSONPath     string       `json:"jsonPath,omitempty"`
+	Equals       *string      `json:"equals,omitempty"` // nil when any value passes
+	MaxBodyBytes int64        `json:"maxBodyBytes,omitempty"`
+	OnFailure    HealthStatus `json:"onFailure"`
+}
+
+// TLSCertInfo describes the leaf certificate presented by a TLS probe target.
+// ChainValid reports whether the chain verifies against the trusted roots
+// for the probed host name; ChainError explains why it does not.
+type TLSCertInfo struct {
+	Subject    string    `json:"subject"`
+	Issuer     string    `json:"issuer"`
+	NotAfter   time.Time `json:"notAfter"`
+	ChainValid bool      `json:"chainValid"`
+	ChainError string    `json:"chainError,omitempty"`
 }
 
 // Service represents a discovered service with health information.
@@ -52,9 +250,15 @@ type Service struct {
         DisplayName         string       `json:"displayName"`
         OriginalDisplayName string       `json:"originalDisplayName,omitempty"`
         Namespace           string       `json:"namespace"`
+        Cluster             string       `json:"cluster,omitempty"` // Kubernetes cluster it was discovered in; empty for config services and a single unnamed cluster
+        Site                string       `json:"site,omitempty"`    // Remote instance it was federated from; empty for local services
         Group               string       `json:"group"`
+        Labels              map[string]string `json:"labels,omitempty"`
+        OriginalLabels      map[string]string `json:"-"` // Discovered labels, restored when an override is removed
         URL                 string       `json:"url"`
         Icon                string       `json:"icon,omitempty"`
+        OriginalIcon        string       `json:"-"` // Discovered icon, restored when an override is removed
+        Description         string       `json:"description,omitempty"`
         Source              string       `json:"source"`
         Status              HealthStatus    `json:"status"`
         CompositeStatus     HealthStatus    `json:"compositeStatus"`
@@ -66,7 +270,30 @@ type Service struct {
         AuthGuarded         bool            `json:"authGuarded"`
         PodDiagnostic       *PodDiagnostic  `json:"podDiagnostic"`
         HealthURL           string          `json:"healthUrl,omitempty"`
+        OriginalHealthURL   string          `json:"-"` // Discovered health URL, restored when an override is removed
         ExpectedStatusCodes []int        `json:"expectedStatusCodes,omitempty"`
+        OriginalExpectedStatusCodes []int `json:"-"` // Discovered expected status codes, restored when an override is removed
+        Probe               *ProbeSpec   `json:"probe,omitempty"`
+        OriginalProbe       *ProbeSpec   `json:"-"` // Discovered probe, restored when an override is removed
+        Assertions          []BodyAssertion `json:"assertions,omitempty"`
+        CheckInterval       time.Duration   `json:"-"` // Per-service override; zero inherits group/global
+        CheckTimeout        time.Duration   `json:"-"` // Per-service override; zero inherits group/global
+        FailureThreshold    int             `json:"-"` // Per-service override; zero inherits global
+        SuccessThreshold    int             `json:"-"` // Per-service override; zero inherits global
+        PendingStatus       HealthStatus    `json:"pendingStatus,omitempty"`    // Status waiting on a threshold
+        PendingCount        int             `json:"pendingCount,omitempty"`     // Consecutive results for PendingStatus
+        PendingThreshold    int             `json:"pendingThreshold,omitempty"` // Results needed to commit PendingStatus
+        Flapping            bool            `json:"flapping"`
+        TLSCert             *TLSCertInfo    `json:"tlsCert,omitempty"`
+        Latency             *LatencySLO     `json:"-"`                        // Response time thresholds
+        LatencyBreach       string          `json:"latencyBreach,omitempty"`  // e.g. "p95=9200ms" while over a threshold
+        DependsOn           []string        `json:"dependsOn,omitempty"`      // Service keys ("namespace/name", or a name in the same namespace)
+        OriginalDependsOn   []string        `json:"-"`                        // Discovered dependsOn, restored when an override is removed
+        BlockedBy           []string        `json:"blockedBy,omitempty"`      // Failing root dependencies while this service is failing
+        Maintenance         bool            `json:"maintenance"`                // Covered by an active maintenance window
+        MaintenanceWindow   string          `json:"maintenanceWindow,omitempty"` // ID of that window
+        Stale               bool            `json:"stale,omitempty"`             // Restored from a snapshot and not yet re-checked, or its site is unreachable
+        Restored            bool            `json:"-"`                           // Restored from a snapshot and not yet rediscovered by its source
         ReadyEndpoints      *int         `json:"readyEndpoints"`
         TotalEndpoints      *int         `json:"totalEndpoints"`
         GitOpsStatus        *GitOpsStatus `json:"gitopsStatus"`
@@ -80,43 +307,73 @@ const (
 	EventUpdated
 	EventK8sStatus
 	EventConfigErrors
+	// EventResync tells a subscriber it missed events because its buffer was
+	// full. It should reload everything it tracks (All, K8sConnected,
+	// ConfigErrors) as if it had just subscribed.
+	EventResync
 )
 
 // Event represents a state mutation notification.
 type Event struct {
 	Type      EventType
 	Service   Service // Populated for Discovered/Updated
+	Site      string  // Populated for Removed
+	Cluster   string  // Populated for Removed
 	Namespace string  // Populated for Removed
 	Name      string  // Populated for Removed
 }
 
+// ClusterStatus is the connectivity of one Kubernetes cluster. The cluster
+// of a single-cluster setup has an empty Name.
+type ClusterStatus struct {
+	Name      string
+	Connected bool
+	LastEvent time.Time // Last connectivity change
+}
+
+// subscriberBuffer is the number of events a subscriber may fall behind by
+// before it is sent an EventResync.
+const subscriberBuffer = 128
+
+// subscriber tracks delivery to one subscription channel.
+type subscriber struct {
+	queued   uint64 // events ever put on the channel, resyncs included
+	resyncAt uint64 // value of queued when the pending EventResync was sent; 0 if none
+	dropped  uint64 // events not delivered
+}
+
 // Store is a concurrency-safe in-memory store for discovered services.
-// Services are keyed by "namespace/name".
+// Services are keyed by "namespace/name", or "cluster/namespace/name" for
+// services discovered in a named cluster (see Service.Key).
 type Store struct {
 	mu           sync.RWMutex
 	services     map[string]Service
-	subs         map[chan Event]struct{}
-	k8sConnected bool
-	lastK8sEvent time.Time
+	subs         map[chan Event]*subscriber
+	clusters     map[string]ClusterStatus
 	configErrors []string
+	objectErrors map[string][]string // errors in the annotations of discovered objects, by object reference
+	dropped      uint64 // events not delivered to a full subscriber
 }
 
 // NewStore creates a new empty Store.
 func NewStore() *Store {
 	return &Store{
 		services: make(map[string]Service),
-		subs:     make(map[chan Event]struct{}),
+		subs:     make(map[chan Event]*subscriber),
+		clusters: make(map[string]ClusterStatus),
 	}
 }
 
 // Subscribe returns a read-only channel that receives events for every state mutation.
-// The caller is responsible for ensuring the channel is consumed to prevent blocking.
-// In a production environment, this should include a way to Unsubscribe.
+// Delivery never blocks the store. A subscriber either receives every event
+// in order or, once it falls subscriberBuffer events behind, receives an
+// EventResync in place of the events it missed.
 func (s *Store) Subscribe() <-chan Event {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	ch := make(chan Event, 128) // Increased buffer for better tolerance
-	s.subs[ch] = struct{}{}
+	// One slot beyond the buffer is kept free for EventResync.
+	ch := make(chan Event, subscriberBuffer+1)
+	s.subs[ch] = &subscriber{}
 	return ch
 }
 
@@ -134,10 +391,112 @@ func (s *Store) Unsubscribe(ch <-chan Event) {
 	}
 }
 
+// publishLocked fans event out to all subscribers without blocking. A
+// subscriber whose buffer is full misses the event and is sent an
+// EventResync instead, in the slot Subscribe reserves for it. Until the
+// subscriber has read that resync, further events are dropped too: the
+// reload it triggers runs after they were applied, since reading state
+// requires s.mu. Must be called with s.mu held.
+func (s *Store) publishLocked(event Event) {
+	for ch, sub := range s.subs {
+		if sub.resyncAt != 0 {
+			// Only the store sends, so everything queued minus what is still
+			// buffered has been read.
+			if sub.queued-uint64(len(ch)) < sub.resyncAt {
+				sub.dropped++
+				s.dropped++
+				continue
+			}
+			sub.resyncAt = 0
+		}
+		if len(ch) < subscriberBuffer {
+			ch <- event
+			sub.queued++
+			continue
+		}
+		sub.dropped++
+		s.dropped++
+		ch <- Event{Type: EventResync}
+		sub.queued++
+		sub.resyncAt = sub.queued
+	}
+}
+
+// DroppedEvents returns how many events were not delivered because a
+// subscriber's buffer was full, across all subscribers.
+func (s *Store) DroppedEvents() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dropped
+}
+
+// SubscriberDropped returns how many events the subscription ch has missed
+// (each gap is reported to it with an EventResync), or 0 if ch is not
+// subscribed.
+func (s *Store) SubscriberDropped(ch <-chan Event) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for existing, sub := range s.subs {
+		if (<-chan Event)(existing) == ch {
+			return sub.dropped
+		}
+	}
+	return 0
+}
+
 func serviceKey(namespace, name string) string {
 	return namespace + "/" + name
 }
 
+// ScopedNamespace qualifies namespace with cluster as "cluster/namespace".
+// For the unnamed cluster it is namespace alone. Get, Update, and Remove
+// take a scoped namespace.
+func ScopedNamespace(cluster, namespace string) string {
+	if cluster == "" {
+		return namespace
+	}
+	return cluster + "/" + namespace
+}
+
+// ScopedNamespace returns the service's namespace qualified by its cluster
+// and, for a federated service, its site.
+func (s Service) ScopedNamespace() string {
+	ns := ScopedNamespace(s.Cluster, s.Namespace)
+	if s.Site != "" {
+		ns = s.Site + "/" + ns
+	}
+	return ns
+}
+
+// Key returns the service's store key: "namespace/name", or
+// "cluster/namespace/name" for a service from a named cluster, prefixed
+// with "site/" for a service federated from a remote instance.
+func (s Service) Key() string {
+	return serviceKey(s.ScopedNamespace(), s.Name)
+}
+
+// Key returns the store key of the event's service.
+func (e Event) Key() string {
+	if e.Type == EventRemoved {
+		return Service{Site: e.Site, Cluster: e.Cluster, Namespace: e.Namespace, Name: e.Name}.Key()
+	}
+	return e.Service.Key()
+}
+
+// removedEvent returns the EventRemoved for svc.
+func removedEvent(svc Service) Event {
+	return Event{Type: EventRemoved, Site: svc.Site, Cluster: svc.Cluster, Namespace: svc.Namespace, Name: svc.Name}
+}
+
+func cloneStrings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	out := make([]string, len(in))
+	copy(out, in)
+	return out
+}
+
 func stringSlicesEqual(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -152,13 +511,20 @@ func stringSlicesEqual(a, b []string) bool {
 
 // AddOrUpdate inserts or replaces a service in the store.
 // It sends an EventDiscovered event for new services or an EventUpdated event for existing ones.
+// Replacing a service restored from a snapshot keeps its observed health
+// state, unless it is federated: its site reports that state itself.
 func (s *Store) AddOrUpdate(svc Service) {
 	s.mu.Lock()
-	key := serviceKey(svc.Namespace, svc.Name)
+	key := svc.Key()
 	_, exists := s.services[key]
 
 	// Store a deep copy to prevent external mutation of shared pointers
-	s.services[key] = svc.DeepCopy()
+	svc = svc.DeepCopy()
+	if prev, ok := s.services[key]; ok && prev.Restored && svc.Site == "" {
+		carryObserved(&svc, prev)
+	}
+	svc.BlockedBy = s.blockedByLocked(svc)
+	s.services[key] = svc
 
 	eventType := EventDiscovered
 	if exists {
@@ -167,39 +533,30 @@ func (s *Store) AddOrUpdate(svc Service) {
 
 	// Fan-out to all subscribers
 	event := Event{Type: eventType, Service: svc.DeepCopy()}
-	for ch := range s.subs {
-		select {
-		case ch <- event:
-		default:
-			// Buffer full, subscriber is too slow.
-			// In a more robust system, we might flag the client for reconnection.
-		}
-	}
+	s.publishLocked(event)
+	s.refreshBlockedLocked(key)
 	s.mu.Unlock()
 }
 
 // Remove deletes a service from the store and sends an EventRemoved notification.
+// namespace is the service's scoped namespace.
 func (s *Store) Remove(namespace, name string) {
 	s.mu.Lock()
 	key := serviceKey(namespace, name)
-	if _, exists := s.services[key]; !exists {
+	svc, exists := s.services[key]
+	if !exists {
 		s.mu.Unlock()
 		return
 	}
 	delete(s.services, key)
 
 	// Fan-out to all subscribers
-	event := Event{Type: EventRemoved, Namespace: namespace, Name: name}
-	for ch := range s.subs {
-		select {
-		case ch <- event:
-		default:
-		}
-	}
+	s.publishLocked(removedEvent(svc))
+	s.refreshBlockedLocked(key)
 	s.mu.Unlock()
 }
 
-// Get retrieves a single service by namespace and name.
+// Get retrieves a single service by scoped namespace and name.
 func (s *Store) Get(namespace, name string) (Service, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -223,7 +580,8 @@ func (s *Store) All() []Service {
 
 // Update performs a thread-safe read-modify-write operation on a single service.
 // The provided function 'fn' is called with a pointer to the service while the store is locked.
-// If the service does not exist, 'fn' is not called.
+// If the service does not exist, 'fn' is not called. namespace is the
+// service's scoped namespace.
 func (s *Store) Update(namespace, name string, fn func(*Service)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -235,45 +593,72 @@ func (s *Store) Update(namespace, name string, fn func(*Service)) {
 	}
 
 	fn(&svc)
+	svc.BlockedBy = s.blockedByLocked(svc)
 	s.services[key] = svc
 
 	// Fan-out to all subscribers
 	event := Event{Type: EventUpdated, Service: svc.DeepCopy()}
-	for ch := range s.subs {
-		select {
-		case ch <- event:
-		default:
-		}
-	}
+	s.publishLocked(event)
+	s.refreshBlockedLocked(key)
 }
 
-// SetK8sConnected updates the K8s connectivity status and notifies subscribers.
+// SetK8sConnected updates the connectivity of the unnamed cluster and
+// notifies subscribers.
 func (s *Store) SetK8sConnected(connected bool) {
+	s.SetClusterConnected("", connected)
+}
+
+// SetClusterConnected updates the connectivity of a Kubernetes cluster and
+// notifies subscribers.
+func (s *Store) SetClusterConnected(cluster string, connected bool) {
 	s.mu.Lock()
-	s.k8sConnected = connected
-	s.lastK8sEvent = time.Now()
+	s.clusters[cluster] = ClusterStatus{Name: cluster, Connected: connected, LastEvent: time.Now()}
 	event := Event{Type: EventK8sStatus}
-	for ch := range s.subs {
-		select {
-		case ch <- event:
-		default:
-		}
-	}
+	s.publishLocked(event)
 	s.mu.Unlock()
 }
 
-// K8sConnected returns whether the K8s API is currently reachable.
+// K8sConnected returns whether the K8s API of every known cluster is
+// currently reachable. It is false until a cluster reports in.
 func (s *Store) K8sConnected() bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.k8sConnected
+	if len(s.clusters) == 0 {
+		return false
+	}
+	for _, c := range s.clusters {
+		if !c.Connected {
+			return false
+		}
+	}
+	return true
 }
 
-// LastK8sEvent returns the time of the last K8s connectivity status change.
+// LastK8sEvent returns the time of the last K8s connectivity status change
+// of any cluster.
 func (s *Store) LastK8sEvent() time.Time {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.lastK8sEvent
+	var last time.Time
+	for _, c := range s.clusters {
+		if c.LastEvent.After(last) {
+			last = c.LastEvent
+		}
+	}
+	return last
+}
+
+// Clusters returns the connectivity of every cluster that has reported in,
+// sorted by name.
+func (s *Store) Clusters() []ClusterStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]ClusterStatus, 0, len(s.clusters))
+	for _, c := range s.clusters {
+		out = append(out, c)
+	}
+	slices.SortFunc(out, func(a, b ClusterStatus) int { return strings.Compare(a.Name, b.Name) })
+	return out
 }
 
 // SetConfigErrors stores config validation errors for SSE broadcasting.
@@ -289,20 +674,48 @@ func (s *Store) SetConfigErrors(errs []string) {
 	s.configErrors = next
 
 	event := Event{Type: EventConfigErrors}
-	for ch := range s.subs {
-		select {
-		case ch <- event:
-		default:
+	s.publishLocked(event)
+}
+
+// SetObjectErrors stores the errors in the annotations of one discovered
+// object, e.g. kind "Ingress", replacing its previous ones; nil clears them.
+// namespace is the object's scoped namespace. They are reported after the
+// config file's errors, each prefixed with the object reference.
+func (s *Store) SetObjectErrors(kind, namespace, name string, errs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ref := kind + " " + serviceKey(namespace, name)
+	next := make([]string, len(errs))
+	for i, err := range errs {
+		next[i] = ref + ": " + err
+	}
+	if stringSlicesEqual(s.objectErrors[ref], next) {
+		return
+	}
+	if len(next) == 0 {
+		delete(s.objectErrors, ref)
+	} else {
+		if s.objectErrors == nil {
+			s.objectErrors = make(map[string][]string)
 		}
+		s.objectErrors[ref] = next
 	}
+
+	event := Event{Type: EventConfigErrors}
+	s.publishLocked(event)
 }
 
-// ConfigErrors returns the current config validation errors.
+// ConfigErrors returns the current config validation errors: those of the
+// config file, then those of discovered objects ordered by reference.
 func (s *Store) ConfigErrors() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	result := make([]string, len(s.configErrors))
 	copy(result, s.configErrors)
+	for _, ref := range slices.Sorted(maps.Keys(s.objectErrors)) {
+		result = append(result, s.objectErrors[ref]...)
+	}
 	return result
 }
 
@@ -329,18 +742,31 @@ func (s Service) DeepCopy() Service {
 		val := *s.ErrorSnippet
 		cp.ErrorSnippet = &val
 	}
-	if s.PodDiagnostic != nil {
-		pd := *s.PodDiagnostic
-		if pd.Reason != nil {
-			val := *pd.Reason
-			pd.Reason = &val
-		}
-		cp.PodDiagnostic = &pd
-	}
+	cp.PodDiagnostic = s.PodDiagnostic.Clone()
 	if s.ExpectedStatusCodes != nil {
 		cp.ExpectedStatusCodes = make([]int, len(s.ExpectedStatusCodes))
 		copy(cp.ExpectedStatusCodes, s.ExpectedStatusCodes)
 	}
+	cp.OriginalExpectedStatusCodes = slices.Clone(s.OriginalExpectedStatusCodes)
+	cp.Probe = s.Probe.Clone()
+	cp.OriginalProbe = s.OriginalProbe.Clone()
+	if s.Assertions != nil {
+		cp.Assertions = make([]BodyAssertion, len(s.Assertions))
+		copy(cp.Assertions, s.Assertions)
+	}
+	if s.TLSCert != nil {
+		tc := *s.TLSCert
+		cp.TLSCert = &tc
+	}
+	if s.Latency != nil {
+		l := *s.Latency
+		cp.Latency = &l
+	}
+	cp.Labels = maps.Clone(s.Labels)
+	cp.OriginalLabels = maps.Clone(s.OriginalLabels)
+	cp.DependsOn = cloneStrings(s.DependsOn)
+	cp.OriginalDependsOn = cloneStrings(s.OriginalDependsOn)
+	cp.BlockedBy = cloneStrings(s.BlockedBy)
 	if s.ReadyEndpoints != nil {
 		val := *s.ReadyEndpoints
 		cp.ReadyEndpoints = &val
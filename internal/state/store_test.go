@@ -3,6 +3,9 @@ package state
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -252,6 +255,34 @@ func TestStoreDeepCopy(t *testing.T) {
 	}
 }
 
+func TestPodDiagnosticClone(t *testing.T) {
+	exitCode := 137
+	seen := time.Now()
+	d := &PodDiagnostic{Pods: []PodDetail{{
+		Name:       "app-1",
+		Containers: []ContainerDetail{{Name: "app", ExitCode: &exitCode}},
+		Events:     []PodEvent{{Reason: "BackOff", LastSeen: &seen}},
+	}}}
+
+	cp := d.Clone()
+	d.Pods[0].Name = "changed"
+	d.Pods[0].Containers[0].Name = "changed"
+	d.Pods[0].Events[0].Reason = "changed"
+	exitCode = 0
+	seen = seen.Add(time.Hour)
+
+	pod := cp.Pods[0]
+	if pod.Name != "app-1" || pod.Containers[0].Name != "app" || pod.Events[0].Reason != "BackOff" {
+		t.Errorf("Clone() shares pod details: %+v", pod)
+	}
+	if *pod.Containers[0].ExitCode != 137 || !pod.Events[0].LastSeen.Before(seen) {
+		t.Error("Clone() shares exit code or event time")
+	}
+	if (*PodDiagnostic)(nil).Clone() != nil {
+		t.Error("Clone() of nil should be nil")
+	}
+}
+
 func TestStoreGetDeepCopy(t *testing.T) {
 	store := NewStore()
 	code := 200
@@ -460,6 +491,89 @@ func TestSubscribeNonBlocking(t *testing.T) {
 	}
 }
 
+func TestSubscribeOverflowSendsResync(t *testing.T) {
+	store := NewStore()
+	events := store.Subscribe()
+	other := store.Subscribe()
+	defer store.Unsubscribe(other)
+
+	add := func(i int) {
+		store.AddOrUpdate(Service{Name: fmt.Sprintf("svc-%d", i), Namespace: "default", Status: StatusUnknown})
+	}
+	total := subscriberBuffer + 10
+	for i := range total {
+		add(i)
+	}
+
+	for i := range subscriberBuffer {
+		evt := <-events
+		if evt.Type != EventDiscovered || evt.Service.Name != fmt.Sprintf("svc-%d", i) {
+			t.Fatalf("event %d = %v %q, want discovered svc-%d", i, evt.Type, evt.Service.Name, i)
+		}
+	}
+	if evt := <-events; evt.Type != EventResync {
+		t.Fatalf("expected EventResync after the buffer filled, got %v", evt.Type)
+	}
+	select {
+	case evt := <-events:
+		t.Fatalf("expected no events after the resync, got %v", evt.Type)
+	default:
+	}
+
+	if got := store.SubscriberDropped(events); got != 10 {
+		t.Errorf("SubscriberDropped = %d, want 10", got)
+	}
+	if got := store.SubscriberDropped(other); got != 10 {
+		t.Errorf("SubscriberDropped(other) = %d, want 10", got)
+	}
+	if got := store.DroppedEvents(); got != 20 {
+		t.Errorf("DroppedEvents = %d, want 20", got)
+	}
+	if got := len(store.All()); got != total {
+		t.Errorf("store has %d services, want %d", got, total)
+	}
+
+	// Once the resync is read, delivery resumes.
+	add(total)
+	select {
+	case evt := <-events:
+		if evt.Type != EventDiscovered || evt.Service.Name != fmt.Sprintf("svc-%d", total) {
+			t.Errorf("expected discovered svc-%d after resync, got %v %q", total, evt.Type, evt.Service.Name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event after resync")
+	}
+}
+
+func TestSubscribeUnreadResyncCoversLaterEvents(t *testing.T) {
+	store := NewStore()
+	events := store.Subscribe()
+	for i := range subscriberBuffer + 1 {
+		store.AddOrUpdate(Service{Name: fmt.Sprintf("svc-%d", i), Namespace: "default"})
+	}
+	// Drain part of the buffer; the resync is still queued behind the rest.
+	for range 10 {
+		<-events
+	}
+	store.AddOrUpdate(Service{Name: "late", Namespace: "default"})
+
+	var last Event
+	for range subscriberBuffer - 10 + 1 {
+		last = <-events
+	}
+	if last.Type != EventResync {
+		t.Fatalf("expected EventResync last, got %v", last.Type)
+	}
+	select {
+	case evt := <-events:
+		t.Fatalf("event queued behind an unread resync: %v", evt.Type)
+	default:
+	}
+	if _, ok := store.Get("default", "late"); !ok {
+		t.Error("state must include the event covered by the resync")
+	}
+}
+
 func TestSubscribeEventOrder(t *testing.T) {
 	store := NewStore()
 	events := store.Subscribe()
@@ -580,6 +694,57 @@ func TestK8sConnectedGettersConcurrent(t *testing.T) {
 	wg.Wait()
 }
 
+func TestSetClusterConnected(t *testing.T) {
+	store := NewStore()
+	store.SetClusterConnected("prod", true)
+	store.SetClusterConnected("lab", false)
+
+	if store.K8sConnected() {
+		t.Error("expected K8sConnected = false while a cluster is disconnected")
+	}
+	clusters := store.Clusters()
+	if len(clusters) != 2 || clusters[0].Name != "lab" || clusters[0].Connected || clusters[1].Name != "prod" || !clusters[1].Connected {
+		t.Errorf("Clusters() = %+v, want lab disconnected and prod connected", clusters)
+	}
+	if store.LastK8sEvent().Before(clusters[1].LastEvent) {
+		t.Error("LastK8sEvent should be the latest cluster change")
+	}
+
+	store.SetClusterConnected("lab", true)
+	if !store.K8sConnected() {
+		t.Error("expected K8sConnected = true once every cluster is connected")
+	}
+}
+
+func TestStoreClusterKeys(t *testing.T) {
+	store := NewStore()
+	events := store.Subscribe()
+	store.AddOrUpdate(Service{Name: "web", Namespace: "media", Status: StatusHealthy})
+	store.AddOrUpdate(Service{Name: "web", Namespace: "media", Cluster: "lab", Status: StatusUnhealthy})
+	for range 2 {
+		if evt := <-events; evt.Type != EventDiscovered {
+			t.Fatalf("expected EventDiscovered, got %v", evt.Type)
+		}
+	}
+
+	if svc, ok := store.Get("media", "web"); !ok || svc.Status != StatusHealthy {
+		t.Errorf("unclustered service = %+v, %v", svc, ok)
+	}
+	lab, ok := store.Get("lab/media", "web")
+	if !ok || lab.Status != StatusUnhealthy || lab.Key() != "lab/media/web" {
+		t.Errorf("lab service = %+v, %v", lab, ok)
+	}
+
+	store.Remove(lab.ScopedNamespace(), "web")
+	evt := <-events
+	if evt.Type != EventRemoved || evt.Cluster != "lab" || evt.Namespace != "media" || evt.Name != "web" {
+		t.Errorf("removed event = %+v, want cluster lab, namespace media", evt)
+	}
+	if _, ok := store.Get("media", "web"); !ok {
+		t.Error("removing the lab service should keep the unclustered one")
+	}
+}
+
 func TestSetConfigErrorsFiresEventWhenChanged(t *testing.T) {
 	store := NewStore()
 	events := store.Subscribe()
@@ -635,6 +800,37 @@ func TestSetConfigErrorsStoresAndReturnsCopies(t *testing.T) {
 	}
 }
 
+func TestSetObjectErrors(t *testing.T) {
+	store := NewStore()
+	store.SetConfigErrors([]string{"services[0].url: required field missing"})
+	events := store.Subscribe()
+
+	store.SetObjectErrors("Ingress", "media", "jellyfin", []string{"command-center.io/icon: bad"})
+	store.SetObjectErrors("Ingress", "lab/default", "app", []string{"a", "b"})
+	store.SetObjectErrors("Ingress", "lab/default", "app", []string{"a", "b"})
+
+	want := []string{
+		"services[0].url: required field missing",
+		"Ingress lab/default/app: a",
+		"Ingress lab/default/app: b",
+		"Ingress media/jellyfin: command-center.io/icon: bad",
+	}
+	if got := store.ConfigErrors(); !slices.Equal(got, want) {
+		t.Errorf("ConfigErrors() = %q, want %q", got, want)
+	}
+	if len(events) != 2 {
+		t.Errorf("got %d events, want one per change", len(events))
+	}
+
+	// Config file errors and other objects' errors are kept apart.
+	store.SetConfigErrors(nil)
+	store.SetObjectErrors("Ingress", "lab/default", "app", nil)
+	want = []string{"Ingress media/jellyfin: command-center.io/icon: bad"}
+	if got := store.ConfigErrors(); !slices.Equal(got, want) {
+		t.Errorf("ConfigErrors() = %q, want %q", got, want)
+	}
+}
+
 func TestStoreConcurrentAccess(t *testing.T) {
 	store := NewStore()
 	const goroutines = 100
@@ -861,3 +1057,69 @@ func TestDeepCopyNewPointerFields(t *testing.T) {
 		t.Errorf("DeepCopy TotalEndpoints not independent: got %d", *cp.TotalEndpoints)
 	}
 }
+
+func TestProbeSecretsNotSerializedAndDeepCopied(t *testing.T) {
+	svc := Service{
+		Name:      "api",
+		Namespace: "custom",
+		Probe: &ProbeSpec{
+			Type:        ProbeHTTP,
+			Body:        `{"token":"body-secret"}`,
+			Headers:     map[string]SecretRef{"X-Api-Key": {Value: "header-secret"}},
+			BearerToken: &SecretRef{Value: "bearer-secret"},
+			BasicAuth:   &BasicAuth{Username: "admin", Password: SecretRef{Value: "basic-secret"}},
+		},
+	}
+
+	data, err := json.Marshal(svc)
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	for _, secret := range []string{"body-secret", "header-secret", "bearer-secret", "basic-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("JSON leaks %q: %s", secret, data)
+		}
+	}
+
+	cp := svc.DeepCopy()
+	cp.Probe.Headers["X-Api-Key"] = SecretRef{Value: "changed"}
+	cp.Probe.BearerToken.Value = "changed"
+	cp.Probe.BasicAuth.Password.Value = "changed"
+	if svc.Probe.Headers["X-Api-Key"].Value != "header-secret" ||
+		svc.Probe.BearerToken.Value != "bearer-secret" ||
+		svc.Probe.BasicAuth.Password.Value != "basic-secret" {
+		t.Error("DeepCopy shares probe credentials with the original")
+	}
+}
+
+func TestSecretRef_Resolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PROBE_TEST_TOKEN", "from-env")
+
+	tests := []struct {
+		name    string
+		ref     SecretRef
+		want    string
+		wantErr bool
+	}{
+		{"value", SecretRef{Value: "inline"}, "inline", false},
+		{"env", SecretRef{Env: "PROBE_TEST_TOKEN"}, "from-env", false},
+		{"file trims newline", SecretRef{File: path}, "from-file", false},
+		{"missing env", SecretRef{Env: "PROBE_TEST_UNSET"}, "", true},
+		{"missing file", SecretRef{File: filepath.Join(t.TempDir(), "nope")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ref.Resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
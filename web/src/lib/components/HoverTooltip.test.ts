@@ -299,6 +299,19 @@ describe('HoverTooltip', () => {
 		expect(screen.queryByText(/Pods:/)).not.toBeInTheDocument();
 	});
 
+	it('shows the service description', () => {
+		render(HoverTooltip, {
+			props: {
+				service: makeService({ description: 'Streams the film collection' }),
+				visible: true,
+				position: 'below',
+				left: 0,
+				id: 'tooltip-test'
+			}
+		});
+		expect(screen.getByText('Streams the film collection')).toBeInTheDocument();
+	});
+
 	it('shows auth-guarded line when authGuarded is true', () => {
 		render(HoverTooltip, {
 			props: {
@@ -471,6 +484,51 @@ describe('HoverTooltip pod diagnostics', () => {
 		expect(screen.getByText('1 restart')).toBeInTheDocument();
 	});
 
+	it('shows the latest warning event of the pods', () => {
+		render(HoverTooltip, {
+			props: {
+				service: makeService({
+					status: 'unhealthy',
+					source: 'kubernetes',
+					podDiagnostic: {
+						reason: null,
+						restartCount: 0,
+						pods: [
+							{
+								name: 'app-1',
+								events: [
+									{
+										reason: 'FailedScheduling',
+										message: '0/3 nodes are available',
+										count: 2,
+										lastSeen: '2026-01-02T03:05:00Z'
+									}
+								]
+							},
+							{
+								name: 'app-2',
+								events: [
+									{
+										reason: 'FailedMount',
+										message: 'volume not found',
+										count: 1,
+										lastSeen: '2026-01-02T03:04:00Z'
+									}
+								]
+							}
+						]
+					}
+				}),
+				visible: true,
+				position: 'below',
+				left: 0,
+				id: 'tooltip-test'
+			}
+		});
+		expect(screen.getByText('FailedScheduling: 0/3 nodes are available')).toBeInTheDocument();
+		expect(screen.queryByText(/FailedMount/)).not.toBeInTheDocument();
+	});
+
 	it('shows no pod diag line when podDiagnostic is null', () => {
 		render(HoverTooltip, {
 			props: {
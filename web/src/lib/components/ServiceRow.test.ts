@@ -353,6 +353,50 @@ describe('ServiceRow', () => {
 			expect(screen.getByText('Connection refused')).toBeInTheDocument();
 		});
 
+		it('expanded section shows pod details', async () => {
+			render(ServiceRow, {
+				props: {
+					service: makeService({
+						podDiagnostic: {
+							reason: 'CrashLoopBackOff',
+							restartCount: 4,
+							pods: [
+								{
+									name: 'grafana-7d9f',
+									node: 'node-1',
+									containers: [
+										{
+											name: 'grafana',
+											ready: false,
+											state: 'CrashLoopBackOff',
+											restartCount: 4,
+											exitCode: 1,
+											terminationReason: 'Error',
+											terminationMessage: 'panic: missing GF_SECURITY_ADMIN_PASSWORD'
+										}
+									],
+									events: [
+										{
+											reason: 'BackOff',
+											message: 'Back-off restarting failed container',
+											count: 12
+										}
+									]
+								}
+							]
+						}
+					}),
+					odd: false
+				}
+			});
+			await fireEvent.click(screen.getByRole('button'));
+			await tick();
+			expect(screen.getByTestId('pod-detail')).toHaveTextContent('grafana-7d9f on node-1');
+			expect(screen.getByText(/CrashLoopBackOff · 4 restarts · exit 1 \(Error\)/)).toBeInTheDocument();
+			expect(screen.getByText('panic: missing GF_SECURITY_ADMIN_PASSWORD')).toBeInTheDocument();
+			expect(screen.getByText('BackOff ×12: Back-off restarting failed container')).toBeInTheDocument();
+		});
+
 		it('has aria-expanded attribute', () => {
 			render(ServiceRow, {
 				props: { service: makeService(), odd: false }
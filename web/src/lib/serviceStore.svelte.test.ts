@@ -118,6 +118,28 @@ describe('serviceStore', () => {
 			remove('default', 'non-existent');
 			expect(getSortedServices()).toHaveLength(1);
 		});
+
+		it('keeps same-named services of different clusters apart', () => {
+			replaceAll([
+				makeService({ name: 'svc', namespace: 'default' }),
+				makeService({ name: 'svc', namespace: 'default', cluster: 'lab' })
+			], 'v1');
+			expect(getSortedServices()).toHaveLength(2);
+			remove('default', 'svc', 'lab');
+			expect(getSortedServices()).toHaveLength(1);
+			expect(getSortedServices()[0].cluster).toBeUndefined();
+		});
+
+		it('keeps federated services apart from local ones', () => {
+			replaceAll([
+				makeService({ name: 'svc', namespace: 'default', cluster: 'lab' }),
+				makeService({ name: 'svc', namespace: 'default', cluster: 'lab', site: 'east' })
+			], 'v1');
+			expect(getSortedServices()).toHaveLength(2);
+			remove('default', 'svc', 'lab', 'east');
+			expect(getSortedServices()).toHaveLength(1);
+			expect(getSortedServices()[0].site).toBeUndefined();
+		});
 	});
 
 	describe('sortedServices', () => {
@@ -184,9 +184,17 @@ export function getConfigErrors(): string[] {
 export function getHasConfigErrors(): boolean {
 	return hasConfigErrors;
 }
+// serviceKey identifies a service: "namespace/name", prefixed with the
+// cluster for services of a named cluster and with the site for services
+// federated from a remote instance.
+export function serviceKey(s: Pick<Service, 'namespace' | 'name' | 'cluster' | 'site'>): string {
+	const key = s.cluster ? `${s.cluster}/${s.namespace}/${s.name}` : `${s.namespace}/${s.name}`;
+	return s.site ? `${s.site}/${key}` : key;
+}
+
 // Mutation functions (called by sseClient only)
 export function replaceAll(newServices: Service[], newAppVersion: string, newHealthCheckIntervalMs?: number): void {
-	const nextServices = new Map(newServices.map((s) => [`${s.namespace}/${s.name}`, s]));
+	const nextServices = new Map(newServices.map((s) => [serviceKey(s), s]));
 	services = nextServices;
 	pruneGroupCollapseOverrides(nextServices);
 	if (newAppVersion) {
@@ -198,7 +206,7 @@ export function replaceAll(newServices: Service[], newAppVersion: string, newHea
 
 export function addOrUpdate(service: Service): void {
 	const updated = new Map(services);
-	updated.set(`${service.namespace}/${service.name}`, service);
+	updated.set(serviceKey(service), service);
 	services = updated;
 	const checkedAt = parseLastChecked(service.lastChecked);
 	if (checkedAt && (!lastUpdated || checkedAt.getTime() > lastUpdated.getTime())) {
@@ -206,9 +214,9 @@ export function addOrUpdate(service: Service): void {
 	}
 }
 
-export function remove(namespace: string, name: string): void {
+export function remove(namespace: string, name: string, cluster?: string, site?: string): void {
 	const updated = new Map(services);
-	updated.delete(`${namespace}/${name}`);
+	updated.delete(serviceKey({ namespace, name, cluster, site }));
 	services = updated;
 	pruneGroupCollapseOverrides(updated);
 }
@@ -370,6 +370,21 @@ describe('sseClient', () => {
 				);
 				expect(addOrUpdate).not.toHaveBeenCalled();
 
+				es.emit(
+					'discovered',
+					JSON.stringify(
+						makeService({
+							name: 'bad-poddiag-pods',
+							podDiagnostic: {
+								reason: null,
+								restartCount: 0,
+								pods: [{ name: 'app-1', events: [{ reason: 'BackOff' }] }]
+							} as unknown as Service['podDiagnostic']
+						})
+					)
+				);
+				expect(addOrUpdate).not.toHaveBeenCalled();
+
 				es.emit(
 					'discovered',
 					JSON.stringify(
@@ -11,7 +11,17 @@ import { loadCustomBindings } from './keyboardStore.svelte';
 import { saveLastKnownState } from './offlineCache';
 import { setLastSyncTime } from './connectivityStore.svelte';
 import { resolveApiUrl } from './basePath';
-import type { HealthStatus, Service, K8sStatusPayload, ServiceSource, KeyboardConfig } from './types';
+import type {
+	HealthStatus,
+	Service,
+	K8sStatusPayload,
+	ServiceSource,
+	KeyboardConfig,
+	PodDiagnostic,
+	PodDetail,
+	ContainerDetail,
+	PodEvent
+} from './types';
 
 let eventSource: EventSource | null = null;
 let onlineReconnectHandler: (() => void) | null = null;
@@ -69,13 +79,49 @@ function isNullableISODateString(value: unknown): value is string | null {
 	);
 }
 
-function isPodDiagnostic(value: unknown): value is { reason: string | null; restartCount: number } {
+function isOptionalArrayOf<T>(value: unknown, isItem: (item: unknown) => item is T): boolean {
+	return value === undefined || (Array.isArray(value) && value.every(isItem));
+}
+
+function isPodEvent(value: unknown): value is PodEvent {
+	if (!isRecord(value)) return false;
+	return (
+		typeof value.reason === 'string' &&
+		typeof value.message === 'string' &&
+		typeof value.count === 'number' &&
+		(value.lastSeen === undefined || isNullableISODateString(value.lastSeen))
+	);
+}
+
+function isContainerDetail(value: unknown): value is ContainerDetail {
+	if (!isRecord(value)) return false;
+	return (
+		typeof value.name === 'string' &&
+		typeof value.ready === 'boolean' &&
+		typeof value.state === 'string' &&
+		typeof value.restartCount === 'number' &&
+		(value.exitCode === undefined || typeof value.exitCode === 'number')
+	);
+}
+
+function isPodDetail(value: unknown): value is PodDetail {
+	if (!isRecord(value)) return false;
+	return (
+		typeof value.name === 'string' &&
+		(value.node === undefined || typeof value.node === 'string') &&
+		isOptionalArrayOf(value.containers, isContainerDetail) &&
+		isOptionalArrayOf(value.events, isPodEvent)
+	);
+}
+
+function isPodDiagnostic(value: unknown): value is PodDiagnostic {
 	if (!isRecord(value)) return false;
 	return (
 		isNullableString(value.reason) &&
 		typeof value.restartCount === 'number' &&
 		Number.isInteger(value.restartCount) &&
-		value.restartCount >= 0
+		value.restartCount >= 0 &&
+		isOptionalArrayOf(value.pods, isPodDetail)
 	);
 }
 
@@ -150,9 +196,16 @@ function isStatePayload(value: unknown): value is StatePayload {
 	return value.services.every((service) => isService(service));
 }
 
-function isRemovedPayload(value: unknown): value is { namespace: string; name: string } {
+function isRemovedPayload(
+	value: unknown
+): value is { namespace: string; name: string; cluster?: string; site?: string } {
 	if (!isRecord(value)) return false;
-	return typeof value.namespace === 'string' && typeof value.name === 'string';
+	return (
+		typeof value.namespace === 'string' &&
+		typeof value.name === 'string' &&
+		(value.cluster === undefined || typeof value.cluster === 'string') &&
+		(value.site === undefined || typeof value.site === 'string')
+	);
 }
 
 function isK8sStatusPayload(value: unknown): value is K8sStatusPayload {
@@ -164,7 +217,13 @@ export function connect(): void {
 	closeActiveConnection();
 
 	setConnectionStatus('connecting');
-	const sseUrl = resolveApiUrl('api/events');
+	let sseUrl = resolveApiUrl('api/events');
+	// A label selector in the page URL (?selector=tier%3Dcritical) limits the dashboard to matching services.
+	const selector =
+		typeof window !== 'undefined' ? new URLSearchParams(window.location.search).get('selector') : null;
+	if (selector) {
+		sseUrl += '?selector=' + encodeURIComponent(selector);
+	}
 	const source = new EventSource(sseUrl);
 	eventSource = source;
 
@@ -219,7 +278,7 @@ export function connect(): void {
 	source.addEventListener('removed', (e: MessageEvent) => {
 		const payload = parseJson(e.data);
 		if (!isRemovedPayload(payload)) return;
-		remove(payload.namespace, payload.name);
+		remove(payload.namespace, payload.name, payload.cluster, payload.site);
 	});
 
 	source.addEventListener('k8sStatus', (e: MessageEvent) => {
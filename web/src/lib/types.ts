@@ -19,9 +19,35 @@ export const HEALTH_STATUSES: HealthStatus[] = [
 
 export const DEFAULT_HEALTH_CHECK_INTERVAL_MS = 30_000;
 
+export interface PodEvent {
+	reason: string;
+	message: string;
+	count: number;
+	lastSeen?: string;
+}
+
+export interface ContainerDetail {
+	name: string;
+	init?: boolean;
+	ready: boolean;
+	state: string;
+	restartCount: number;
+	exitCode?: number;
+	terminationReason?: string;
+	terminationMessage?: string;
+}
+
+export interface PodDetail {
+	name: string;
+	node?: string;
+	containers?: ContainerDetail[];
+	events?: PodEvent[];
+}
+
 export interface PodDiagnostic {
 	reason: string | null;
 	restartCount: number;
+	pods?: PodDetail[];
 }
 
 export type ReconciliationState = 'synced' | 'progressing' | 'failed' | 'suspended';
@@ -33,13 +59,25 @@ export interface GitOpsStatus {
 	sourceType: string;
 }
 
+export interface TLSCertInfo {
+	subject: string;
+	issuer: string;
+	notAfter: string;
+	chainValid: boolean;
+	chainError?: string;
+}
+
 export interface Service {
 	name: string;
 	icon?: string | null;
 	displayName: string;
 	namespace: string;
+	cluster?: string;
+	site?: string;
 	group: string;
+	labels?: Record<string, string>;
 	url: string;
+	description?: string;
 	source?: ServiceSource;
 	status: HealthStatus;
 	compositeStatus: HealthStatus;
@@ -49,6 +87,17 @@ export interface Service {
 	lastChecked: string | null;
 	lastStateChange: string | null;
 	errorSnippet: string | null;
+	pendingStatus?: HealthStatus;
+	pendingCount?: number;
+	pendingThreshold?: number;
+	flapping?: boolean;
+	tlsCert?: TLSCertInfo;
+	latencyBreach?: string;
+	dependsOn?: string[];
+	blockedBy?: string[];
+	maintenance?: boolean;
+	maintenanceWindow?: string;
+	stale?: boolean;
 	podDiagnostic: PodDiagnostic | null;
 	healthUrl?: string | null;
 	readyEndpoints: number | null;
@@ -69,11 +118,18 @@ export interface ServiceGroup {
 	expanded: boolean;
 }
 
+export interface ClusterStatus {
+	name: string;
+	connected: boolean;
+	lastEvent?: string | null;
+}
+
 export interface StateEventPayload {
 	appVersion: string;
 	services: Service[];
 	k8sConnected?: boolean;
 	k8sLastEvent?: string | null;
+	clusters?: ClusterStatus[];
 	healthCheckIntervalMs?: number;
 	configErrors?: string[];
 	keyboard?: KeyboardConfig;
@@ -82,6 +138,7 @@ export interface StateEventPayload {
 export interface K8sStatusPayload {
 	k8sConnected: boolean;
 	k8sLastEvent: string | null;
+	clusters?: ClusterStatus[];
 }
 
 // Talos Node types (Epic 15)